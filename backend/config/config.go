@@ -15,6 +15,8 @@ type Config struct {
 		Address string `json:"address"`
 		Port    string `json:"port"`
 	} `json:"tcp_servers"`
+	// JobResumePolicy는 재시작 시 실행 중이던 반복 전송 작업의 처리 방식입니다 (resume | restart | none).
+	JobResumePolicy string `json:"job_resume_policy"`
 }
 
 var (
@@ -22,13 +24,17 @@ var (
 	configOnce sync.Once
 )
 
-// LoadConfig는 config.json 파일에서 설정을 로드합니다.
+// Path는 설정 파일 경로입니다.
+var Path = "config.json"
+
+// LoadConfig는 Path의 설정 파일에서 설정을 로드합니다. 파일이 없으면 기본 설정으로 만듭니다.
 func LoadConfig() {
 	configOnce.Do(func() {
 		// 기본 설정
 		config = &Config{
-			ServerPort: "8080",
-			DBPath:     "./data.db",
+			ServerPort:      "8080",
+			DBPath:          "./data.db",
+			JobResumePolicy: "resume",
 			TCPServers: []struct {
 				Name    string `json:"name"`
 				Address string `json:"address"`
@@ -43,21 +49,21 @@ func LoadConfig() {
 		}
 
 		// 설정 파일이 존재하는지 확인
-		if _, err := os.Stat("config.json"); os.IsNotExist(err) {
+		if _, err := os.Stat(Path); os.IsNotExist(err) {
 			// 설정 파일이 없으면 기본 설정으로 새 파일 생성
 			data, err := json.MarshalIndent(config, "", "  ")
 			if err != nil {
 				log.Fatalf("설정 마샬링 실패: %v", err)
 			}
 
-			err = os.WriteFile("config.json", data, 0644)
+			err = os.WriteFile(Path, data, 0644)
 			if err != nil {
 				log.Fatalf("설정 파일 생성 실패: %v", err)
 			}
-			log.Println("기본 설정으로 config.json 파일을 생성했습니다.")
+			log.Printf("기본 설정으로 %s 파일을 생성했습니다.", Path)
 		} else {
 			// 설정 파일이 있으면 로드
-			data, err := os.ReadFile("config.json")
+			data, err := os.ReadFile(Path)
 			if err != nil {
 				log.Fatalf("설정 파일 읽기 실패: %v", err)
 			}
@@ -66,7 +72,7 @@ func LoadConfig() {
 			if err != nil {
 				log.Fatalf("설정 언마샬링 실패: %v", err)
 			}
-			log.Println("config.json에서 설정을 로드했습니다.")
+			log.Printf("%s에서 설정을 로드했습니다.", Path)
 		}
 	})
 }
@@ -0,0 +1,19 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestMain은 패키지 디렉터리에 config.json이 남지 않도록 임시 디렉터리의 설정 파일을 사용합니다.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "config")
+	if err != nil {
+		panic("임시 디렉터리 생성 실패: " + err.Error())
+	}
+	Path = filepath.Join(dir, "config.json")
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
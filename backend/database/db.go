@@ -22,6 +22,20 @@ func InitDB() (*gorm.DB, error) {
 		&models.TCPServer{},
 		&models.TCPPacket{},
 		&models.TCPPacketHistory{},
+		&models.MockEndpoint{},
+		&models.MockRule{},
+		&models.MockPush{},
+		&models.Recording{},
+		&models.RecordingEntry{},
+		&models.Relay{},
+		&models.LoadTest{},
+		&models.FuzzJob{},
+		&models.FuzzCase{},
+		&models.Scenario{},
+		&models.ScenarioRun{},
+		&models.Schedule{},
+		&models.ScheduleRun{},
+		&models.SendJob{},
 	)
 	if err != nil {
 		return nil, err
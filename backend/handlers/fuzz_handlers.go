@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/fake-edge-server/models"
+	"github.com/fake-edge-server/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// FuzzHandler는 퍼징 작업 실행과 크래시 입력 관리를 위한 핸들러 구조체입니다.
+type FuzzHandler struct {
+	DB     *gorm.DB
+	Runner *services.FuzzRunner
+}
+
+// NewFuzzHandler는 새로운 FuzzHandler 인스턴스를 생성합니다.
+func NewFuzzHandler(db *gorm.DB, runner *services.FuzzRunner) *FuzzHandler {
+	return &FuzzHandler{
+		DB:     db,
+		Runner: runner,
+	}
+}
+
+// getFuzzJobByID는 URL 파라미터로 퍼징 작업을 조회하고, 실행 중이면 현재 진행 수를 채웁니다.
+func (h *FuzzHandler) getFuzzJobByID(c *gin.Context) (*models.FuzzJob, bool) {
+	var job models.FuzzJob
+	if err := h.DB.First(&job, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "퍼징 작업을 찾을 수 없습니다"})
+		return nil, false
+	}
+	if executed, crashes, ok := h.Runner.Progress(job.ID); ok {
+		job.Executed, job.Crashes = executed, crashes
+	}
+	return &job, true
+}
+
+// StartFuzzJob은 TCP 서버의 패킷 하나를 변형해 보내는 퍼징 작업을 생성하고 바로 시작합니다.
+func (h *FuzzHandler) StartFuzzJob(c *gin.Context) {
+	var server models.TCPServer
+	if err := h.DB.First(&server, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "TCP 서버를 찾을 수 없습니다"})
+		return
+	}
+
+	var req models.FuzzJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "유효하지 않은 요청 형식: " + err.Error()})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var packet models.TCPPacket
+	if err := h.DB.Where("tcp_server_id = ?", server.ID).First(&packet, req.PacketID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "서버에 해당 패킷이 없습니다"})
+		return
+	}
+	if err := packet.ValidateKind(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Name == "" {
+		req.Name = packet.Name
+	}
+	if req.Seed == 0 {
+		req.Seed = time.Now().UnixNano()
+	}
+	now := time.Now()
+	job := models.FuzzJob{
+		TCPServerID: server.ID,
+		TCPPacketID: packet.ID,
+		Name:        req.Name,
+		Iterations:  req.Iterations,
+		Seed:        req.Seed,
+		Mutations:   req.Mutations,
+		TimeoutMs:   req.TimeoutMs,
+		StopOnCrash: req.StopOnCrash,
+		Status:      models.FuzzRunning,
+		StartedAt:   &now,
+	}
+	if err := h.DB.Create(&job).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "퍼징 작업 생성 실패: " + err.Error()})
+		return
+	}
+	if err := h.Runner.Start(job, server, packet); err != nil {
+		h.DB.Unscoped().Delete(&job)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "퍼징 작업 시작 실패: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, job)
+}
+
+// GetFuzzJobs는 TCP 서버의 퍼징 작업 목록을 최신 순으로 반환합니다.
+func (h *FuzzHandler) GetFuzzJobs(c *gin.Context) {
+	var jobs []models.FuzzJob
+	if err := h.DB.Where("tcp_server_id = ?", c.Param("id")).Order("id DESC").Find(&jobs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for i := range jobs {
+		if executed, crashes, ok := h.Runner.Progress(jobs[i].ID); ok {
+			jobs[i].Executed, jobs[i].Crashes = executed, crashes
+		}
+	}
+
+	c.JSON(http.StatusOK, jobs)
+}
+
+// GetFuzzJobByID는 퍼징 작업 설정과 진행 상황을 반환합니다.
+func (h *FuzzHandler) GetFuzzJobByID(c *gin.Context) {
+	job, ok := h.getFuzzJobByID(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// StopFuzzJob은 실행 중인 퍼징 작업을 중지합니다.
+func (h *FuzzHandler) StopFuzzJob(c *gin.Context) {
+	job, ok := h.getFuzzJobByID(c)
+	if !ok {
+		return
+	}
+	if err := h.Runner.Stop(job.ID); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"id": job.ID, "message": "퍼징 작업 중지 요청됨"})
+}
+
+// DeleteFuzzJob은 끝난 퍼징 작업과 크래시 입력을 삭제합니다.
+func (h *FuzzHandler) DeleteFuzzJob(c *gin.Context) {
+	job, ok := h.getFuzzJobByID(c)
+	if !ok {
+		return
+	}
+	if h.Runner.IsRunning(job.ID) {
+		c.JSON(http.StatusConflict, gin.H{"error": "실행 중인 퍼징 작업은 삭제할 수 없습니다"})
+		return
+	}
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("fuzz_job_id = ?", job.ID).Delete(&models.FuzzCase{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(job).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "퍼징 작업 삭제 실패: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "퍼징 작업이 삭제되었습니다"})
+}
+
+// GetFuzzCases는 퍼징 작업에서 찾은 크래시 입력 목록을 발견 순으로 반환합니다.
+func (h *FuzzHandler) GetFuzzCases(c *gin.Context) {
+	job, ok := h.getFuzzJobByID(c)
+	if !ok {
+		return
+	}
+
+	var cases []models.FuzzCase
+	if err := h.DB.Where("fuzz_job_id = ?", job.ID).Order("id").Find(&cases).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, cases)
+}
+
+// ReplayFuzzCase는 저장된 크래시 입력을 새 연결로 다시 보내고 크래시 재현 여부를 반환합니다.
+func (h *FuzzHandler) ReplayFuzzCase(c *gin.Context) {
+	job, ok := h.getFuzzJobByID(c)
+	if !ok {
+		return
+	}
+	var fc models.FuzzCase
+	if err := h.DB.Where("fuzz_job_id = ?", job.ID).First(&fc, c.Param("case_id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "크래시 입력을 찾을 수 없습니다"})
+		return
+	}
+	var server models.TCPServer
+	if err := h.DB.First(&server, job.TCPServerID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "TCP 서버를 찾을 수 없습니다"})
+		return
+	}
+	var packet models.TCPPacket
+	if err := h.DB.First(&packet, job.TCPPacketID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "패킷을 찾을 수 없습니다"})
+		return
+	}
+
+	result, err := h.Runner.Replay(*job, fc, server, packet)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "크래시 입력 재생 실패: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/fake-edge-server/models"
+	"github.com/fake-edge-server/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupFuzzRouter(db *gorm.DB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	handler := NewFuzzHandler(db, services.NewFuzzRunner(db, services.NewWebSocketHub()))
+	r.GET("/api/tcp/:id/fuzz", handler.GetFuzzJobs)
+	r.POST("/api/tcp/:id/fuzz", handler.StartFuzzJob)
+	fz := r.Group("/api/fuzz")
+	{
+		fz.GET("/:id", handler.GetFuzzJobByID)
+		fz.DELETE("/:id", handler.DeleteFuzzJob)
+		fz.POST("/:id/stop", handler.StopFuzzJob)
+		fz.GET("/:id/cases", handler.GetFuzzCases)
+		fz.POST("/:id/cases/:case_id/replay", handler.ReplayFuzzCase)
+	}
+	return r
+}
+
+// startFragileDevice는 2바이트 요청을 그대로 돌려주고, 두 번째 바이트가 int8 최소값(0x80)이면
+// 연결을 끊는 장비를 띄웁니다. 길이가 다른 요청에는 응답하지 않습니다.
+func startFragileDevice(t *testing.T) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				buf := make([]byte, 64)
+				for {
+					n, err := conn.Read(buf)
+					if err != nil {
+						return
+					}
+					if n != 2 {
+						continue
+					}
+					if buf[1] == 0x80 {
+						return
+					}
+					conn.Write(buf[:n])
+				}
+			}()
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+func createFuzzTarget(t *testing.T, db *gorm.DB, port int) (models.TCPServer, models.TCPPacket) {
+	t.Helper()
+	server := models.TCPServer{Name: "device", Host: "127.0.0.1", Port: port}
+	require.NoError(t, db.Create(&server).Error)
+	packet := models.TCPPacket{TCPServerID: server.ID, Name: "status", Data: models.PacketData{
+		{Offset: 0, Value: 1, Type: models.TypeUint8},
+		{Offset: 1, Value: 5, Type: models.TypeInt8},
+	}}
+	require.NoError(t, db.Create(&packet).Error)
+	return server, packet
+}
+
+func startFuzzJob(t *testing.T, router *gin.Engine, serverID uint, body string) models.FuzzJob {
+	t.Helper()
+	resp := doJSON(router, "POST", fmt.Sprintf("/api/tcp/%d/fuzz", serverID), body)
+	require.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+	var job models.FuzzJob
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &job))
+	return job
+}
+
+func waitFuzzJob(t *testing.T, router *gin.Engine, id uint) models.FuzzJob {
+	t.Helper()
+	var job models.FuzzJob
+	require.Eventually(t, func() bool {
+		resp := doJSON(router, "GET", "/api/fuzz/"+itoa(id), "")
+		json.Unmarshal(resp.Body.Bytes(), &job)
+		return job.Status != models.FuzzRunning
+	}, 10*time.Second, 20*time.Millisecond)
+	return job
+}
+
+func TestFuzzJobFindsAndReplaysCrash(t *testing.T) {
+	db := setupTestDB()
+	router := setupFuzzRouter(db)
+	server, packet := createFuzzTarget(t, db, startFragileDevice(t))
+
+	// 길이 변형은 장비가 무시하므로(응답 없음) 시간 초과 후 정상 패킷 확인으로 크래시가 아님을 판별
+	body := fmt.Sprintf(`{"packet_id":%d,"iterations":20,"seed":11,"timeout_ms":100,"mutations":["boundary","length_mismatch"]}`, packet.ID)
+	job := startFuzzJob(t, router, server.ID, body)
+	assert.Equal(t, int64(11), job.Seed)
+	assert.Equal(t, "status", job.Name)
+
+	job = waitFuzzJob(t, router, job.ID)
+	assert.Equal(t, models.FuzzCompleted, job.Status, job.Error)
+	assert.Equal(t, 20, job.Executed)
+	require.Positive(t, job.Crashes)
+
+	resp := doJSON(router, "GET", "/api/fuzz/"+itoa(job.ID)+"/cases", "")
+	var cases []models.FuzzCase
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &cases))
+	require.Len(t, cases, job.Crashes)
+	for _, fc := range cases {
+		assert.Equal(t, models.MutationBoundary, fc.Mutation)
+		assert.Equal(t, 1, fc.Offset)
+		assert.Equal(t, "int8 min", fc.Detail)
+		assert.Equal(t, "0180", fc.Input)
+		assert.Equal(t, models.FuzzCrashDisconnect, fc.Crash)
+	}
+
+	resp = doJSON(router, "POST", fmt.Sprintf("/api/fuzz/%d/cases/%d/replay", job.ID, cases[0].ID), "")
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	var result models.FuzzReplayResult
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &result))
+	assert.Equal(t, models.FuzzCrashDisconnect, result.Crash)
+	assert.True(t, result.Alive)
+
+	resp = doJSON(router, "POST", "/api/fuzz/"+itoa(job.ID)+"/stop", "")
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	resp = doJSON(router, "DELETE", "/api/fuzz/"+itoa(job.ID), "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var count int64
+	db.Model(&models.FuzzCase{}).Count(&count)
+	assert.Zero(t, count)
+}
+
+func TestFuzzJobStopsOnCrash(t *testing.T) {
+	db := setupTestDB()
+	router := setupFuzzRouter(db)
+	server, packet := createFuzzTarget(t, db, startFragileDevice(t))
+
+	body := fmt.Sprintf(`{"packet_id":%d,"iterations":1000,"seed":3,"mutations":["boundary"],"stop_on_crash":true}`, packet.ID)
+	job := waitFuzzJob(t, router, startFuzzJob(t, router, server.ID, body).ID)
+	assert.Equal(t, models.FuzzStopped, job.Status)
+	assert.Equal(t, 1, job.Crashes)
+	assert.Less(t, job.Executed, 1000)
+}
+
+func TestFuzzJobFailsWithoutBaselineResponse(t *testing.T) {
+	db := setupTestDB()
+	router := setupFuzzRouter(db)
+
+	// 접속은 받지만 아무 응답도 하지 않는 장비
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				io.Copy(io.Discard, conn)
+			}()
+		}
+	}()
+	server, packet := createFuzzTarget(t, db, ln.Addr().(*net.TCPAddr).Port)
+
+	body := fmt.Sprintf(`{"packet_id":%d,"iterations":10,"timeout_ms":100}`, packet.ID)
+	job := waitFuzzJob(t, router, startFuzzJob(t, router, server.ID, body).ID)
+	assert.Equal(t, models.FuzzFailed, job.Status)
+	assert.Equal(t, "기준 패킷에 응답이 없습니다", job.Error)
+	assert.Zero(t, job.Executed)
+}
+
+func TestStartFuzzJobValidation(t *testing.T) {
+	db := setupTestDB()
+	router := setupFuzzRouter(db)
+	server, packet := createFuzzTarget(t, db, 1)
+
+	path := fmt.Sprintf("/api/tcp/%d/fuzz", server.ID)
+	cases := []string{
+		fmt.Sprintf(`{"packet_id":%d,"iterations":0}`, packet.ID),
+		fmt.Sprintf(`{"packet_id":%d,"iterations":10,"mutations":["explode"]}`, packet.ID),
+		fmt.Sprintf(`{"packet_id":%d,"iterations":10,"mutations":["crc_mismatch","invalid_json"]}`, packet.ID),
+		`{"packet_id":999,"iterations":10}`,
+	}
+	for _, body := range cases {
+		assert.Equal(t, http.StatusBadRequest, doJSON(router, "POST", path, body).Code, body)
+	}
+	var count int64
+	db.Unscoped().Model(&models.FuzzJob{}).Count(&count)
+	assert.Zero(t, count)
+	assert.Equal(t, http.StatusNotFound, doJSON(router, "POST", "/api/tcp/99/fuzz", `{}`).Code)
+}
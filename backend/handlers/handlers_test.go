@@ -5,8 +5,11 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/fake-edge-server/config"
 	"github.com/fake-edge-server/models"
 	"github.com/fake-edge-server/services"
 	"github.com/gin-gonic/gin"
@@ -15,11 +18,26 @@ import (
 	"gorm.io/gorm"
 )
 
+// TestMain은 패키지 디렉터리에 config.json이 남지 않도록 임시 디렉터리의 설정 파일을 사용합니다.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "config")
+	if err != nil {
+		panic("임시 디렉터리 생성 실패: " + err.Error())
+	}
+	config.Path = filepath.Join(dir, "config.json")
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
 func setupTestDB() *gorm.DB {
 	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
 	if err != nil {
 		panic("테스트 데이터베이스 연결 실패: " + err.Error())
 	}
+	// 메모리 DB는 연결마다 따로 생기므로 동시에 접근해도 같은 DB를 쓰도록 연결을 하나로 제한
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
 
 	// 실제 프로젝트의 마이그레이션과 동일하게 설정
 	err = db.AutoMigrate(
@@ -28,6 +46,20 @@ func setupTestDB() *gorm.DB {
 		&models.TCPServer{},
 		&models.TCPPacket{},
 		&models.TCPPacketHistory{},
+		&models.MockEndpoint{},
+		&models.MockRule{},
+		&models.MockPush{},
+		&models.Recording{},
+		&models.RecordingEntry{},
+		&models.Relay{},
+		&models.LoadTest{},
+		&models.FuzzJob{},
+		&models.FuzzCase{},
+		&models.Scenario{},
+		&models.ScenarioRun{},
+		&models.Schedule{},
+		&models.ScheduleRun{},
+		&models.SendJob{},
 	)
 	if err != nil {
 		panic("마이그레이션 실패: " + err.Error())
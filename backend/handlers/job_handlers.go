@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/fake-edge-server/models"
+	"github.com/fake-edge-server/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// JobHandler는 반복 전송 작업 조회와 중지를 위한 핸들러 구조체입니다.
+type JobHandler struct {
+	DB     *gorm.DB
+	Sender *services.PacketSender
+}
+
+// NewJobHandler는 새로운 JobHandler 인스턴스를 생성합니다.
+func NewJobHandler(db *gorm.DB, sender *services.PacketSender) *JobHandler {
+	return &JobHandler{
+		DB:     db,
+		Sender: sender,
+	}
+}
+
+// GetJobs는 반복 전송 작업 목록을 전송 통계와 함께 최신 순으로 반환합니다.
+// status, server_id 쿼리로 걸러낼 수 있으며, 재시작 전에 실행 중이던 작업은 resumes와 resumed_at 또는 interrupted 상태로 구분됩니다.
+func (h *JobHandler) GetJobs(c *gin.Context) {
+	query := h.DB.Order("id DESC")
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if serverID := c.Query("server_id"); serverID != "" {
+		query = query.Where("tcp_server_id = ?", serverID)
+	}
+
+	var jobs []models.SendJob
+	if err := query.Find(&jobs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, jobs)
+}
+
+// getJobByID는 URL 파라미터에서 ID를 추출하여 반복 전송 작업을 조회합니다.
+func (h *JobHandler) getJobByID(c *gin.Context) (*models.SendJob, bool) {
+	var job models.SendJob
+	if err := h.DB.First(&job, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "전송 작업을 찾을 수 없습니다"})
+		return nil, false
+	}
+	return &job, true
+}
+
+// GetJobByID는 특정 반복 전송 작업과 전송 통계를 반환합니다.
+func (h *JobHandler) GetJobByID(c *gin.Context) {
+	job, ok := h.getJobByID(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// StopJob은 실행 중인 반복 전송 작업을 중지합니다. 실행 중이 아니면 404를 반환합니다.
+func (h *JobHandler) StopJob(c *gin.Context) {
+	job, ok := h.getJobByID(c)
+	if !ok {
+		return
+	}
+
+	stopped, err := h.Sender.StopJob(job.ID)
+	if errors.Is(err, services.ErrJobNotRunning) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, stopped)
+}
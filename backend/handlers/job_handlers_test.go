@@ -0,0 +1,474 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fake-edge-server/models"
+	"github.com/fake-edge-server/services"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupJobRouter(db *gorm.DB, sender *services.PacketSender, hub *services.WebSocketHub) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	handler := NewTCPPacketHandler(db, services.NewTCPConnectionManager(), hub, sender)
+	r.POST("/api/tcp/:id/packets/:packet_id/send", handler.SendTCPPacket)
+	r.POST("/api/tcp/:id/packets/:packet_id/stop", handler.StopTCPPacketSend)
+	jobs := NewJobHandler(db, sender)
+	r.GET("/api/jobs", jobs.GetJobs)
+	r.GET("/api/jobs/:id", jobs.GetJobByID)
+	r.POST("/api/jobs/:id/stop", jobs.StopJob)
+	r.GET("/api/ws", NewWSHandler(hub).Handle)
+	return r
+}
+
+// newJobSender는 같은 WebSocket 허브를 쓰는 PacketSender와 라우터를 만듭니다.
+func newJobSender(db *gorm.DB) (*services.PacketSender, *gin.Engine) {
+	hub := services.NewWebSocketHub()
+	sender := services.NewPacketSender(db, services.NewTCPConnectionManager(), hub)
+	return sender, setupJobRouter(db, sender, hub)
+}
+
+func getJobs(t *testing.T, router *gin.Engine, query string) []models.SendJob {
+	t.Helper()
+	resp := doJSON(router, "GET", "/api/jobs"+query, "")
+	require.Equal(t, http.StatusOK, resp.Code)
+	var jobs []models.SendJob
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &jobs))
+	return jobs
+}
+
+func countHistory(db *gorm.DB, serverID uint) int64 {
+	var count int64
+	db.Model(&models.TCPPacketHistory{}).Where("tcp_server_id = ?", serverID).Count(&count)
+	return count
+}
+
+func TestSendJobIsStored(t *testing.T) {
+	db := setupTestDB()
+	_, router := newJobSender(db)
+	server, login, _ := createLoginPackets(t, db, startLoginDevice(t))
+	path := "/api/tcp/" + itoa(server.ID) + "/packets/" + itoa(login.ID)
+
+	resp := doJSON(router, "POST", path+"/send", `{"interval_ms":20}`)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	var started struct {
+		Message string         `json:"message"`
+		Job     models.SendJob `json:"job"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &started))
+	assert.Equal(t, "started", started.Message)
+	assert.Equal(t, models.SendJobRunning, started.Job.Status)
+	assert.Equal(t, 20, started.Job.IntervalMs)
+
+	// 이미 실행 중이면 같은 작업을 돌려줌
+	resp = doJSON(router, "POST", path+"/send", `{"interval_ms":50}`)
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &started))
+	assert.Equal(t, 20, started.Job.IntervalMs)
+	require.Len(t, getJobs(t, router, ""), 1)
+
+	require.Eventually(t, func() bool { return countHistory(db, server.ID) >= 2 }, 5*time.Second, 10*time.Millisecond)
+	doJSON(router, "POST", path+"/stop", "")
+
+	resp = doJSON(router, "GET", "/api/jobs/"+itoa(started.Job.ID), "")
+	require.Equal(t, http.StatusOK, resp.Code)
+	var job models.SendJob
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &job))
+	assert.Equal(t, models.SendJobStopped, job.Status)
+	assert.NotNil(t, job.StoppedAt)
+	assert.Empty(t, getJobs(t, router, "?status=running"))
+
+	resp = doJSON(router, "GET", "/api/jobs/999", "")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func TestSendJobResumesAfterRestart(t *testing.T) {
+	db := setupTestDB()
+	server, login, read := createLoginPackets(t, db, startLoginDevice(t))
+	startedAt := time.Now().Add(-time.Hour)
+	running := models.SendJob{TCPServerID: server.ID, TCPPacketID: login.ID, SendJobSpec: models.SendJobSpec{IntervalMs: 20}, Status: models.SendJobRunning, StartedAt: startedAt}
+	running.Sent, running.Succeeded = 10, 10
+	require.NoError(t, db.Create(&running).Error)
+	orphan := models.SendJob{TCPServerID: server.ID, TCPPacketID: 999, SendJobSpec: models.SendJobSpec{IntervalMs: 20}, Status: models.SendJobRunning, StartedAt: startedAt}
+	require.NoError(t, db.Create(&orphan).Error)
+	stopped := models.SendJob{TCPServerID: server.ID, TCPPacketID: read.ID, SendJobSpec: models.SendJobSpec{IntervalMs: 20}, Status: models.SendJobStopped, StartedAt: startedAt}
+	require.NoError(t, db.Create(&stopped).Error)
+
+	// 재시작한 것처럼 새 PacketSender로 작업을 재개
+	sender, router := newJobSender(db)
+	sender.ResumeJobs(models.JobResumeContinue)
+
+	require.Eventually(t, func() bool { return countHistory(db, server.ID) >= 2 }, 5*time.Second, 10*time.Millisecond)
+	jobs := getJobs(t, router, "")
+	require.Len(t, jobs, 3)
+	byID := map[uint]models.SendJob{}
+	for _, job := range jobs {
+		byID[job.ID] = job
+	}
+	assert.Equal(t, models.SendJobRunning, byID[running.ID].Status)
+	assert.Equal(t, 1, byID[running.ID].Resumes)
+	assert.NotNil(t, byID[running.ID].ResumedAt)
+	assert.WithinDuration(t, startedAt, byID[running.ID].StartedAt, time.Second)
+	assert.Greater(t, byID[running.ID].Sent, int64(10))
+	assert.Equal(t, models.SendJobFailed, byID[orphan.ID].Status)
+	assert.Contains(t, byID[orphan.ID].Error, "패킷[999]")
+	assert.Equal(t, models.SendJobStopped, byID[stopped.ID].Status)
+	assert.Zero(t, byID[stopped.ID].Resumes)
+
+	doJSON(router, "POST", "/api/tcp/"+itoa(server.ID)+"/packets/"+itoa(login.ID)+"/stop", "")
+	assert.Empty(t, getJobs(t, router, "?status=running"))
+}
+
+func TestSendJobResumePolicies(t *testing.T) {
+	for _, policy := range []string{models.JobResumeRestart, models.JobResumeNone} {
+		t.Run(policy, func(t *testing.T) {
+			db := setupTestDB()
+			server, login, _ := createLoginPackets(t, db, startLoginDevice(t))
+			startedAt := time.Now().Add(-time.Hour)
+			job := models.SendJob{TCPServerID: server.ID, TCPPacketID: login.ID, SendJobSpec: models.SendJobSpec{IntervalMs: 20}, Status: models.SendJobRunning, StartedAt: startedAt}
+			job.Sent, job.Succeeded = 10, 10
+			require.NoError(t, db.Create(&job).Error)
+
+			sender, _ := newJobSender(db)
+			sender.ResumeJobs(policy)
+			defer sender.Stop(server.ID, login.ID)
+			require.NoError(t, db.First(&job, job.ID).Error)
+
+			if policy == models.JobResumeNone {
+				assert.Equal(t, models.SendJobInterrupted, job.Status)
+				assert.Zero(t, job.Resumes)
+				time.Sleep(60 * time.Millisecond)
+				assert.Zero(t, countHistory(db, server.ID))
+				return
+			}
+			assert.Equal(t, models.SendJobRunning, job.Status)
+			assert.Equal(t, 1, job.Resumes)
+			assert.Zero(t, job.Sent)
+			assert.True(t, job.StartedAt.After(startedAt.Add(time.Minute)))
+			require.Eventually(t, func() bool { return countHistory(db, server.ID) >= 1 }, 5*time.Second, 10*time.Millisecond)
+		})
+	}
+}
+
+func TestSendJobMetrics(t *testing.T) {
+	db := setupTestDB()
+	_, router := newJobSender(db)
+	server, login, _ := createLoginPackets(t, db, startLoginDevice(t))
+	path := "/api/tcp/" + itoa(server.ID) + "/packets/" + itoa(login.ID)
+
+	// 실행 중인 작업이 없으면 404
+	resp := doJSON(router, "POST", path+"/stop", "")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/api/ws", nil)
+	require.NoError(t, err)
+	defer ws.Close()
+
+	resp = doJSON(router, "POST", path+"/send", `{"interval_ms":20}`)
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	// WebSocket으로 전송마다 작업 통계가 전달됨
+	var metrics models.SendJob
+	for metrics.Sent < 2 {
+		var msg struct {
+			Type string         `json:"type"`
+			Job  models.SendJob `json:"job"`
+		}
+		ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+		require.NoError(t, ws.ReadJSON(&msg))
+		if msg.Type == "job_metrics" {
+			metrics = msg.Job
+			assert.Equal(t, metrics.Sent, metrics.Succeeded)
+		}
+	}
+	assert.Equal(t, "login", metrics.PacketName)
+	assert.Equal(t, "device", metrics.ServerName)
+	assert.Equal(t, "81beef", metrics.LastResponse)
+	assert.Greater(t, metrics.LastRTTMs, 0.0)
+
+	jobs := getJobs(t, router, "?status=running")
+	require.Len(t, jobs, 1)
+	assert.GreaterOrEqual(t, jobs[0].Sent, int64(2))
+	assert.Zero(t, jobs[0].Failed)
+	assert.NotNil(t, jobs[0].LastSentAt)
+
+	resp = doJSON(router, "POST", "/api/jobs/"+itoa(jobs[0].ID)+"/stop", "")
+	require.Equal(t, http.StatusOK, resp.Code)
+	var job models.SendJob
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &job))
+	assert.Equal(t, models.SendJobStopped, job.Status)
+	resp = doJSON(router, "POST", "/api/jobs/"+itoa(job.ID)+"/stop", "")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	resp = doJSON(router, "POST", path+"/stop", "")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func TestSendJobCountsFailures(t *testing.T) {
+	db := setupTestDB()
+	_, router := newJobSender(db)
+	server := models.TCPServer{Name: "closed", Host: "127.0.0.1", Port: 1}
+	require.NoError(t, db.Create(&server).Error)
+	packet := models.TCPPacket{TCPServerID: server.ID, Name: "ping", Data: models.PacketData{{Offset: 0, Value: 1, Type: models.TypeUint8}}}
+	require.NoError(t, db.Create(&packet).Error)
+	path := "/api/tcp/" + itoa(server.ID) + "/packets/" + itoa(packet.ID)
+
+	resp := doJSON(router, "POST", path+"/send", `{"interval_ms":20}`)
+	require.Equal(t, http.StatusOK, resp.Code)
+	var job models.SendJob
+	require.Eventually(t, func() bool {
+		job = getJobs(t, router, "")[0]
+		return job.Failed >= 2
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Zero(t, job.Succeeded)
+	assert.Equal(t, job.Sent, job.Failed)
+	assert.NotEmpty(t, job.LastError)
+
+	resp = doJSON(router, "POST", path+"/stop", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+// readJobDone은 WebSocket에서 job_done 메시지를 기다려 작업과 요약을 반환합니다.
+func readJobDone(t *testing.T, ws *websocket.Conn) (models.SendJob, map[string]float64) {
+	t.Helper()
+	for {
+		var msg struct {
+			Type    string                 `json:"type"`
+			Job     models.SendJob         `json:"job"`
+			Summary map[string]interface{} `json:"summary"`
+		}
+		ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+		require.NoError(t, ws.ReadJSON(&msg))
+		if msg.Type != "job_done" {
+			continue
+		}
+		summary := map[string]float64{}
+		for k, v := range msg.Summary {
+			if f, ok := v.(float64); ok {
+				summary[k] = f
+			}
+		}
+		return msg.Job, summary
+	}
+}
+
+func TestSendJobLimits(t *testing.T) {
+	db := setupTestDB()
+	_, router := newJobSender(db)
+	server, login, read := createLoginPackets(t, db, startLoginDevice(t))
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/api/ws", nil)
+	require.NoError(t, err)
+	defer ws.Close()
+
+	// 5번 보내면 완료되고 요약이 방송됨
+	resp := doJSON(router, "POST", "/api/tcp/"+itoa(server.ID)+"/packets/"+itoa(login.ID)+"/send", `{"interval_ms":10,"count":5,"jitter_pct":20}`)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	job, summary := readJobDone(t, ws)
+	assert.Equal(t, models.SendJobCompleted, job.Status)
+	assert.NotNil(t, job.StoppedAt)
+	assert.Equal(t, 5.0, summary["sent"])
+	assert.Equal(t, 5.0, summary["succeeded"])
+	assert.Zero(t, summary["failed"])
+	assert.Greater(t, summary["avg_rtt_ms"], 0.0)
+	assert.Equal(t, int64(5), countHistory(db, server.ID))
+	assert.Equal(t, models.SendJobCompleted, getJobs(t, router, "")[0].Status)
+
+	// 완료된 작업은 실행 중이 아니므로 중지할 수 없음
+	resp = doJSON(router, "POST", "/api/jobs/"+itoa(job.ID)+"/stop", "")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	// 실행 시간이 지나면 완료됨
+	started := time.Now()
+	resp = doJSON(router, "POST", "/api/tcp/"+itoa(server.ID)+"/packets/"+itoa(read.ID)+"/send", `{"interval_ms":20,"duration_ms":150}`)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	job, summary = readJobDone(t, ws)
+	assert.GreaterOrEqual(t, time.Since(started), 150*time.Millisecond)
+	assert.Equal(t, read.ID, job.TCPPacketID)
+	assert.GreaterOrEqual(t, summary["elapsed_ms"], 150.0)
+	assert.GreaterOrEqual(t, summary["sent"], 3.0)
+	assert.LessOrEqual(t, summary["sent"], 8.0)
+}
+
+func TestSendJobBurst(t *testing.T) {
+	db := setupTestDB()
+	_, router := newJobSender(db)
+	server, login, _ := createLoginPackets(t, db, startLoginDevice(t))
+
+	// 300ms마다 3개씩 연달아 보내고 6개를 보내면 완료
+	resp := doJSON(router, "POST", "/api/tcp/"+itoa(server.ID)+"/packets/"+itoa(login.ID)+"/send", `{"interval_ms":300,"burst":3,"count":6}`)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	require.Eventually(t, func() bool { return countHistory(db, server.ID) >= 3 }, 5*time.Second, 5*time.Millisecond)
+	assert.Equal(t, int64(3), countHistory(db, server.ID))
+	require.Eventually(t, func() bool {
+		return getJobs(t, router, "")[0].Status == models.SendJobCompleted
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, int64(6), countHistory(db, server.ID))
+}
+
+func TestSendJobSpecValidation(t *testing.T) {
+	db := setupTestDB()
+	_, router := newJobSender(db)
+	server, login, _ := createLoginPackets(t, db, startLoginDevice(t))
+	path := "/api/tcp/" + itoa(server.ID) + "/packets/" + itoa(login.ID) + "/send"
+
+	for _, body := range []string{
+		`{"count":10}`,
+		`{"interval_ms":100,"jitter_pct":150}`,
+		`{"interval_ms":100,"burst":-1}`,
+		`{"interval_ms":"fast"}`,
+	} {
+		resp := doJSON(router, "POST", path, body)
+		assert.Equal(t, http.StatusBadRequest, resp.Code, body)
+	}
+	assert.Empty(t, getJobs(t, router, ""))
+}
+
+func TestSendJobPausesWhileReconnecting(t *testing.T) {
+	db := setupTestDB()
+	hub := services.NewWebSocketHub()
+	connManager := services.NewTCPConnectionManager()
+	connManager.SetHub(hub)
+	sender := services.NewPacketSender(db, connManager, hub)
+	router := setupJobRouter(db, sender, hub)
+	server, login, _ := createLoginPackets(t, db, startLoginDevice(t))
+	server.Reconnect = models.ReconnectPolicy{Mode: models.ReconnectBackoff, InitialMs: 200}
+	require.NoError(t, db.Save(&server).Error)
+
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/api/ws", nil)
+	require.NoError(t, err)
+	defer ws.Close()
+
+	type message struct {
+		Type    string         `json:"type"`
+		Status  string         `json:"status"`
+		Attempt int            `json:"attempt"`
+		Job     models.SendJob `json:"job"`
+	}
+	read := func() message {
+		var msg message
+		ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+		require.NoError(t, ws.ReadJSON(&msg))
+		return msg
+	}
+
+	resp := doJSON(router, "POST", "/api/tcp/"+itoa(server.ID)+"/packets/"+itoa(login.ID)+"/send", `{"interval_ms":20}`)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	for msg := read(); msg.Type != "job_metrics" || msg.Job.Sent < 2; msg = read() {
+	}
+
+	// 연결이 끊어지면 재연결하는 동안 작업이 멈췄다가 재연결 후 이어서 전송함
+	connManager.GetConn(server.ID).Close()
+	var paused, resumed *models.SendJob
+	var statuses []string
+	for {
+		msg := read()
+		switch msg.Type {
+		case "status":
+			statuses = append(statuses, msg.Status)
+			if msg.Status == "Reconnecting" {
+				assert.Equal(t, 1, msg.Attempt)
+			}
+		case "job_paused":
+			paused = &msg.Job
+		case "job_resumed":
+			resumed = &msg.Job
+		}
+		if resumed != nil && msg.Type == "job_metrics" {
+			assert.Greater(t, msg.Job.Sent, resumed.Sent)
+			break
+		}
+	}
+	assert.Equal(t, []string{"Reconnecting", "Alive"}, statuses)
+	require.NotNil(t, paused)
+	assert.Equal(t, models.SendJobPaused, paused.Status)
+	assert.Equal(t, models.SendJobRunning, resumed.Status)
+	assert.Equal(t, paused.Sent, resumed.Sent)
+	assert.Equal(t, "Alive", connManager.GetStatus(server.ID))
+
+	resp = doJSON(router, "POST", "/api/tcp/"+itoa(server.ID)+"/packets/"+itoa(login.ID)+"/stop", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestSendJobFailsWhenReconnectGivesUp(t *testing.T) {
+	db := setupTestDB()
+	hub := services.NewWebSocketHub()
+	connManager := services.NewTCPConnectionManager()
+	connManager.SetHub(hub)
+	connManager.SetDB(db)
+	sender := services.NewPacketSender(db, connManager, hub)
+	router := setupJobRouter(db, sender, hub)
+	server, login, _ := createLoginPackets(t, db, startLoginDevice(t))
+
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/api/ws", nil)
+	require.NoError(t, err)
+	defer ws.Close()
+
+	type message struct {
+		Type   string         `json:"type"`
+		Status string         `json:"status"`
+		Job    models.SendJob `json:"job"`
+	}
+	read := func() message {
+		var msg message
+		ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+		require.NoError(t, ws.ReadJSON(&msg))
+		return msg
+	}
+
+	resp := doJSON(router, "POST", "/api/tcp/"+itoa(server.ID)+"/packets/"+itoa(login.ID)+"/send", `{"interval_ms":20}`)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	for msg := read(); msg.Type != "job_metrics"; msg = read() {
+	}
+
+	// 연결 중에 바꾼 재연결 정책과 주소는 연결이 끊어질 때 다시 읽음
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server.Port = ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+	server.Reconnect = models.ReconnectPolicy{Mode: models.ReconnectBackoff, MaxAttempts: 1, InitialMs: 50}
+	require.NoError(t, db.Save(&server).Error)
+
+	// 재연결을 포기하면 일시 중지된 작업은 다시 전송하지 않고 실패로 끝남
+	connManager.GetConn(server.ID).Close()
+	var statuses []string
+	var done *models.SendJob
+	for done == nil {
+		msg := read()
+		require.NotEqual(t, "job_resumed", msg.Type)
+		switch msg.Type {
+		case "status":
+			statuses = append(statuses, msg.Status)
+		case "job_done":
+			done = &msg.Job
+		}
+	}
+	assert.Equal(t, []string{"Reconnecting", "Dead"}, statuses)
+	assert.Equal(t, models.SendJobFailed, done.Status)
+	assert.NotEmpty(t, done.Error)
+	assert.Equal(t, "Dead", connManager.GetStatus(server.ID))
+
+	sent := countHistory(db, server.ID)
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, sent, countHistory(db, server.ID))
+	jobs := getJobs(t, router, "")
+	require.Len(t, jobs, 1)
+	assert.Equal(t, models.SendJobFailed, jobs[0].Status)
+	assert.Equal(t, done.Error, jobs[0].Error)
+}
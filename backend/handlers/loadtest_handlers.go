@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/fake-edge-server/models"
+	"github.com/fake-edge-server/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// LoadTestHandler는 부하 시험 실행과 결과 조회를 위한 핸들러 구조체입니다.
+type LoadTestHandler struct {
+	DB     *gorm.DB
+	Runner *services.LoadTestRunner
+}
+
+// NewLoadTestHandler는 새로운 LoadTestHandler 인스턴스를 생성합니다.
+func NewLoadTestHandler(db *gorm.DB, runner *services.LoadTestRunner) *LoadTestHandler {
+	return &LoadTestHandler{
+		DB:     db,
+		Runner: runner,
+	}
+}
+
+// getLoadTestByID는 URL 파라미터로 부하 시험을 조회하고, 실행 중이면 현재 결과를 채웁니다.
+func (h *LoadTestHandler) getLoadTestByID(c *gin.Context) (*models.LoadTest, bool) {
+	var test models.LoadTest
+	if err := h.DB.First(&test, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "부하 시험을 찾을 수 없습니다"})
+		return nil, false
+	}
+	if report, ok := h.Runner.Report(test.ID); ok {
+		test.Report = report
+	}
+	return &test, true
+}
+
+// StartLoadTest는 TCP 서버에 대한 부하 시험을 생성하고 바로 시작합니다.
+func (h *LoadTestHandler) StartLoadTest(c *gin.Context) {
+	var server models.TCPServer
+	if err := h.DB.First(&server, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "TCP 서버를 찾을 수 없습니다"})
+		return
+	}
+
+	var req models.LoadTestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "유효하지 않은 요청 형식: " + err.Error()})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	packets := make([]models.TCPPacket, 0, len(req.PacketIDs))
+	for _, id := range req.PacketIDs {
+		var packet models.TCPPacket
+		if err := h.DB.Where("tcp_server_id = ?", server.ID).First(&packet, id).Error; err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("서버에 패킷[%d]이 없습니다", id)})
+			return
+		}
+		if err := packet.ValidateKind(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		packets = append(packets, packet)
+	}
+
+	if req.Name == "" {
+		req.Name = server.Name
+	}
+	now := time.Now()
+	test := models.LoadTest{
+		TCPServerID: server.ID,
+		Name:        req.Name,
+		Connections: req.Connections,
+		Rate:        req.Rate,
+		RampUpMs:    req.RampUpMs,
+		DurationMs:  req.DurationMs,
+		PacketIDs:   req.PacketIDs,
+		Status:      models.LoadTestRunning,
+		StartedAt:   &now,
+	}
+	if err := h.DB.Create(&test).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "부하 시험 생성 실패: " + err.Error()})
+		return
+	}
+	if err := h.Runner.Start(test, server, packets); err != nil {
+		h.DB.Delete(&test)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "부하 시험 시작 실패: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, test)
+}
+
+// GetLoadTests는 TCP 서버의 부하 시험 목록을 최신 순으로 반환합니다.
+func (h *LoadTestHandler) GetLoadTests(c *gin.Context) {
+	var tests []models.LoadTest
+	if err := h.DB.Where("tcp_server_id = ?", c.Param("id")).Order("id DESC").Find(&tests).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for i := range tests {
+		if report, ok := h.Runner.Report(tests[i].ID); ok {
+			tests[i].Report = report
+		}
+	}
+
+	c.JSON(http.StatusOK, tests)
+}
+
+// GetLoadTestByID는 부하 시험 설정과 결과를 반환합니다. 실행 중이면 현재까지의 결과입니다.
+func (h *LoadTestHandler) GetLoadTestByID(c *gin.Context) {
+	test, ok := h.getLoadTestByID(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, test)
+}
+
+// StopLoadTest는 실행 중인 부하 시험을 중지합니다. 결과는 종료 후 저장됩니다.
+func (h *LoadTestHandler) StopLoadTest(c *gin.Context) {
+	test, ok := h.getLoadTestByID(c)
+	if !ok {
+		return
+	}
+	if err := h.Runner.Stop(test.ID); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"id": test.ID, "message": "부하 시험 중지 요청됨"})
+}
+
+// DeleteLoadTest는 끝난 부하 시험을 삭제합니다.
+func (h *LoadTestHandler) DeleteLoadTest(c *gin.Context) {
+	test, ok := h.getLoadTestByID(c)
+	if !ok {
+		return
+	}
+	if h.Runner.IsRunning(test.ID) {
+		c.JSON(http.StatusConflict, gin.H{"error": "실행 중인 부하 시험은 삭제할 수 없습니다"})
+		return
+	}
+
+	if err := h.DB.Delete(test).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "부하 시험 삭제 실패: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "부하 시험이 삭제되었습니다"})
+}
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/fake-edge-server/models"
+	"github.com/fake-edge-server/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupLoadTestRouter(db *gorm.DB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	handler := NewLoadTestHandler(db, services.NewLoadTestRunner(db, services.NewWebSocketHub()))
+	r.GET("/api/tcp/:id/loadtests", handler.GetLoadTests)
+	r.POST("/api/tcp/:id/loadtests", handler.StartLoadTest)
+	lt := r.Group("/api/loadtests")
+	{
+		lt.GET("/:id", handler.GetLoadTestByID)
+		lt.DELETE("/:id", handler.DeleteLoadTest)
+		lt.POST("/:id/stop", handler.StopLoadTest)
+	}
+	return r
+}
+
+// startEchoDevice는 연결마다 받은 데이터를 그대로 돌려주는 장비를 띄우고 연결 수를 셉니다.
+func startEchoDevice(t *testing.T) (int, chan struct{}) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+	accepted := make(chan struct{}, 100)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- struct{}{}
+			go func() {
+				defer conn.Close()
+				buf := make([]byte, 64)
+				for {
+					n, err := conn.Read(buf)
+					if err != nil {
+						return
+					}
+					conn.Write(buf[:n])
+				}
+			}()
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr).Port, accepted
+}
+
+func waitLoadTest(t *testing.T, router *gin.Engine, id uint) models.LoadTest {
+	t.Helper()
+	var test models.LoadTest
+	require.Eventually(t, func() bool {
+		resp := doJSON(router, "GET", "/api/loadtests/"+itoa(id), "")
+		json.Unmarshal(resp.Body.Bytes(), &test)
+		return test.Status != models.LoadTestRunning
+	}, 3*time.Second, 20*time.Millisecond)
+	return test
+}
+
+func TestLoadTestClosedLoop(t *testing.T) {
+	db := setupTestDB()
+	router := setupLoadTestRouter(db)
+	port, accepted := startEchoDevice(t)
+
+	server := models.TCPServer{Name: "device", Host: "127.0.0.1", Port: port}
+	db.Create(&server)
+	first := models.TCPPacket{TCPServerID: server.ID, Name: "a", UseCRC: true, Data: models.PacketData{{Offset: 0, Value: 1}}}
+	second := models.TCPPacket{TCPServerID: server.ID, Name: "b", Data: models.PacketData{{Offset: 0, Value: 2}}}
+	db.Create(&first)
+	db.Create(&second)
+
+	body := fmt.Sprintf(`{"connections":5,"ramp_up_ms":100,"duration_ms":300,"packet_ids":[%d,%d]}`, first.ID, second.ID)
+	resp := doJSON(router, "POST", fmt.Sprintf("/api/tcp/%d/loadtests", server.ID), body)
+	require.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+	var test models.LoadTest
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &test))
+	assert.Equal(t, models.LoadTestRunning, test.Status)
+
+	resp = doJSON(router, "DELETE", "/api/loadtests/"+itoa(test.ID), "")
+	assert.Equal(t, http.StatusConflict, resp.Code)
+
+	test = waitLoadTest(t, router, test.ID)
+	assert.Equal(t, models.LoadTestCompleted, test.Status)
+	assert.Len(t, accepted, 5)
+	report := test.Report
+	assert.Positive(t, report.Received)
+	assert.GreaterOrEqual(t, report.Sent, report.Received)
+	assert.Empty(t, report.Errors)
+	assert.Zero(t, report.Active)
+	assert.Positive(t, report.Throughput)
+	assert.LessOrEqual(t, report.Latency.P50, report.Latency.P90)
+	assert.LessOrEqual(t, report.Latency.P99, report.Latency.Max)
+	assert.NotNil(t, test.FinishedAt)
+
+	resp = doJSON(router, "POST", "/api/loadtests/"+itoa(test.ID)+"/stop", "")
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	resp = doJSON(router, "DELETE", "/api/loadtests/"+itoa(test.ID), "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestLoadTestRateAndStop(t *testing.T) {
+	db := setupTestDB()
+	router := setupLoadTestRouter(db)
+	port, _ := startEchoDevice(t)
+
+	server := models.TCPServer{Name: "device", Host: "127.0.0.1", Port: port}
+	db.Create(&server)
+	packet := models.TCPPacket{TCPServerID: server.ID, Name: "a", Data: models.PacketData{{Offset: 0, Value: 1}}}
+	db.Create(&packet)
+
+	// 두 연결이 합쳐 초당 20개, 연결마다 100ms 간격으로 전송
+	body := fmt.Sprintf(`{"connections":2,"rate":20,"duration_ms":10000,"packet_ids":[%d]}`, packet.ID)
+	resp := doJSON(router, "POST", fmt.Sprintf("/api/tcp/%d/loadtests", server.ID), body)
+	require.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+	var test models.LoadTest
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &test))
+
+	time.Sleep(550 * time.Millisecond)
+	resp = doJSON(router, "GET", "/api/loadtests/"+itoa(test.ID), "")
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &test))
+	assert.Equal(t, 2, test.Report.Active)
+
+	resp = doJSON(router, "POST", "/api/loadtests/"+itoa(test.ID)+"/stop", "")
+	require.Equal(t, http.StatusOK, resp.Code)
+	test = waitLoadTest(t, router, test.ID)
+	assert.Equal(t, models.LoadTestStopped, test.Status)
+	assert.GreaterOrEqual(t, test.Report.Received, int64(8))
+	assert.LessOrEqual(t, test.Report.Sent, int64(14))
+}
+
+func TestLoadTestCountsConnectErrors(t *testing.T) {
+	db := setupTestDB()
+	router := setupLoadTestRouter(db)
+
+	ln, _ := net.Listen("tcp", "127.0.0.1:0")
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+	server := models.TCPServer{Name: "down", Host: "127.0.0.1", Port: port}
+	db.Create(&server)
+	packet := models.TCPPacket{TCPServerID: server.ID, Name: "a", Data: models.PacketData{{Offset: 0, Value: 1}}}
+	db.Create(&packet)
+
+	body := fmt.Sprintf(`{"connections":3,"duration_ms":200,"packet_ids":[%d]}`, packet.ID)
+	resp := doJSON(router, "POST", fmt.Sprintf("/api/tcp/%d/loadtests", server.ID), body)
+	require.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+	var test models.LoadTest
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &test))
+
+	test = waitLoadTest(t, router, test.ID)
+	assert.Equal(t, int64(3), test.Report.Errors[models.LoadErrorConnect])
+	assert.Zero(t, test.Report.Sent)
+
+	resp = doJSON(router, "GET", fmt.Sprintf("/api/tcp/%d/loadtests", server.ID), "")
+	var tests []models.LoadTest
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &tests))
+	assert.Len(t, tests, 1)
+}
+
+func TestStartLoadTestValidation(t *testing.T) {
+	db := setupTestDB()
+	router := setupLoadTestRouter(db)
+
+	server := models.TCPServer{Name: "device", Host: "127.0.0.1", Port: 1}
+	other := models.TCPServer{Name: "other", Host: "127.0.0.1", Port: 2}
+	db.Create(&server)
+	db.Create(&other)
+	packet := models.TCPPacket{TCPServerID: other.ID, Name: "a", Data: models.PacketData{{Offset: 0}}}
+	db.Create(&packet)
+
+	path := fmt.Sprintf("/api/tcp/%d/loadtests", server.ID)
+	cases := map[string]int{
+		`{"connections":0,"duration_ms":100,"packet_ids":[1]}`:                          http.StatusBadRequest,
+		`{"connections":1,"duration_ms":100,"ramp_up_ms":200,"packet_ids":[1]}`:         http.StatusBadRequest,
+		`{"connections":1,"duration_ms":100,"rate":-1,"packet_ids":[1]}`:                http.StatusBadRequest,
+		fmt.Sprintf(`{"connections":1,"duration_ms":100,"packet_ids":[%d]}`, packet.ID): http.StatusBadRequest,
+	}
+	for body, code := range cases {
+		assert.Equal(t, code, doJSON(router, "POST", path, body).Code, body)
+	}
+	assert.Equal(t, http.StatusNotFound, doJSON(router, "POST", "/api/tcp/99/loadtests", `{}`).Code)
+}
@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/fake-edge-server/models"
+	"github.com/fake-edge-server/services"
+	"github.com/fake-edge-server/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// MockHandler는 목(수신 대기) 엔드포인트 관리를 위한 핸들러 구조체입니다.
+type MockHandler struct {
+	DB    *gorm.DB
+	Mocks *services.MockServerManager
+	Hub   *services.WebSocketHub
+}
+
+// NewMockHandler는 새로운 MockHandler 인스턴스를 생성합니다.
+func NewMockHandler(db *gorm.DB, mocks *services.MockServerManager, hub *services.WebSocketHub) *MockHandler {
+	return &MockHandler{
+		DB:    db,
+		Mocks: mocks,
+		Hub:   hub,
+	}
+}
+
+// validateMockRequest는 목 엔드포인트 생성/수정 요청을 검증합니다.
+func validateMockRequest(req models.MockEndpointRequest) error {
+	if req.BindAddr != "" && !utils.IsValidHost(req.BindAddr) {
+		return errors.New("유효한 바인드 주소를 입력해주세요")
+	}
+
+	if req.Port < 0 || req.Port > 65535 {
+		return errors.New("유효한 포트 번호를 입력해주세요 (0-65535)")
+	}
+
+	if _, err := req.Framing.Format(); err != nil {
+		return errors.New("유효하지 않은 프레임 설정: " + err.Error())
+	}
+
+	if err := req.States.Validate(); err != nil {
+		return err
+	}
+	if err := req.States.CheckState(req.InitialState); err != nil {
+		return errors.New("시작 상태가 상태 목록에 없습니다: " + req.InitialState)
+	}
+
+	if err := req.Faults.Validate(); err != nil {
+		return errors.New("유효하지 않은 결함 설정: " + err.Error())
+	}
+	return nil
+}
+
+// applyMockRequest는 요청 값을 목 엔드포인트 모델에 반영합니다.
+func applyMockRequest(endpoint *models.MockEndpoint, req models.MockEndpointRequest) {
+	endpoint.Name = req.Name
+	endpoint.BindAddr = req.BindAddr
+	endpoint.Port = req.Port
+	endpoint.UseCRC = req.UseCRC
+	endpoint.Framing = req.Framing
+	endpoint.States = req.States
+	endpoint.InitialState = req.InitialState
+	endpoint.Faults = req.Faults
+}
+
+// getMockByID는 URL 파라미터에서 ID를 추출하여 목 엔드포인트를 조회합니다.
+func (h *MockHandler) getMockByID(c *gin.Context) (*models.MockEndpoint, bool) {
+	var endpoint models.MockEndpoint
+	if err := h.DB.First(&endpoint, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "목 엔드포인트를 찾을 수 없습니다"})
+		return nil, false
+	}
+	endpoint.Running = h.Mocks.IsRunning(endpoint.ID)
+	return &endpoint, true
+}
+
+// CreateMockEndpoint는 새로운 목 엔드포인트를 생성합니다.
+func (h *MockHandler) CreateMockEndpoint(c *gin.Context) {
+	var req models.MockEndpointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "유효하지 않은 요청 형식: " + err.Error()})
+		return
+	}
+
+	if err := validateMockRequest(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var existing models.MockEndpoint
+	if h.DB.Where("name = ?", req.Name).First(&existing).RowsAffected > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "같은 이름의 목 엔드포인트가 이미 존재합니다"})
+		return
+	}
+
+	var endpoint models.MockEndpoint
+	applyMockRequest(&endpoint, req)
+	if err := h.DB.Create(&endpoint).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "목 엔드포인트 생성 실패: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, endpoint)
+}
+
+// GetMockEndpoints는 모든 목 엔드포인트 목록을 실행 여부와 함께 반환합니다.
+func (h *MockHandler) GetMockEndpoints(c *gin.Context) {
+	var endpoints []models.MockEndpoint
+	if err := h.DB.Find(&endpoints).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for i := range endpoints {
+		endpoints[i].Running = h.Mocks.IsRunning(endpoints[i].ID)
+	}
+
+	c.JSON(http.StatusOK, endpoints)
+}
+
+// GetMockEndpointByID는 특정 목 엔드포인트 정보를 반환합니다.
+func (h *MockHandler) GetMockEndpointByID(c *gin.Context) {
+	endpoint, ok := h.getMockByID(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, endpoint)
+}
+
+// UpdateMockEndpoint는 목 엔드포인트 정보를 수정합니다. 실행 중에는 수정할 수 없습니다.
+func (h *MockHandler) UpdateMockEndpoint(c *gin.Context) {
+	endpoint, ok := h.getMockByID(c)
+	if !ok {
+		return
+	}
+	if endpoint.Running {
+		c.JSON(http.StatusConflict, gin.H{"error": "실행 중인 목 엔드포인트는 수정할 수 없습니다"})
+		return
+	}
+
+	var req models.MockEndpointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "유효하지 않은 요청 형식: " + err.Error()})
+		return
+	}
+
+	if err := validateMockRequest(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Name != endpoint.Name {
+		var existing models.MockEndpoint
+		if h.DB.Where("name = ? AND id != ?", req.Name, endpoint.ID).First(&existing).RowsAffected > 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "같은 이름의 목 엔드포인트가 이미 존재합니다"})
+			return
+		}
+	}
+
+	// 상태 목록이 바뀌어도 기존 규칙이 없는 상태를 가리키지 않아야 한다
+	var rules []models.MockRule
+	h.DB.Where("mock_endpoint_id = ?", endpoint.ID).Find(&rules)
+	for _, rule := range rules {
+		if err := checkRuleStates(req.States, rule); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("규칙 %q: %v", rule.Name, err)})
+			return
+		}
+	}
+
+	applyMockRequest(endpoint, req)
+	if err := h.DB.Save(endpoint).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "목 엔드포인트 업데이트 실패: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, endpoint)
+}
+
+// DeleteMockEndpoint는 목 엔드포인트를 중지하고 삭제합니다.
+func (h *MockHandler) DeleteMockEndpoint(c *gin.Context) {
+	endpoint, ok := h.getMockByID(c)
+	if !ok {
+		return
+	}
+	if endpoint.Running {
+		h.Mocks.Stop(endpoint.ID)
+	}
+
+	if err := h.DB.Delete(endpoint).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "목 엔드포인트 삭제 실패: " + err.Error()})
+		return
+	}
+	h.DB.Where("mock_endpoint_id = ?", endpoint.ID).Delete(&models.MockRule{})
+	h.DB.Where("mock_endpoint_id = ?", endpoint.ID).Delete(&models.MockPush{})
+
+	c.JSON(http.StatusOK, gin.H{"message": "목 엔드포인트가 성공적으로 삭제되었습니다"})
+}
+
+// StartMockEndpoint는 목 엔드포인트의 수신 대기를 시작합니다.
+func (h *MockHandler) StartMockEndpoint(c *gin.Context) {
+	endpoint, ok := h.getMockByID(c)
+	if !ok {
+		return
+	}
+	if err := h.Mocks.Start(*endpoint); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "목 엔드포인트 시작 실패: " + err.Error()})
+		return
+	}
+
+	addr, _ := h.Mocks.ListenAddr(endpoint.ID)
+	h.Hub.Broadcast(gin.H{"type": "mock_status", "mock_endpoint_id": endpoint.ID, "running": true, "listen_addr": addr})
+	c.JSON(http.StatusOK, gin.H{
+		"id":          endpoint.ID,
+		"name":        endpoint.Name,
+		"message":     "목 엔드포인트 시작됨",
+		"listen_addr": addr,
+	})
+}
+
+// StopMockEndpoint는 목 엔드포인트의 수신 대기와 모든 클라이언트 연결을 종료합니다.
+func (h *MockHandler) StopMockEndpoint(c *gin.Context) {
+	endpoint, ok := h.getMockByID(c)
+	if !ok {
+		return
+	}
+	if err := h.Mocks.Stop(endpoint.ID); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.Hub.Broadcast(gin.H{"type": "mock_status", "mock_endpoint_id": endpoint.ID, "running": false})
+	c.JSON(http.StatusOK, gin.H{
+		"id":      endpoint.ID,
+		"name":    endpoint.Name,
+		"message": "목 엔드포인트 중지됨",
+	})
+}
+
+// GetMockStatus는 목 엔드포인트의 실행 상태와 수신 주소를 반환합니다.
+func (h *MockHandler) GetMockStatus(c *gin.Context) {
+	endpoint, ok := h.getMockByID(c)
+	if !ok {
+		return
+	}
+	addr, _ := h.Mocks.ListenAddr(endpoint.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":          endpoint.ID,
+		"name":        endpoint.Name,
+		"running":     endpoint.Running,
+		"listen_addr": addr,
+	})
+}
+
+// GetMockConnections는 목 엔드포인트에 연결된 클라이언트 목록과 연결별 상태/변수를 반환합니다.
+func (h *MockHandler) GetMockConnections(c *gin.Context) {
+	endpoint, ok := h.getMockByID(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, h.Mocks.Connections(endpoint.ID))
+}
+
+// GetMockHistory는 목 엔드포인트가 주고받은 프레임 이력을 반환합니다.
+func (h *MockHandler) GetMockHistory(c *gin.Context) {
+	var history []models.TCPPacketHistory
+	result := h.DB.Where("mock_endpoint_id = ?", c.Param("id")).Order("id desc").Find(&history)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "이력 조회 실패: " + result.Error.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
@@ -0,0 +1,536 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/fake-edge-server/models"
+	"github.com/fake-edge-server/services"
+	"github.com/fake-edge-server/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupMockRouter(db *gorm.DB, mocks *services.MockServerManager) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	handler := NewMockHandler(db, mocks, services.NewWebSocketHub())
+	mk := r.Group("/api/mocks")
+	{
+		mk.POST("", handler.CreateMockEndpoint)
+		mk.GET("", handler.GetMockEndpoints)
+		mk.PUT("/:id", handler.UpdateMockEndpoint)
+		mk.DELETE("/:id", handler.DeleteMockEndpoint)
+		mk.GET("/:id/status", handler.GetMockStatus)
+		mk.POST("/:id/start", handler.StartMockEndpoint)
+		mk.POST("/:id/stop", handler.StopMockEndpoint)
+		mk.GET("/:id/connections", handler.GetMockConnections)
+		mk.GET("/:id/history", handler.GetMockHistory)
+	}
+	return r
+}
+
+func doJSON(router *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+// startMock creates a mock endpoint on a random loopback port and starts it.
+func startMock(t *testing.T, router *gin.Engine, body string) (uint, string) {
+	t.Helper()
+	resp := doJSON(router, "POST", "/api/mocks", body)
+	require.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+	var endpoint models.MockEndpoint
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &endpoint))
+
+	resp = doJSON(router, "POST", "/api/mocks/"+itoa(endpoint.ID)+"/start", "")
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	var started struct {
+		ListenAddr string `json:"listen_addr"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &started))
+	t.Cleanup(func() { doJSON(router, "POST", "/api/mocks/"+itoa(endpoint.ID)+"/stop", "") })
+	return endpoint.ID, started.ListenAddr
+}
+
+func itoa(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+func mockHistory(t *testing.T, router *gin.Engine, id uint) []models.TCPPacketHistory {
+	t.Helper()
+	resp := doJSON(router, "GET", "/api/mocks/"+itoa(id)+"/history", "")
+	var history []models.TCPPacketHistory
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &history))
+	return history
+}
+
+func TestMockEndpointLogsInboundAndOutboundFrames(t *testing.T) {
+	db := setupTestDB()
+	mocks := services.NewMockServerManager(db, services.NewWebSocketHub())
+	router := setupMockRouter(db, mocks)
+	id, addr := startMock(t, router, `{"name":"edge","bind_address":"127.0.0.1","port":0,"use_crc":true}`)
+
+	client, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer client.Close()
+
+	// 두 프레임을 한 번에 보내도 프레임 단위로 기록
+	client.Write(append(utils.BuildPacket([]byte{0x01, 0x02}), utils.BuildPacket([]byte{0x03})...))
+	assert.Eventually(t, func() bool { return len(mockHistory(t, router, id)) == 2 }, time.Second, 10*time.Millisecond)
+
+	resp := doJSON(router, "GET", "/api/mocks/"+itoa(id)+"/connections", "")
+	var sessions []services.MockConnection
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &sessions))
+	require.Len(t, sessions, 1)
+	assert.Equal(t, client.LocalAddr().String(), sessions[0].Peer)
+
+	// 모든 연결로 전송하면 프레임으로 감싸 보내고 outbound로 기록
+	sent, err := mocks.Send(id, "", []byte{0xAA})
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	frame := make([]byte, utils.HeaderSize+1)
+	client.SetReadDeadline(time.Now().Add(time.Second))
+	_, err = io.ReadFull(client, frame)
+	require.NoError(t, err)
+	payload, err := utils.UnpackPacket(frame)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0xAA}, payload)
+
+	history := mockHistory(t, router, id)
+	require.Len(t, history, 3)
+	assert.Equal(t, models.DirectionOutbound, history[0].Direction)
+	assert.Equal(t, "aa", history[0].Response)
+	assert.Equal(t, models.DirectionInbound, history[1].Direction)
+	assert.Equal(t, "03", history[1].Request)
+	assert.Equal(t, "0102", history[2].Request)
+	assert.Equal(t, client.LocalAddr().String(), history[2].Peer)
+}
+
+func TestMockEndpointKeepsSessionOnFramingError(t *testing.T) {
+	db := setupTestDB()
+	mocks := services.NewMockServerManager(db, services.NewWebSocketHub())
+	router := setupMockRouter(db, mocks)
+	id, addr := startMock(t, router, `{"name":"edge","bind_address":"127.0.0.1","use_crc":true}`)
+
+	client, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer client.Close()
+
+	// 매직이 맞지 않는 바이트는 연결을 끊지 않고 그대로 inbound로 기록
+	client.Write([]byte("bad"))
+	assert.Eventually(t, func() bool { return len(mockHistory(t, router, id)) == 1 }, time.Second, 10*time.Millisecond)
+	client.Write(utils.BuildPacket([]byte{0x01}))
+	assert.Eventually(t, func() bool { return len(mockHistory(t, router, id)) == 2 }, time.Second, 10*time.Millisecond)
+
+	history := mockHistory(t, router, id)
+	assert.Equal(t, "01", history[0].Request)
+	assert.Equal(t, "626164", history[1].Request)
+	assert.Len(t, mocks.Connections(id), 1)
+}
+
+func TestMockEndpointStartStop(t *testing.T) {
+	db := setupTestDB()
+	mocks := services.NewMockServerManager(db, services.NewWebSocketHub())
+	router := setupMockRouter(db, mocks)
+	id, addr := startMock(t, router, `{"name":"edge","bind_address":"127.0.0.1"}`)
+
+	client, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer client.Close()
+
+	// 실행 중에는 수정 불가, 중복 시작 불가
+	assert.Equal(t, http.StatusConflict, doJSON(router, "PUT", "/api/mocks/"+itoa(id), `{"name":"edge2"}`).Code)
+	assert.Equal(t, http.StatusInternalServerError, doJSON(router, "POST", "/api/mocks/"+itoa(id)+"/start", "").Code)
+
+	assert.Equal(t, http.StatusOK, doJSON(router, "POST", "/api/mocks/"+itoa(id)+"/stop", "").Code)
+	client.SetReadDeadline(time.Now().Add(time.Second))
+	_, err = client.Read(make([]byte, 1))
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusConflict, doJSON(router, "POST", "/api/mocks/"+itoa(id)+"/stop", "").Code)
+
+	resp := doJSON(router, "GET", "/api/mocks/"+itoa(id)+"/status", "")
+	assert.Contains(t, resp.Body.String(), `"running":false`)
+	assert.Equal(t, http.StatusOK, doJSON(router, "PUT", "/api/mocks/"+itoa(id), `{"name":"edge2"}`).Code)
+}
+
+func TestCreateMockEndpointValidation(t *testing.T) {
+	db := setupTestDB()
+	router := setupMockRouter(db, services.NewMockServerManager(db, services.NewWebSocketHub()))
+
+	assert.Equal(t, http.StatusBadRequest, doJSON(router, "POST", "/api/mocks", `{"port":5000}`).Code)
+	assert.Equal(t, http.StatusBadRequest, doJSON(router, "POST", "/api/mocks", `{"name":"a","port":70000}`).Code)
+	assert.Equal(t, http.StatusBadRequest, doJSON(router, "POST", "/api/mocks", `{"name":"a","bind_address":"bad host!"}`).Code)
+	assert.Equal(t, http.StatusCreated, doJSON(router, "POST", "/api/mocks", `{"name":"a","port":5000}`).Code)
+	assert.Equal(t, http.StatusConflict, doJSON(router, "POST", "/api/mocks", `{"name":"a","port":5001}`).Code)
+}
+
+func TestMockRulesRespondToMatchingFrames(t *testing.T) {
+	db := setupTestDB()
+	mocks := services.NewMockServerManager(db, services.NewWebSocketHub())
+	router := setupMockRouter(db, mocks)
+	router.POST("/api/mocks/:id/rules", NewMockHandler(db, mocks, services.NewWebSocketHub()).CreateMockRule)
+
+	ack := models.TCPPacket{Name: "ack", Data: models.PacketData{{Offset: 0, Value: 0x81}, {Offset: 1}, {Offset: 2}}}
+	status := models.TCPPacket{Name: "status", Data: models.PacketData{{Offset: 0, Value: 0x83}}}
+	nak := models.TCPPacket{Name: "nak", Data: models.PacketData{{Offset: 0, Value: 0xEE}}}
+	defs := models.TCPPacket{Name: "defs", Data: models.PacketData{
+		{Offset: 0, Type: models.TypeUint8},
+		{Offset: 1, Type: models.TypeUint16, IsChained: true},
+		{Offset: 2, Type: models.TypeUint16, IsChained: true},
+	}}
+	for _, p := range []*models.TCPPacket{&ack, &status, &nak, &defs} {
+		require.NoError(t, db.Create(p).Error)
+	}
+
+	id, addr := startMock(t, router, `{"name":"edge","bind_address":"127.0.0.1"}`)
+	rules := []string{
+		// 시퀀스 번호(1-2번 바이트)를 응답에 복사
+		`{"name":"login","match_type":"bytes","pattern":"01","response_packet_id":` + itoa(ack.ID) + `,"copy_fields":[{"from":1,"to":1,"length":2}]}`,
+		`{"name":"ignore","match_type":"mask","pattern":"20","mask":"f0","no_response":true}`,
+		`{"name":"status","match_type":"field","field_packet_id":` + itoa(defs.ID) + `,"field_offset":1,"field_value":"513","response_packet_id":` + itoa(status.ID) + `,"delay_ms":100}`,
+		`{"name":"fallback","is_default":true,"response_packet_id":` + itoa(nak.ID) + `}`,
+	}
+	for _, body := range rules {
+		resp := doJSON(router, "POST", "/api/mocks/"+itoa(id)+"/rules", body)
+		require.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+	}
+
+	client, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer client.Close()
+	exchange := func(req []byte) ([]byte, time.Duration) {
+		start := time.Now()
+		client.Write(req)
+		client.SetReadDeadline(time.Now().Add(300 * time.Millisecond))
+		buf := make([]byte, 64)
+		n, _ := client.Read(buf)
+		return buf[:n], time.Since(start)
+	}
+
+	got, _ := exchange([]byte{0x01, 0x34, 0x12})
+	assert.Equal(t, []byte{0x81, 0x34, 0x12}, got)
+
+	got, _ = exchange([]byte{0x2F, 0x00})
+	assert.Empty(t, got)
+
+	got, elapsed := exchange([]byte{0x03, 0x01, 0x02})
+	assert.Equal(t, []byte{0x83}, got)
+	assert.GreaterOrEqual(t, elapsed, 100*time.Millisecond)
+
+	got, _ = exchange([]byte{0x03, 0x02, 0x02})
+	assert.Equal(t, []byte{0xEE}, got)
+
+	history := mockHistory(t, router, id)
+	require.NotEmpty(t, history)
+	assert.Equal(t, "nak", history[0].PacketName)
+	assert.Equal(t, models.DirectionOutbound, history[0].Direction)
+}
+
+func TestCreateMockRuleValidation(t *testing.T) {
+	db := setupTestDB()
+	mocks := services.NewMockServerManager(db, services.NewWebSocketHub())
+	router := setupMockRouter(db, mocks)
+	router.POST("/api/mocks/:id/rules", NewMockHandler(db, mocks, services.NewWebSocketHub()).CreateMockRule)
+
+	packet := models.TCPPacket{Name: "p", Data: models.PacketData{{Offset: 0}}}
+	db.Create(&packet)
+	assert.Equal(t, http.StatusCreated, doJSON(router, "POST", "/api/mocks", `{"name":"edge"}`).Code)
+
+	cases := map[string]int{
+		`{"match_type":"bytes","pattern":"zz","response_packet_id":` + itoa(packet.ID) + `}`:                   http.StatusBadRequest,
+		`{"match_type":"mask","pattern":"0102","mask":"ff","response_packet_id":` + itoa(packet.ID) + `}`:      http.StatusBadRequest,
+		`{"match_type":"field","field_packet_id":` + itoa(packet.ID) + `,"field_offset":5,"no_response":true}`: http.StatusBadRequest,
+		`{"match_type":"regex","no_response":true}`:                                                            http.StatusBadRequest,
+		`{"match_type":"any"}`:                          http.StatusBadRequest,
+		`{"match_type":"any","response_packet_id":999}`: http.StatusBadRequest,
+		`{"match_type":"bytes","pattern":"01","response_packet_id":` + itoa(packet.ID) + `}`:                             http.StatusCreated,
+		`{"match_type":"any","response_packet_id":` + itoa(packet.ID) + `,"copy_fields":[{"from":0,"to":2,"length":1}]}`: http.StatusBadRequest,
+		`{"match_type":"any","response_hex":"0102","use_vars":[{"name":"v","offset":3}]}`:                                http.StatusBadRequest,
+		`{"match_type":"exact","pattern":"01","no_response":true,"set_vars":[{"name":"v","offset":1,"length":1}]}`:       http.StatusBadRequest,
+		`{"match_type":"any","response_hex":"0102","copy_fields":[{"from":0,"to":2,"length":1}]}`:                        http.StatusCreated,
+	}
+	for body, code := range cases {
+		resp := doJSON(router, "POST", "/api/mocks/1/rules", body)
+		assert.Equal(t, code, resp.Code, body)
+	}
+	assert.Equal(t, http.StatusNotFound, doJSON(router, "POST", "/api/mocks/9/rules", `{"no_response":true}`).Code)
+}
+
+func TestMockStateMachineWithConnectionVars(t *testing.T) {
+	db := setupTestDB()
+	mocks := services.NewMockServerManager(db, services.NewWebSocketHub())
+	router := setupMockRouter(db, mocks)
+	router.POST("/api/mocks/:id/rules", NewMockHandler(db, mocks, services.NewWebSocketHub()).CreateMockRule)
+
+	packets := map[string]*models.TCPPacket{}
+	for name, value := range map[string]int{"login_ok": 0x90, "nak": 0xEE, "cmd_ok": 0xA0, "reset_ok": 0x9F} {
+		p := &models.TCPPacket{Name: name, Data: models.PacketData{{Offset: 0, Value: value}}}
+		require.NoError(t, db.Create(p).Error)
+		packets[name] = p
+	}
+	pid := func(name string) string { return itoa(packets[name].ID) }
+
+	id, addr := startMock(t, router, `{"name":"device","bind_address":"127.0.0.1","states":["locked","ready"],"initial_state":"locked"}`)
+	rules := []string{
+		// 로그인 시 세션 ID(1-2번 바이트)를 저장하고 ready로 전환
+		`{"name":"login","state":"locked","match_type":"bytes","pattern":"10","next_state":"ready","set_vars":[{"name":"sid","offset":1,"length":2}],"response_packet_id":` + pid("login_ok") + `,"use_vars":[{"name":"sid","offset":1}]}`,
+		`{"name":"locked","state":"locked","is_default":true,"response_packet_id":` + pid("nak") + `}`,
+		`{"name":"command","state":"ready","match_type":"bytes","pattern":"20","response_packet_id":` + pid("cmd_ok") + `,"use_vars":[{"name":"sid","offset":1,"length":4}]}`,
+		`{"name":"reset","state":"ready","match_type":"bytes","pattern":"ff","next_state":"locked","response_packet_id":` + pid("reset_ok") + `}`,
+	}
+	for _, body := range rules {
+		resp := doJSON(router, "POST", "/api/mocks/"+itoa(id)+"/rules", body)
+		require.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+	}
+	resp := doJSON(router, "POST", "/api/mocks/"+itoa(id)+"/rules", `{"state":"busy","no_response":true}`)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	client, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer client.Close()
+	exchange := func(req ...byte) []byte {
+		client.Write(req)
+		client.SetReadDeadline(time.Now().Add(300 * time.Millisecond))
+		buf := make([]byte, 64)
+		n, _ := client.Read(buf)
+		return buf[:n]
+	}
+	connections := func() []services.MockConnection {
+		resp := doJSON(router, "GET", "/api/mocks/"+itoa(id)+"/connections", "")
+		var conns []services.MockConnection
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &conns))
+		return conns
+	}
+
+	require.Eventually(t, func() bool { return len(connections()) == 1 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, "locked", connections()[0].State)
+	assert.Equal(t, []byte{0xEE}, exchange(0x20))
+	assert.Equal(t, []byte{0x90, 0xCA, 0xFE}, exchange(0x10, 0xCA, 0xFE))
+
+	conns := connections()
+	assert.Equal(t, "ready", conns[0].State)
+	assert.Equal(t, "cafe", conns[0].Vars["sid"])
+
+	assert.Equal(t, []byte{0xA0, 0xCA, 0xFE, 0x00, 0x00}, exchange(0x20))
+	assert.Equal(t, []byte{0x9F}, exchange(0xFF))
+	assert.Equal(t, "locked", connections()[0].State)
+	assert.Equal(t, []byte{0xEE}, exchange(0x20))
+}
+
+func TestMockStateValidation(t *testing.T) {
+	db := setupTestDB()
+	mocks := services.NewMockServerManager(db, services.NewWebSocketHub())
+	router := setupMockRouter(db, mocks)
+	router.POST("/api/mocks/:id/rules", NewMockHandler(db, mocks, services.NewWebSocketHub()).CreateMockRule)
+
+	for _, body := range []string{
+		`{"name":"device","states":["a","b"],"initial_state":"c"}`,
+		`{"name":"device","states":["a",""]}`,
+		`{"name":"device","states":["a","a"]}`,
+	} {
+		resp := doJSON(router, "POST", "/api/mocks", body)
+		assert.Equal(t, http.StatusBadRequest, resp.Code, body)
+	}
+	resp := doJSON(router, "POST", "/api/mocks", `{"name":"device","states":["a","b"]}`)
+	require.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+	var endpoint models.MockEndpoint
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &endpoint))
+	resp = doJSON(router, "POST", "/api/mocks/"+itoa(endpoint.ID)+"/rules", `{"name":"to-b","state":"a","next_state":"b","no_response":true}`)
+	require.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+
+	// 규칙이 가리키는 상태를 목록에서 빼는 수정은 거부
+	resp = doJSON(router, "PUT", "/api/mocks/"+itoa(endpoint.ID), `{"name":"device","states":["a"]}`)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Contains(t, resp.Body.String(), "to-b")
+	resp = doJSON(router, "PUT", "/api/mocks/"+itoa(endpoint.ID), `{"name":"device","states":["a","b","c"],"initial_state":"c"}`)
+	assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+}
+
+func TestMockPushes(t *testing.T) {
+	db := setupTestDB()
+	mocks := services.NewMockServerManager(db, services.NewWebSocketHub())
+	router := setupMockRouter(db, mocks)
+	handler := NewMockHandler(db, mocks, services.NewWebSocketHub())
+	router.POST("/api/mocks/:id/push", handler.PushMockPacket)
+	router.POST("/api/mocks/:id/pushes", handler.CreateMockPush)
+	router.PUT("/api/mocks/:id/pushes/:push_id", handler.UpdateMockPush)
+
+	telemetry := models.TCPPacket{Name: "telemetry", Data: models.PacketData{{Offset: 0, Value: 0x70}}}
+	alarm := models.TCPPacket{Name: "alarm", Data: models.PacketData{{Offset: 0, Value: 0xA1}}}
+	db.Create(&telemetry)
+	db.Create(&alarm)
+
+	id, addr := startMock(t, router, `{"name":"edge","bind_address":"127.0.0.1"}`)
+	c1, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer c1.Close()
+	c2, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer c2.Close()
+	read := func(c net.Conn) []byte {
+		c.SetReadDeadline(time.Now().Add(500 * time.Millisecond))
+		buf := make([]byte, 1)
+		n, _ := c.Read(buf)
+		return buf[:n]
+	}
+	assert.Eventually(t, func() bool { return len(mocks.Connections(id)) == 2 }, time.Second, 10*time.Millisecond)
+
+	// 실행 중인 엔드포인트에 주기 전송을 추가하면 바로 시작
+	resp := doJSON(router, "POST", "/api/mocks/"+itoa(id)+"/pushes", `{"name":"tm","packet_id":`+itoa(telemetry.ID)+`,"interval_ms":100,"enabled":true}`)
+	require.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+	var push models.MockPush
+	json.Unmarshal(resp.Body.Bytes(), &push)
+	assert.Equal(t, []byte{0x70}, read(c1))
+	assert.Equal(t, []byte{0x70}, read(c2))
+
+	// 비활성화하면 중지
+	resp = doJSON(router, "PUT", "/api/mocks/"+itoa(id)+"/pushes/"+itoa(push.ID), `{"packet_id":`+itoa(telemetry.ID)+`,"interval_ms":100}`)
+	require.Equal(t, http.StatusOK, resp.Code)
+	time.Sleep(150 * time.Millisecond)
+	read(c1)
+	read(c2)
+
+	// 한 연결에만 즉시 전송
+	resp = doJSON(router, "POST", "/api/mocks/"+itoa(id)+"/push", `{"packet_id":`+itoa(alarm.ID)+`,"peer":"`+c2.LocalAddr().String()+`"}`)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	assert.Contains(t, resp.Body.String(), `"sent":1`)
+	assert.Equal(t, []byte{0xA1}, read(c2))
+	assert.Empty(t, read(c1))
+
+	resp = doJSON(router, "POST", "/api/mocks/"+itoa(id)+"/push", `{"packet_id":`+itoa(alarm.ID)+`,"peer":"127.0.0.1:1"}`)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	// Edge/Modbus 패킷은 메시지 ID나 MBAP 헤더 없이 보내지 않도록 푸시할 수 없음
+	edge := models.TCPPacket{Name: "edge", Kind: models.PacketKindEdge, Data: models.PacketData{{Offset: 0, Value: 0x01}}}
+	db.Create(&edge)
+	resp = doJSON(router, "POST", "/api/mocks/"+itoa(id)+"/push", `{"packet_id":`+itoa(edge.ID)+`}`)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Contains(t, resp.Body.String(), "raw 패킷만")
+	resp = doJSON(router, "POST", "/api/mocks/"+itoa(id)+"/pushes", `{"name":"e","packet_id":`+itoa(edge.ID)+`,"interval_ms":100}`)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	history := mockHistory(t, router, id)
+	require.NotEmpty(t, history)
+	assert.Equal(t, models.DirectionPush, history[0].Direction)
+	assert.Equal(t, "alarm", history[0].PacketName)
+	assert.Equal(t, "a1", history[0].Response)
+	assert.Equal(t, c2.LocalAddr().String(), history[0].Peer)
+}
+
+func TestMockPushReloadAfterLoadError(t *testing.T) {
+	db := setupTestDB()
+	mocks := services.NewMockServerManager(db, services.NewWebSocketHub())
+	router := setupMockRouter(db, mocks)
+
+	// 주기 전송을 불러오지 못해도 다시 불러올 때 패닉이 나지 않음
+	require.NoError(t, db.Migrator().DropTable(&models.MockPush{}))
+	id, _ := startMock(t, router, `{"name":"edge","bind_address":"127.0.0.1"}`)
+	assert.NotPanics(t, func() {
+		mocks.ReloadPushes(id)
+		mocks.ReloadPushes(id)
+	})
+}
+
+func TestCreateMockPushValidation(t *testing.T) {
+	db := setupTestDB()
+	mocks := services.NewMockServerManager(db, services.NewWebSocketHub())
+	router := setupMockRouter(db, mocks)
+	router.POST("/api/mocks/:id/pushes", NewMockHandler(db, mocks, services.NewWebSocketHub()).CreateMockPush)
+
+	packet := models.TCPPacket{Name: "p", Data: models.PacketData{{Offset: 0}}}
+	db.Create(&packet)
+	assert.Equal(t, http.StatusCreated, doJSON(router, "POST", "/api/mocks", `{"name":"edge"}`).Code)
+
+	pid := itoa(packet.ID)
+	cases := map[string]int{
+		`{"packet_id":` + pid + `}`:                                                    http.StatusBadRequest,
+		`{"packet_id":` + pid + `,"interval_ms":100,"cron":"* * * * *"}`:               http.StatusBadRequest,
+		`{"packet_id":` + pid + `,"cron":"61 * * * *"}`:                                http.StatusBadRequest,
+		`{"packet_id":` + pid + `,"cron":"0 9 * * mon-fri","timezone":"Mars/Olympus"}`: http.StatusBadRequest,
+		`{"packet_id":999,"interval_ms":100}`:                                          http.StatusBadRequest,
+		`{"packet_id":` + pid + `,"cron":"0 9 * * mon-fri","timezone":"Asia/Seoul"}`:   http.StatusCreated,
+		`{"packet_id":` + pid + `,"interval_ms":1000,"enabled":true}`:                  http.StatusCreated,
+	}
+	for body, code := range cases {
+		resp := doJSON(router, "POST", "/api/mocks/1/pushes", body)
+		assert.Equal(t, code, resp.Code, body)
+	}
+}
+
+func TestMockEndpointInjectsFaults(t *testing.T) {
+	db := setupTestDB()
+	mocks := services.NewMockServerManager(db, services.NewWebSocketHub())
+	router := setupMockRouter(db, mocks)
+	router.POST("/api/mocks/:id/rules", NewMockHandler(db, mocks, services.NewWebSocketHub()).CreateMockRule)
+
+	resp := doJSON(router, "POST", "/api/mocks", `{"name":"bad","faults":{"drop_rate":1.5}}`)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	// 모든 응답을 두 번 보내고 지연시킴
+	id, addr := startMock(t, router, `{"name":"flaky","bind_address":"127.0.0.1","faults":{"duplicate_rate":1,"latency_rate":1,"latency_ms":50}}`)
+	resp = doJSON(router, "POST", "/api/mocks/"+itoa(id)+"/rules", `{"name":"echo","response_hex":"aa"}`)
+	require.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+
+	client, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer client.Close()
+	start := time.Now()
+	client.Write([]byte{0x01})
+	reply := make([]byte, 2)
+	client.SetReadDeadline(time.Now().Add(time.Second))
+	_, err = io.ReadFull(client, reply)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0xAA, 0xAA}, reply)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	history := mockHistory(t, router, id)
+	require.Len(t, history, 2)
+	assert.Equal(t, models.DirectionOutbound, history[0].Direction)
+	assert.Equal(t, "latency,duplicate", history[0].Faults)
+	assert.Equal(t, "aaaa", history[0].Response)
+	assert.Empty(t, history[1].Faults)
+}
+
+func TestMockEndpointFlushesReorderedFrameOnClose(t *testing.T) {
+	db := setupTestDB()
+	mocks := services.NewMockServerManager(db, services.NewWebSocketHub())
+	router := setupMockRouter(db, mocks)
+	router.POST("/api/mocks/:id/rules", NewMockHandler(db, mocks, services.NewWebSocketHub()).CreateMockRule)
+
+	// 응답을 다음 프레임 뒤로 미루지만 다음 프레임 없이 연결이 끝남
+	id, addr := startMock(t, router, `{"name":"reorder","bind_address":"127.0.0.1","faults":{"reorder_rate":1}}`)
+	resp := doJSON(router, "POST", "/api/mocks/"+itoa(id)+"/rules", `{"name":"echo","response_hex":"aa"}`)
+	require.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+
+	client, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer client.Close()
+	client.Write([]byte{0x01})
+	require.Eventually(t, func() bool { return len(mockHistory(t, router, id)) == 2 }, time.Second, 10*time.Millisecond)
+	require.NoError(t, client.(*net.TCPConn).CloseWrite())
+
+	client.SetReadDeadline(time.Now().Add(time.Second))
+	reply, err := io.ReadAll(client)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0xAA}, reply)
+
+	history := mockHistory(t, router, id)
+	require.Len(t, history, 3)
+	assert.Equal(t, "reorder", history[0].Faults)
+	assert.Equal(t, "aa", history[0].Response)
+	assert.Equal(t, "reorder", history[1].Faults)
+	assert.Empty(t, history[1].Response)
+}
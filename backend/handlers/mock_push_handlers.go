@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/fake-edge-server/models"
+	"github.com/gin-gonic/gin"
+)
+
+// applyPushRequest는 요청 값을 주기 전송 모델에 반영합니다.
+func applyPushRequest(push *models.MockPush, req models.MockPushRequest) {
+	push.Name = req.Name
+	push.PacketID = req.PacketID
+	push.IntervalMs = req.IntervalMs
+	push.Cron = req.Cron
+	push.Timezone = req.Timezone
+	push.Enabled = req.Enabled
+}
+
+// validatePush는 실행 시점 설정과 전송할 패킷을 검증합니다.
+func (h *MockHandler) validatePush(push models.MockPush) error {
+	if err := push.Validate(); err != nil {
+		return err
+	}
+	var packet models.TCPPacket
+	if err := h.DB.First(&packet, push.PacketID).Error; err != nil {
+		return errors.New("전송할 패킷을 찾을 수 없습니다")
+	}
+	return packet.CheckPushable()
+}
+
+// getPushByID는 URL 파라미터로 목 엔드포인트에 속한 주기 전송을 조회합니다.
+func (h *MockHandler) getPushByID(c *gin.Context) (*models.MockPush, bool) {
+	var push models.MockPush
+	result := h.DB.Where("mock_endpoint_id = ?", c.Param("id")).First(&push, c.Param("push_id"))
+	if result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "주기 전송을 찾을 수 없습니다"})
+		return nil, false
+	}
+	return &push, true
+}
+
+// GetMockPushes는 목 엔드포인트의 주기 전송 목록을 반환합니다.
+func (h *MockHandler) GetMockPushes(c *gin.Context) {
+	endpoint, ok := h.getMockByID(c)
+	if !ok {
+		return
+	}
+
+	var pushes []models.MockPush
+	if err := h.DB.Where("mock_endpoint_id = ?", endpoint.ID).Find(&pushes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, pushes)
+}
+
+// CreateMockPush는 목 엔드포인트에 주기 전송을 추가합니다.
+func (h *MockHandler) CreateMockPush(c *gin.Context) {
+	endpoint, ok := h.getMockByID(c)
+	if !ok {
+		return
+	}
+
+	var req models.MockPushRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "유효하지 않은 요청 형식: " + err.Error()})
+		return
+	}
+
+	push := models.MockPush{MockEndpointID: endpoint.ID}
+	applyPushRequest(&push, req)
+	if err := h.validatePush(push); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.DB.Create(&push).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "주기 전송 생성 실패: " + err.Error()})
+		return
+	}
+	h.Mocks.ReloadPushes(endpoint.ID)
+
+	c.JSON(http.StatusCreated, push)
+}
+
+// UpdateMockPush는 주기 전송을 수정하고 실행 중인 엔드포인트에 반영합니다.
+func (h *MockHandler) UpdateMockPush(c *gin.Context) {
+	push, ok := h.getPushByID(c)
+	if !ok {
+		return
+	}
+
+	var req models.MockPushRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "유효하지 않은 요청 형식: " + err.Error()})
+		return
+	}
+
+	applyPushRequest(push, req)
+	if err := h.validatePush(*push); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.DB.Save(push).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "주기 전송 업데이트 실패: " + err.Error()})
+		return
+	}
+	h.Mocks.ReloadPushes(push.MockEndpointID)
+
+	c.JSON(http.StatusOK, push)
+}
+
+// DeleteMockPush는 주기 전송을 삭제합니다.
+func (h *MockHandler) DeleteMockPush(c *gin.Context) {
+	push, ok := h.getPushByID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.DB.Delete(push).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "주기 전송 삭제 실패: " + err.Error()})
+		return
+	}
+	h.Mocks.ReloadPushes(push.MockEndpointID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "주기 전송이 성공적으로 삭제되었습니다"})
+}
+
+// PushMockPacket은 패킷을 지정한 연결(peer) 또는 모든 연결로 즉시 전송합니다.
+func (h *MockHandler) PushMockPacket(c *gin.Context) {
+	endpoint, ok := h.getMockByID(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		PacketID uint   `json:"packet_id" binding:"required"`
+		Peer     string `json:"peer"` // 비어 있으면 모든 연결
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "유효하지 않은 요청 형식: " + err.Error()})
+		return
+	}
+
+	var packet models.TCPPacket
+	if err := h.DB.First(&packet, req.PacketID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "패킷을 찾을 수 없습니다"})
+		return
+	}
+	if !endpoint.Running {
+		c.JSON(http.StatusConflict, gin.H{"error": "목 엔드포인트가 실행 중이 아닙니다"})
+		return
+	}
+
+	sent, err := h.Mocks.Push(endpoint.ID, req.Peer, packet)
+	if err != nil && sent == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "sent": sent})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "전송 완료", "sent": sent})
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"encoding/hex"
+	"errors"
+	"net/http"
+
+	"github.com/fake-edge-server/models"
+	"github.com/gin-gonic/gin"
+)
+
+// applyRuleRequest는 요청 값을 목 규칙 모델에 반영합니다.
+func applyRuleRequest(rule *models.MockRule, req models.MockRuleRequest) {
+	rule.Name = req.Name
+	rule.Priority = req.Priority
+	rule.MatchType = req.MatchType
+	rule.Offset = req.Offset
+	rule.Pattern = req.Pattern
+	rule.Mask = req.Mask
+	rule.FieldPacketID = req.FieldPacketID
+	rule.FieldOffset = req.FieldOffset
+	rule.FieldValue = req.FieldValue
+	rule.IsDefault = req.IsDefault
+	rule.NoResponse = req.NoResponse
+	rule.ResponsePacketID = req.ResponsePacketID
+	rule.ResponseHex = req.ResponseHex
+	rule.CopyFields = req.CopyFields
+	rule.DelayMs = req.DelayMs
+	rule.State = req.State
+	rule.NextState = req.NextState
+	rule.SetVars = req.SetVars
+	rule.UseVars = req.UseVars
+}
+
+// checkRuleStates는 규칙의 state와 next_state가 상태 목록에 있는지 검증합니다.
+func checkRuleStates(states models.StateList, rule models.MockRule) error {
+	if err := states.CheckState(rule.State); err != nil {
+		return err
+	}
+	return states.CheckState(rule.NextState)
+}
+
+// validateRule은 규칙 설정과 참조하는 패킷, 상태 이름을 검증합니다.
+func (h *MockHandler) validateRule(endpoint models.MockEndpoint, rule models.MockRule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+
+	if err := checkRuleStates(endpoint.States, rule); err != nil {
+		return err
+	}
+
+	if rule.MatchType == models.MatchField {
+		var packet models.TCPPacket
+		if err := h.DB.First(&packet, rule.FieldPacketID).Error; err != nil {
+			return errors.New("필드 정의 패킷을 찾을 수 없습니다")
+		}
+		if _, _, ok := packet.Data.Field(rule.FieldOffset); !ok {
+			return errors.New("필드 정의 패킷에 해당 오프셋의 필드가 없습니다")
+		}
+	}
+
+	responseLen := -1
+	switch {
+	case rule.NoResponse:
+	case rule.ResponseHex != "":
+		response, _ := hex.DecodeString(rule.ResponseHex)
+		responseLen = len(response)
+	default:
+		var packet models.TCPPacket
+		if err := h.DB.First(&packet, rule.ResponsePacketID).Error; err != nil {
+			return errors.New("응답 패킷을 찾을 수 없습니다")
+		}
+		responseLen = packet.Data.Len()
+	}
+	return rule.CheckBounds(responseLen)
+}
+
+// getRuleByID는 URL 파라미터로 목 엔드포인트에 속한 규칙을 조회합니다.
+func (h *MockHandler) getRuleByID(c *gin.Context) (*models.MockRule, bool) {
+	var rule models.MockRule
+	result := h.DB.Where("mock_endpoint_id = ?", c.Param("id")).First(&rule, c.Param("rule_id"))
+	if result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "목 규칙을 찾을 수 없습니다"})
+		return nil, false
+	}
+	return &rule, true
+}
+
+// GetMockRules는 목 엔드포인트의 규칙 목록을 검사 순서대로 반환합니다.
+func (h *MockHandler) GetMockRules(c *gin.Context) {
+	endpoint, ok := h.getMockByID(c)
+	if !ok {
+		return
+	}
+
+	var rules []models.MockRule
+	if err := h.DB.Where("mock_endpoint_id = ?", endpoint.ID).Order("priority, id").Find(&rules).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// CreateMockRule은 목 엔드포인트에 응답 규칙을 추가합니다.
+func (h *MockHandler) CreateMockRule(c *gin.Context) {
+	endpoint, ok := h.getMockByID(c)
+	if !ok {
+		return
+	}
+
+	var req models.MockRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "유효하지 않은 요청 형식: " + err.Error()})
+		return
+	}
+
+	rule := models.MockRule{MockEndpointID: endpoint.ID}
+	applyRuleRequest(&rule, req)
+	if err := h.validateRule(*endpoint, rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.DB.Create(&rule).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "목 규칙 생성 실패: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// UpdateMockRule은 목 규칙을 수정합니다. 실행 중인 엔드포인트에도 바로 적용됩니다.
+func (h *MockHandler) UpdateMockRule(c *gin.Context) {
+	endpoint, ok := h.getMockByID(c)
+	if !ok {
+		return
+	}
+	rule, ok := h.getRuleByID(c)
+	if !ok {
+		return
+	}
+
+	var req models.MockRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "유효하지 않은 요청 형식: " + err.Error()})
+		return
+	}
+
+	applyRuleRequest(rule, req)
+	if err := h.validateRule(*endpoint, *rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.DB.Save(rule).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "목 규칙 업데이트 실패: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// DeleteMockRule은 목 규칙을 삭제합니다.
+func (h *MockHandler) DeleteMockRule(c *gin.Context) {
+	rule, ok := h.getRuleByID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.DB.Delete(rule).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "목 규칙 삭제 실패: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "목 규칙이 성공적으로 삭제되었습니다"})
+}
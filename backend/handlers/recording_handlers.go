@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/fake-edge-server/models"
+	"github.com/fake-edge-server/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// RecordingHandler는 세션 기록과 목 재생 관리를 위한 핸들러 구조체입니다.
+type RecordingHandler struct {
+	DB     *gorm.DB
+	Sender *services.PacketSender
+}
+
+// NewRecordingHandler는 새로운 RecordingHandler 인스턴스를 생성합니다.
+func NewRecordingHandler(db *gorm.DB, sender *services.PacketSender) *RecordingHandler {
+	return &RecordingHandler{
+		DB:     db,
+		Sender: sender,
+	}
+}
+
+// StartRecording은 TCP 서버로 보내는 요청/응답의 기록을 시작합니다.
+func (h *RecordingHandler) StartRecording(c *gin.Context) {
+	var server models.TCPServer
+	if err := h.DB.First(&server, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "TCP 서버를 찾을 수 없습니다"})
+		return
+	}
+
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "유효하지 않은 요청 형식: " + err.Error()})
+		return
+	}
+	if req.Name == "" {
+		req.Name = server.Name
+	}
+
+	rec, err := h.Sender.StartRecording(server.ID, req.Name)
+	if errors.Is(err, services.ErrRecordingActive) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, rec)
+}
+
+// StopRecording은 TCP 서버의 진행 중인 기록을 종료합니다.
+func (h *RecordingHandler) StopRecording(c *gin.Context) {
+	var server models.TCPServer
+	if err := h.DB.First(&server, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "TCP 서버를 찾을 수 없습니다"})
+		return
+	}
+
+	rec, err := h.Sender.StopRecording(server.ID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rec)
+}
+
+// GetRecordings는 TCP 서버의 기록 목록을 반환합니다. 항목은 포함하지 않습니다.
+func (h *RecordingHandler) GetRecordings(c *gin.Context) {
+	var recordings []models.Recording
+	if err := h.DB.Where("tcp_server_id = ?", c.Param("id")).Order("id DESC").Find(&recordings).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, recordings)
+}
+
+// GetRecordingByID는 기록과 요청/응답 항목을 순서대로 반환합니다.
+func (h *RecordingHandler) GetRecordingByID(c *gin.Context) {
+	var rec models.Recording
+	err := h.DB.Preload("Entries", func(tx *gorm.DB) *gorm.DB { return tx.Order("seq") }).First(&rec, c.Param("id")).Error
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "기록을 찾을 수 없습니다"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rec)
+}
+
+// DeleteRecording은 기록과 항목을 삭제합니다.
+func (h *RecordingHandler) DeleteRecording(c *gin.Context) {
+	var rec models.Recording
+	if err := h.DB.First(&rec, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "기록을 찾을 수 없습니다"})
+		return
+	}
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("recording_id = ?", rec.ID).Delete(&models.RecordingEntry{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&rec).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "기록이 삭제되었습니다"})
+}
+
+// CreateReplayMock은 기록된 요청에 기록된 응답으로 답하는 목 엔드포인트를 생성합니다.
+func (h *RecordingHandler) CreateReplayMock(c *gin.Context) {
+	var rec models.Recording
+	if err := h.DB.First(&rec, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "기록을 찾을 수 없습니다"})
+		return
+	}
+	if rec.StoppedAt == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "기록을 먼저 종료해주세요"})
+		return
+	}
+
+	var req models.ReplayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "유효하지 않은 요청 형식: " + err.Error()})
+		return
+	}
+	if err := validateMockRequest(models.MockEndpointRequest{BindAddr: req.BindAddr, Port: req.Port}); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.FallbackPacketID != 0 {
+		var packet models.TCPPacket
+		if err := h.DB.First(&packet, req.FallbackPacketID).Error; err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "대체 응답 패킷을 찾을 수 없습니다"})
+			return
+		}
+	}
+
+	endpoint, err := services.CreateReplayMock(h.DB, rec.ID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, endpoint)
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/fake-edge-server/models"
+	"github.com/fake-edge-server/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupRecordingRouter(db *gorm.DB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	hub := services.NewWebSocketHub()
+	connManager := services.NewTCPConnectionManager()
+	sender := services.NewPacketSender(db, connManager, hub)
+	packets := NewTCPPacketHandler(db, connManager, hub, sender)
+	handler := NewRecordingHandler(db, sender)
+	tc := r.Group("/api/tcp")
+	{
+		tc.POST("/:id/packets/:packet_id/send", packets.SendTCPPacket)
+		tc.GET("/:id/recordings", handler.GetRecordings)
+		tc.POST("/:id/recordings", handler.StartRecording)
+		tc.POST("/:id/recordings/stop", handler.StopRecording)
+	}
+	rc := r.Group("/api/recordings")
+	{
+		rc.GET("/:id", handler.GetRecordingByID)
+		rc.DELETE("/:id", handler.DeleteRecording)
+		rc.POST("/:id/mock", handler.CreateReplayMock)
+	}
+	return r
+}
+
+func TestRecordSessionAndReplayAsMock(t *testing.T) {
+	db := setupTestDB()
+	router := setupRecordingRouter(db)
+
+	// 요청 바이트에 0x10을 더해 응답하는 장비. PacketSender는 연결을 유지한 채
+	// 다음 패킷을 보내므로 요청마다 연결을 닫으면 닫힌 연결에 쓰는 경쟁이 생김
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		buf := make([]byte, 16)
+		for {
+			n, err := conn.Read(buf)
+			if err != nil {
+				return
+			}
+			for i := range buf[:n] {
+				buf[i] += 0x10
+			}
+			time.Sleep(20 * time.Millisecond)
+			conn.Write(buf[:n])
+		}
+	}()
+
+	server := models.TCPServer{Name: "device", Host: "127.0.0.1", Port: ln.Addr().(*net.TCPAddr).Port}
+	db.Create(&server)
+	first := models.TCPPacket{TCPServerID: server.ID, Data: models.PacketData{{Offset: 0, Value: 1, Type: models.TypeUint8}}}
+	second := models.TCPPacket{TCPServerID: server.ID, Data: models.PacketData{{Offset: 0, Value: 2, Type: models.TypeUint8}}}
+	db.Create(&first)
+	db.Create(&second)
+
+	base := fmt.Sprintf("/api/tcp/%d", server.ID)
+	resp := doJSON(router, "POST", base+"/recordings", `{"name":"session"}`)
+	require.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+	var rec models.Recording
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &rec))
+
+	resp = doJSON(router, "POST", base+"/recordings", "")
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	resp = doJSON(router, "POST", "/api/recordings/"+itoa(rec.ID)+"/mock", `{"name":"replay"}`)
+	assert.Equal(t, http.StatusConflict, resp.Code)
+
+	for _, packet := range []models.TCPPacket{first, second, first} {
+		resp = doJSON(router, "POST", fmt.Sprintf("%s/packets/%d/send", base, packet.ID), "")
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	}
+	resp = doJSON(router, "POST", base+"/recordings/stop", "")
+	require.Equal(t, http.StatusOK, resp.Code)
+	resp = doJSON(router, "POST", base+"/recordings/stop", "")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	resp = doJSON(router, "GET", "/api/recordings/"+itoa(rec.ID), "")
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &rec))
+	require.Len(t, rec.Entries, 3)
+	assert.Equal(t, "01", rec.Entries[0].Request)
+	assert.Equal(t, "11", rec.Entries[0].Response)
+	assert.Equal(t, "12", rec.Entries[1].Response)
+	assert.GreaterOrEqual(t, rec.Entries[1].LatencyMs, int64(20))
+	assert.GreaterOrEqual(t, rec.Entries[2].OffsetMs, rec.Entries[1].OffsetMs)
+
+	// 같은 요청은 규칙 하나로 합치고 기록과 어긋난 요청에는 대체 응답
+	resp = doJSON(router, "POST", "/api/recordings/"+itoa(rec.ID)+"/mock",
+		`{"name":"replay","bind_address":"127.0.0.1","port":0,"speed":4,"fallback_hex":"ee"}`)
+	require.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+	var endpoint models.MockEndpoint
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &endpoint))
+
+	var count int64
+	db.Model(&models.MockRule{}).Where("mock_endpoint_id = ?", endpoint.ID).Count(&count)
+	assert.Equal(t, int64(3), count)
+	var rule models.MockRule
+	require.NoError(t, db.Where("mock_endpoint_id = ? AND pattern = ?", endpoint.ID, "02").First(&rule).Error)
+	assert.Equal(t, models.MatchExact, rule.MatchType)
+	assert.Equal(t, "12", rule.ResponseHex)
+	assert.Less(t, rule.DelayMs, int(rec.Entries[1].LatencyMs))
+
+	mocks := services.NewMockServerManager(db, services.NewWebSocketHub())
+	mockRouter := setupMockRouter(db, mocks)
+	resp = doJSON(mockRouter, "POST", "/api/mocks/"+itoa(endpoint.ID)+"/start", "")
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	defer mocks.Stop(endpoint.ID)
+	addr, _ := mocks.ListenAddr(endpoint.ID)
+
+	client, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer client.Close()
+	reply := make([]byte, 1)
+	for _, tc := range []struct{ req, want byte }{{0x02, 0x12}, {0x01, 0x11}, {0x07, 0xEE}} {
+		client.Write([]byte{tc.req})
+		client.SetReadDeadline(time.Now().Add(time.Second))
+		_, err = io.ReadFull(client, reply)
+		require.NoError(t, err)
+		assert.Equal(t, tc.want, reply[0])
+	}
+
+	resp = doJSON(router, "DELETE", "/api/recordings/"+itoa(rec.ID), "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	db.Model(&models.RecordingEntry{}).Count(&count)
+	assert.Zero(t, count)
+}
+
+func TestReplayMockRejectsEdgeRecording(t *testing.T) {
+	db := setupTestDB()
+	router := setupRecordingRouter(db)
+
+	// Edge 요청은 전송마다 메시지 ID가 바뀌어 기록된 요청과 일치하지 않음
+	stopped := time.Now()
+	rec := models.Recording{TCPServerID: 1, Name: "edge", StartedAt: stopped, StoppedAt: &stopped}
+	db.Create(&rec)
+	db.Create(&models.RecordingEntry{RecordingID: rec.ID, Seq: 1, Kind: models.PacketKindRaw, Request: "01", Response: "11"})
+	db.Create(&models.RecordingEntry{RecordingID: rec.ID, Seq: 2, Kind: models.PacketKindEdge, Request: "02", Response: "12"})
+
+	resp := doJSON(router, "POST", "/api/recordings/"+itoa(rec.ID)+"/mock", `{"name":"replay","bind_address":"127.0.0.1"}`)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Contains(t, resp.Body.String(), "edge")
+	var count int64
+	db.Model(&models.MockEndpoint{}).Count(&count)
+	assert.Zero(t, count)
+}
+
+func TestRecordingSurvivesRestart(t *testing.T) {
+	db := setupTestDB()
+	server := models.TCPServer{Name: "device", Host: "127.0.0.1", Port: 1}
+	db.Create(&server)
+	// 재시작 전에 시작된 기록은 새 PacketSender에서도 진행 중
+	db.Create(&models.Recording{TCPServerID: server.ID, Name: "before", StartedAt: time.Now()})
+
+	router := setupRecordingRouter(db)
+	base := fmt.Sprintf("/api/tcp/%d", server.ID)
+	resp := doJSON(router, "POST", base+"/recordings", "")
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	resp = doJSON(router, "POST", base+"/recordings/stop", "")
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	resp = doJSON(router, "POST", base+"/recordings", "")
+	assert.Equal(t, http.StatusCreated, resp.Code)
+}
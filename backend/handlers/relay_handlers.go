@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/fake-edge-server/models"
+	"github.com/fake-edge-server/services"
+	"github.com/fake-edge-server/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// RelayHandler는 투명 TCP 중계 관리를 위한 핸들러 구조체입니다.
+type RelayHandler struct {
+	DB     *gorm.DB
+	Relays *services.RelayManager
+	Hub    *services.WebSocketHub
+}
+
+// NewRelayHandler는 새로운 RelayHandler 인스턴스를 생성합니다.
+func NewRelayHandler(db *gorm.DB, relays *services.RelayManager, hub *services.WebSocketHub) *RelayHandler {
+	return &RelayHandler{
+		DB:     db,
+		Relays: relays,
+		Hub:    hub,
+	}
+}
+
+// validateRelayRequest는 중계 생성/수정 요청과 대상 서버를 검증합니다.
+func (h *RelayHandler) validateRelayRequest(req models.RelayRequest) error {
+	if req.BindAddr != "" && !utils.IsValidHost(req.BindAddr) {
+		return errors.New("유효한 바인드 주소를 입력해주세요")
+	}
+
+	if req.Port < 0 || req.Port > 65535 {
+		return errors.New("유효한 포트 번호를 입력해주세요 (0-65535)")
+	}
+
+	if err := req.Faults.Validate(); err != nil {
+		return errors.New("유효하지 않은 결함 설정: " + err.Error())
+	}
+
+	var server models.TCPServer
+	if err := h.DB.First(&server, req.TCPServerID).Error; err != nil {
+		return errors.New("중계할 TCP 서버를 찾을 수 없습니다")
+	}
+	if server.IsDatagram() {
+		return errors.New("UDP 서버는 중계할 수 없습니다")
+	}
+	return nil
+}
+
+// applyRelayRequest는 요청 값을 중계 모델에 반영합니다.
+func applyRelayRequest(relay *models.Relay, req models.RelayRequest) {
+	relay.Name = req.Name
+	relay.BindAddr = req.BindAddr
+	relay.Port = req.Port
+	relay.TCPServerID = req.TCPServerID
+	relay.UseCRC = req.UseCRC
+	relay.Faults = req.Faults
+}
+
+// getRelayByID는 URL 파라미터에서 ID를 추출하여 중계를 조회합니다.
+func (h *RelayHandler) getRelayByID(c *gin.Context) (*models.Relay, bool) {
+	var relay models.Relay
+	if err := h.DB.First(&relay, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "중계를 찾을 수 없습니다"})
+		return nil, false
+	}
+	relay.Running = h.Relays.IsRunning(relay.ID)
+	return &relay, true
+}
+
+// CreateRelay는 새로운 중계를 생성합니다.
+func (h *RelayHandler) CreateRelay(c *gin.Context) {
+	var req models.RelayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "유효하지 않은 요청 형식: " + err.Error()})
+		return
+	}
+
+	if err := h.validateRelayRequest(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var existing models.Relay
+	if h.DB.Where("name = ?", req.Name).First(&existing).RowsAffected > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "같은 이름의 중계가 이미 존재합니다"})
+		return
+	}
+
+	var relay models.Relay
+	applyRelayRequest(&relay, req)
+	if err := h.DB.Create(&relay).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "중계 생성 실패: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, relay)
+}
+
+// GetRelays는 모든 중계 목록을 실행 여부와 함께 반환합니다.
+func (h *RelayHandler) GetRelays(c *gin.Context) {
+	var relays []models.Relay
+	if err := h.DB.Find(&relays).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for i := range relays {
+		relays[i].Running = h.Relays.IsRunning(relays[i].ID)
+	}
+
+	c.JSON(http.StatusOK, relays)
+}
+
+// GetRelayByID는 특정 중계 정보를 반환합니다.
+func (h *RelayHandler) GetRelayByID(c *gin.Context) {
+	relay, ok := h.getRelayByID(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, relay)
+}
+
+// UpdateRelay는 중계 정보를 수정합니다. 실행 중에는 수정할 수 없습니다.
+func (h *RelayHandler) UpdateRelay(c *gin.Context) {
+	relay, ok := h.getRelayByID(c)
+	if !ok {
+		return
+	}
+	if relay.Running {
+		c.JSON(http.StatusConflict, gin.H{"error": "실행 중인 중계는 수정할 수 없습니다"})
+		return
+	}
+
+	var req models.RelayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "유효하지 않은 요청 형식: " + err.Error()})
+		return
+	}
+
+	if err := h.validateRelayRequest(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Name != relay.Name {
+		var existing models.Relay
+		if h.DB.Where("name = ? AND id != ?", req.Name, relay.ID).First(&existing).RowsAffected > 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "같은 이름의 중계가 이미 존재합니다"})
+			return
+		}
+	}
+
+	applyRelayRequest(relay, req)
+	if err := h.DB.Save(relay).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "중계 업데이트 실패: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, relay)
+}
+
+// DeleteRelay는 중계를 중지하고 삭제합니다. 중계 이력은 남겨 둡니다.
+func (h *RelayHandler) DeleteRelay(c *gin.Context) {
+	relay, ok := h.getRelayByID(c)
+	if !ok {
+		return
+	}
+	if relay.Running {
+		h.Relays.Stop(relay.ID)
+	}
+
+	if err := h.DB.Delete(relay).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "중계 삭제 실패: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "중계가 성공적으로 삭제되었습니다"})
+}
+
+// StartRelay는 중계의 수신 대기를 시작합니다.
+func (h *RelayHandler) StartRelay(c *gin.Context) {
+	relay, ok := h.getRelayByID(c)
+	if !ok {
+		return
+	}
+	var server models.TCPServer
+	if err := h.DB.First(&server, relay.TCPServerID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "중계할 TCP 서버를 찾을 수 없습니다"})
+		return
+	}
+	if err := h.Relays.Start(*relay, server); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "중계 시작 실패: " + err.Error()})
+		return
+	}
+
+	addr, _ := h.Relays.ListenAddr(relay.ID)
+	h.Hub.Broadcast(gin.H{"type": "relay_status", "relay_id": relay.ID, "running": true, "listen_addr": addr})
+	c.JSON(http.StatusOK, gin.H{
+		"id":          relay.ID,
+		"name":        relay.Name,
+		"message":     "중계 시작됨",
+		"listen_addr": addr,
+	})
+}
+
+// StopRelay는 중계의 수신 대기와 중계 중인 모든 연결을 종료합니다.
+func (h *RelayHandler) StopRelay(c *gin.Context) {
+	relay, ok := h.getRelayByID(c)
+	if !ok {
+		return
+	}
+	if err := h.Relays.Stop(relay.ID); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.Hub.Broadcast(gin.H{"type": "relay_status", "relay_id": relay.ID, "running": false})
+	c.JSON(http.StatusOK, gin.H{
+		"id":      relay.ID,
+		"name":    relay.Name,
+		"message": "중계 중지됨",
+	})
+}
+
+// GetRelayStatus는 중계의 실행 상태, 수신 주소, 중계 중인 연결을 반환합니다.
+func (h *RelayHandler) GetRelayStatus(c *gin.Context) {
+	relay, ok := h.getRelayByID(c)
+	if !ok {
+		return
+	}
+	addr, _ := h.Relays.ListenAddr(relay.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":          relay.ID,
+		"name":        relay.Name,
+		"running":     relay.Running,
+		"listen_addr": addr,
+		"connections": h.Relays.Connections(relay.ID),
+	})
+}
+
+// GetRelayHistory는 중계가 주고받은 프레임 이력을 반환합니다.
+func (h *RelayHandler) GetRelayHistory(c *gin.Context) {
+	var history []models.TCPPacketHistory
+	result := h.DB.Where("relay_id = ?", c.Param("id")).Order("id desc").Find(&history)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "이력 조회 실패: " + result.Error.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
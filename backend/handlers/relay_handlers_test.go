@@ -0,0 +1,355 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/fake-edge-server/models"
+	"github.com/fake-edge-server/services"
+	"github.com/fake-edge-server/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupRelayRouter(db *gorm.DB, relays *services.RelayManager) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	handler := NewRelayHandler(db, relays, services.NewWebSocketHub())
+	rl := r.Group("/api/relays")
+	{
+		rl.POST("", handler.CreateRelay)
+		rl.PUT("/:id", handler.UpdateRelay)
+		rl.DELETE("/:id", handler.DeleteRelay)
+		rl.GET("/:id/status", handler.GetRelayStatus)
+		rl.POST("/:id/start", handler.StartRelay)
+		rl.POST("/:id/stop", handler.StopRelay)
+		rl.GET("/:id/history", handler.GetRelayHistory)
+	}
+	return r
+}
+
+func TestRelayForwardsAndRecordsBothDirections(t *testing.T) {
+	db := setupTestDB()
+	relays := services.NewRelayManager(db, services.NewWebSocketHub())
+	router := setupRelayRouter(db, relays)
+
+	// 요청의 첫 바이트에 0x80을 더하고 값을 그대로 돌려주는 장비
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		buf := make([]byte, 16)
+		for {
+			n, err := conn.Read(buf)
+			if err != nil {
+				return
+			}
+			buf[0] |= 0x80
+			conn.Write(buf[:n])
+		}
+	}()
+
+	server := models.TCPServer{Name: "device", Host: "127.0.0.1", Port: ln.Addr().(*net.TCPAddr).Port}
+	db.Create(&server)
+	read := models.TCPPacket{TCPServerID: server.ID, Name: "read", Data: models.PacketData{
+		{Offset: 0, Value: 0x01, Type: models.TypeUint8, Desc: "cmd"},
+		{Offset: 1, Type: models.TypeUint16, IsChained: true, Desc: "register"},
+		{Offset: 2, Type: models.TypeUint16, IsChained: true},
+	}}
+	db.Create(&read)
+
+	resp := doJSON(router, "POST", "/api/relays", fmt.Sprintf(`{"name":"tap","bind_address":"127.0.0.1","port":0,"tcp_server_id":%d}`, server.ID))
+	require.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+	var relay models.Relay
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &relay))
+	base := "/api/relays/" + itoa(relay.ID)
+
+	resp = doJSON(router, "POST", base+"/start", "")
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	defer relays.Stop(relay.ID)
+	addr, _ := relays.ListenAddr(relay.ID)
+
+	client, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer client.Close()
+	client.Write([]byte{0x01, 0x34, 0x12})
+	reply := make([]byte, 3)
+	client.SetReadDeadline(time.Now().Add(time.Second))
+	_, err = io.ReadFull(client, reply)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x81, 0x34, 0x12}, reply)
+
+	resp = doJSON(router, "GET", base+"/status", "")
+	var status struct {
+		Running     bool                       `json:"running"`
+		Connections []services.RelayConnection `json:"connections"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &status))
+	assert.True(t, status.Running)
+	require.Len(t, status.Connections, 1)
+	assert.Equal(t, client.LocalAddr().String(), status.Connections[0].Peer)
+	assert.Equal(t, ln.Addr().String(), status.Connections[0].Upstream)
+
+	var history []models.TCPPacketHistory
+	assert.Eventually(t, func() bool {
+		resp = doJSON(router, "GET", base+"/history", "")
+		json.Unmarshal(resp.Body.Bytes(), &history)
+		return len(history) == 2
+	}, time.Second, 10*time.Millisecond)
+	require.Len(t, history, 2)
+	// raw 중계는 받은 즉시 전달하고 기록은 나중에 하므로 두 방향의 기록 순서는 정해져 있지 않음
+	if history[0].Direction == models.DirectionClientToServer {
+		history[0], history[1] = history[1], history[0]
+	}
+	assert.Equal(t, models.DirectionServerToClient, history[0].Direction)
+	assert.Equal(t, "813412", history[0].Response)
+	assert.Zero(t, history[0].TCPPacketID)
+	assert.Equal(t, models.DirectionClientToServer, history[1].Direction)
+	assert.Equal(t, "013412", history[1].Request)
+	assert.Equal(t, read.ID, history[1].TCPPacketID)
+	assert.Equal(t, server.ID, history[1].TCPServerID)
+
+	var decoded []models.DecodedField
+	require.NoError(t, json.Unmarshal([]byte(history[1].Decoded), &decoded))
+	require.Len(t, decoded, 2)
+	assert.Equal(t, "register", decoded[1].Desc)
+	assert.Equal(t, "4660", decoded[1].Value)
+
+	resp = doJSON(router, "PUT", base, fmt.Sprintf(`{"name":"tap","tcp_server_id":%d}`, server.ID))
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	resp = doJSON(router, "POST", base+"/stop", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	resp = doJSON(router, "POST", base+"/stop", "")
+	assert.Equal(t, http.StatusConflict, resp.Code)
+}
+
+func TestRelayForwardsRawStreamWithoutWaiting(t *testing.T) {
+	db := setupTestDB()
+	relays := services.NewRelayManager(db, services.NewWebSocketHub())
+	router := setupRelayRouter(db, relays)
+
+	// 접속하면 10ms마다 1바이트씩 30바이트를 보내는 장비
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for i := 0; i < 30; i++ {
+			conn.Write([]byte{byte(i)})
+			time.Sleep(10 * time.Millisecond)
+		}
+		io.Copy(io.Discard, conn)
+	}()
+
+	server := models.TCPServer{Name: "stream", Host: "127.0.0.1", Port: ln.Addr().(*net.TCPAddr).Port}
+	db.Create(&server)
+	resp := doJSON(router, "POST", "/api/relays", fmt.Sprintf(`{"name":"tap","bind_address":"127.0.0.1","tcp_server_id":%d}`, server.ID))
+	require.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+	var relay models.Relay
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &relay))
+	require.Equal(t, http.StatusOK, doJSON(router, "POST", "/api/relays/"+itoa(relay.ID)+"/start", "").Code)
+	defer relays.Stop(relay.ID)
+	addr, _ := relays.ListenAddr(relay.ID)
+
+	// 스트림이 끝나기(300ms) 전에 앞부분이 전달됨
+	client, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer client.Close()
+	client.SetReadDeadline(time.Now().Add(150 * time.Millisecond))
+	first := make([]byte, 1)
+	_, err = io.ReadFull(client, first)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0}, first)
+
+	rest := make([]byte, 29)
+	client.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, err = io.ReadFull(client, rest)
+	require.NoError(t, err)
+	assert.Equal(t, byte(29), rest[28])
+
+	// 기록은 유휴 구간으로 나뉘어도 전체 바이트를 담음
+	assert.Eventually(t, func() bool {
+		var history []models.TCPPacketHistory
+		db.Where("relay_id = ?", relay.ID).Find(&history)
+		total := 0
+		for _, h := range history {
+			total += len(h.Response) / 2
+		}
+		return total == 30
+	}, 2*time.Second, 20*time.Millisecond)
+}
+
+func TestRelayPassesThroughUnframedBytes(t *testing.T) {
+	db := setupTestDB()
+	relays := services.NewRelayManager(db, services.NewWebSocketHub())
+	router := setupRelayRouter(db, relays)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		io.Copy(conn, conn)
+	}()
+
+	server := models.TCPServer{Name: "echo", Host: "127.0.0.1", Port: ln.Addr().(*net.TCPAddr).Port}
+	db.Create(&server)
+	resp := doJSON(router, "POST", "/api/relays", fmt.Sprintf(`{"name":"tap","bind_address":"127.0.0.1","use_crc":true,"tcp_server_id":%d}`, server.ID))
+	require.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+	var relay models.Relay
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &relay))
+	require.Equal(t, http.StatusOK, doJSON(router, "POST", "/api/relays/"+itoa(relay.ID)+"/start", "").Code)
+	defer relays.Stop(relay.ID)
+	addr, _ := relays.ListenAddr(relay.ID)
+
+	client, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer client.Close()
+	exchange := func(data []byte) []byte {
+		t.Helper()
+		_, err := client.Write(data)
+		require.NoError(t, err)
+		reply := make([]byte, len(data))
+		client.SetReadDeadline(time.Now().Add(time.Second))
+		_, err = io.ReadFull(client, reply)
+		require.NoError(t, err)
+		return reply
+	}
+
+	// 프레임 형식에 맞지 않는 바이트도 연결을 끊지 않고 그대로 전달하고 raw로 기록함
+	assert.Equal(t, []byte("hi"), exchange([]byte("hi")))
+	frame := utils.BuildPacket([]byte{0x01})
+	assert.Equal(t, frame, exchange(frame))
+
+	var history []models.TCPPacketHistory
+	assert.Eventually(t, func() bool {
+		db.Where("relay_id = ? AND direction = ?", relay.ID, models.DirectionClientToServer).Order("id").Find(&history)
+		return len(history) == 2
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, "6869", history[0].Request)
+	assert.Equal(t, "01", history[1].Request)
+}
+
+func TestRelayStopsDuringInjectedLatency(t *testing.T) {
+	db := setupTestDB()
+	relays := services.NewRelayManager(db, services.NewWebSocketHub())
+	router := setupRelayRouter(db, relays)
+	port, _ := startEchoDevice(t)
+	server := models.TCPServer{Name: "device", Host: "127.0.0.1", Port: port}
+	db.Create(&server)
+
+	body := fmt.Sprintf(`{"name":"slow","bind_address":"127.0.0.1","tcp_server_id":%d,"faults":{"latency_rate":1,"latency_ms":10000}}`, server.ID)
+	resp := doJSON(router, "POST", "/api/relays", body)
+	require.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+	var relay models.Relay
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &relay))
+	require.Equal(t, http.StatusOK, doJSON(router, "POST", "/api/relays/"+itoa(relay.ID)+"/start", "").Code)
+	addr, _ := relays.ListenAddr(relay.ID)
+
+	client, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer client.Close()
+	client.Write([]byte{0x01})
+	assert.Eventually(t, func() bool { return len(relays.Connections(relay.ID)) == 1 }, time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+
+	// 10초 지연 중이어도 중지는 바로 끝남
+	started := time.Now()
+	require.Equal(t, http.StatusOK, doJSON(router, "POST", "/api/relays/"+itoa(relay.ID)+"/stop", "").Code)
+	assert.Less(t, time.Since(started), 2*time.Second)
+}
+
+func TestCreateRelayValidation(t *testing.T) {
+	db := setupTestDB()
+	router := setupRelayRouter(db, services.NewRelayManager(db, services.NewWebSocketHub()))
+
+	udp := models.TCPServer{Name: "udp", Host: "127.0.0.1", Port: 5000, Transport: models.TransportUDP}
+	db.Create(&udp)
+
+	resp := doJSON(router, "POST", "/api/relays", `{"name":"missing","tcp_server_id":99}`)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	resp = doJSON(router, "POST", "/api/relays", fmt.Sprintf(`{"name":"udp","tcp_server_id":%d}`, udp.ID))
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	resp = doJSON(router, "POST", "/api/relays", fmt.Sprintf(`{"name":"port","port":70000,"tcp_server_id":%d}`, udp.ID))
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestRelayInjectsResetTowardsClient(t *testing.T) {
+	db := setupTestDB()
+	relays := services.NewRelayManager(db, services.NewWebSocketHub())
+	router := setupRelayRouter(db, relays)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		buf := make([]byte, 16)
+		n, _ := conn.Read(buf)
+		conn.Write(buf[:n])
+		io.Copy(io.Discard, conn)
+	}()
+
+	server := models.TCPServer{Name: "device", Host: "127.0.0.1", Port: ln.Addr().(*net.TCPAddr).Port}
+	db.Create(&server)
+
+	body := fmt.Sprintf(`{"name":"tap","bind_address":"127.0.0.1","tcp_server_id":%d,"faults":{"reset_rate":1,"direction":"sideways"}}`, server.ID)
+	assert.Equal(t, http.StatusBadRequest, doJSON(router, "POST", "/api/relays", body).Code)
+
+	// 요청은 그대로 전달하고 응답 방향에서만 연결을 RST로 끊음
+	body = fmt.Sprintf(`{"name":"tap","bind_address":"127.0.0.1","tcp_server_id":%d,"faults":{"reset_rate":1,"direction":"server_to_client"}}`, server.ID)
+	resp := doJSON(router, "POST", "/api/relays", body)
+	require.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+	var relay models.Relay
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &relay))
+	require.Equal(t, http.StatusOK, doJSON(router, "POST", "/api/relays/"+itoa(relay.ID)+"/start", "").Code)
+	defer relays.Stop(relay.ID)
+	addr, _ := relays.ListenAddr(relay.ID)
+
+	client, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer client.Close()
+	client.Write([]byte{0x01})
+	client.SetReadDeadline(time.Now().Add(time.Second))
+	_, err = client.Read(make([]byte, 1))
+	assert.ErrorContains(t, err, "reset")
+
+	var history []models.TCPPacketHistory
+	assert.Eventually(t, func() bool {
+		resp = doJSON(router, "GET", "/api/relays/"+itoa(relay.ID)+"/history", "")
+		json.Unmarshal(resp.Body.Bytes(), &history)
+		return len(history) == 2
+	}, time.Second, 10*time.Millisecond)
+	require.Len(t, history, 2)
+	for _, h := range history {
+		if h.Direction == models.DirectionServerToClient {
+			assert.Equal(t, "reset", h.Faults)
+		} else {
+			assert.Empty(t, h.Faults)
+		}
+	}
+}
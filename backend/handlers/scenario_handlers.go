@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/fake-edge-server/models"
+	"github.com/fake-edge-server/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ScenarioHandler는 다단계 시나리오 관리와 실행을 위한 핸들러 구조체입니다.
+type ScenarioHandler struct {
+	DB     *gorm.DB
+	Runner *services.ScenarioRunner
+}
+
+// NewScenarioHandler는 새로운 ScenarioHandler 인스턴스를 생성합니다.
+func NewScenarioHandler(db *gorm.DB, runner *services.ScenarioRunner) *ScenarioHandler {
+	return &ScenarioHandler{
+		DB:     db,
+		Runner: runner,
+	}
+}
+
+// validateScenarioRequest는 시나리오 단계, 초기 변수, 기본 서버와 보낼 패킷을 검증합니다.
+func (h *ScenarioHandler) validateScenarioRequest(req models.ScenarioRequest) error {
+	if len(req.Steps) == 0 {
+		return errors.New("시나리오 단계가 없습니다")
+	}
+	if err := req.Steps.Validate(); err != nil {
+		return err
+	}
+	if err := req.Vars.Validate(); err != nil {
+		return err
+	}
+
+	if req.TCPServerID != 0 {
+		var server models.TCPServer
+		if err := h.DB.First(&server, req.TCPServerID).Error; err != nil {
+			return errors.New("TCP 서버를 찾을 수 없습니다")
+		}
+	}
+	_, err := services.LoadScenarioPackets(h.DB, req.Steps)
+	return err
+}
+
+// applyScenarioRequest는 요청 값을 시나리오 모델에 반영합니다.
+func applyScenarioRequest(scenario *models.Scenario, req models.ScenarioRequest) {
+	scenario.Name = req.Name
+	scenario.Desc = req.Desc
+	scenario.TCPServerID = req.TCPServerID
+	scenario.Vars = req.Vars
+	scenario.Steps = req.Steps
+}
+
+// getScenarioByID는 URL 파라미터에서 ID를 추출하여 시나리오를 조회합니다.
+func (h *ScenarioHandler) getScenarioByID(c *gin.Context) (*models.Scenario, bool) {
+	var scenario models.Scenario
+	if err := h.DB.First(&scenario, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "시나리오를 찾을 수 없습니다"})
+		return nil, false
+	}
+	return &scenario, true
+}
+
+// getScenarioRunByID는 시나리오의 실행 기록을 조회하고, 실행 중이면 지금까지의 단계 결과를 채웁니다.
+func (h *ScenarioHandler) getScenarioRunByID(c *gin.Context, scenario *models.Scenario) (*models.ScenarioRun, bool) {
+	var run models.ScenarioRun
+	if err := h.DB.Where("scenario_id = ?", scenario.ID).First(&run, c.Param("run_id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "시나리오 실행 기록을 찾을 수 없습니다"})
+		return nil, false
+	}
+	if logs, ok := h.Runner.Log(run.ID); ok {
+		run.Log = logs
+	}
+	return &run, true
+}
+
+// CreateScenario는 새로운 시나리오를 생성합니다.
+func (h *ScenarioHandler) CreateScenario(c *gin.Context) {
+	var req models.ScenarioRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "유효하지 않은 요청 형식: " + err.Error()})
+		return
+	}
+
+	if err := h.validateScenarioRequest(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var existing models.Scenario
+	if h.DB.Where("name = ?", req.Name).First(&existing).RowsAffected > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "같은 이름의 시나리오가 이미 존재합니다"})
+		return
+	}
+
+	var scenario models.Scenario
+	applyScenarioRequest(&scenario, req)
+	if err := h.DB.Create(&scenario).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "시나리오 생성 실패: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, scenario)
+}
+
+// GetScenarios는 모든 시나리오 목록을 반환합니다.
+func (h *ScenarioHandler) GetScenarios(c *gin.Context) {
+	var scenarios []models.Scenario
+	if err := h.DB.Find(&scenarios).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, scenarios)
+}
+
+// GetScenarioByID는 특정 시나리오 정보를 반환합니다.
+func (h *ScenarioHandler) GetScenarioByID(c *gin.Context) {
+	scenario, ok := h.getScenarioByID(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, scenario)
+}
+
+// UpdateScenario는 시나리오 정보를 수정합니다. 실행 중인 실행에는 영향을 주지 않습니다.
+func (h *ScenarioHandler) UpdateScenario(c *gin.Context) {
+	scenario, ok := h.getScenarioByID(c)
+	if !ok {
+		return
+	}
+
+	var req models.ScenarioRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "유효하지 않은 요청 형식: " + err.Error()})
+		return
+	}
+
+	if err := h.validateScenarioRequest(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Name != scenario.Name {
+		var existing models.Scenario
+		if h.DB.Where("name = ? AND id != ?", req.Name, scenario.ID).First(&existing).RowsAffected > 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "같은 이름의 시나리오가 이미 존재합니다"})
+			return
+		}
+	}
+
+	applyScenarioRequest(scenario, req)
+	if err := h.DB.Save(scenario).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "시나리오 업데이트 실패: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, scenario)
+}
+
+// DeleteScenario는 시나리오와 실행 기록을 삭제합니다. 실행 중에는 삭제할 수 없습니다.
+func (h *ScenarioHandler) DeleteScenario(c *gin.Context) {
+	scenario, ok := h.getScenarioByID(c)
+	if !ok {
+		return
+	}
+	if h.Runner.IsRunning(scenario.ID) {
+		c.JSON(http.StatusConflict, gin.H{"error": "실행 중인 시나리오는 삭제할 수 없습니다"})
+		return
+	}
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("scenario_id = ?", scenario.ID).Delete(&models.ScenarioRun{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(scenario).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "시나리오 삭제 실패: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "시나리오가 성공적으로 삭제되었습니다"})
+}
+
+// RunScenario는 시나리오를 TCP 서버에 대해 실행합니다. 진행 상황은 WebSocket으로 전달됩니다.
+func (h *ScenarioHandler) RunScenario(c *gin.Context) {
+	scenario, ok := h.getScenarioByID(c)
+	if !ok {
+		return
+	}
+
+	var req models.ScenarioRunRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "유효하지 않은 요청 형식: " + err.Error()})
+		return
+	}
+	if err := req.Vars.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	serverID := req.TCPServerID
+	if serverID == 0 {
+		serverID = scenario.TCPServerID
+	}
+	if serverID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "시나리오를 실행할 TCP 서버를 지정해주세요"})
+		return
+	}
+	var server models.TCPServer
+	if err := h.DB.First(&server, serverID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "TCP 서버를 찾을 수 없습니다"})
+		return
+	}
+	run, err := h.Runner.Launch(*scenario, server, req.Vars)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "시나리오 실행 실패: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, run)
+}
+
+// GetScenarioRuns는 시나리오의 실행 기록 목록을 최신 순으로 반환합니다.
+func (h *ScenarioHandler) GetScenarioRuns(c *gin.Context) {
+	scenario, ok := h.getScenarioByID(c)
+	if !ok {
+		return
+	}
+
+	var runs []models.ScenarioRun
+	if err := h.DB.Where("scenario_id = ?", scenario.ID).Order("id DESC").Find(&runs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for i := range runs {
+		if logs, ok := h.Runner.Log(runs[i].ID); ok {
+			runs[i].Log = logs
+		}
+	}
+
+	c.JSON(http.StatusOK, runs)
+}
+
+// GetScenarioRunByID는 실행 기록 하나와 단계별 결과를 반환합니다.
+func (h *ScenarioHandler) GetScenarioRunByID(c *gin.Context) {
+	scenario, ok := h.getScenarioByID(c)
+	if !ok {
+		return
+	}
+	run, ok := h.getScenarioRunByID(c, scenario)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, run)
+}
+
+// StopScenarioRun은 실행 중인 시나리오를 현재 단계에서 중지합니다.
+func (h *ScenarioHandler) StopScenarioRun(c *gin.Context) {
+	scenario, ok := h.getScenarioByID(c)
+	if !ok {
+		return
+	}
+	run, ok := h.getScenarioRunByID(c, scenario)
+	if !ok {
+		return
+	}
+	if err := h.Runner.Stop(run.ID); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"id": run.ID, "message": "시나리오 중지 요청됨"})
+}
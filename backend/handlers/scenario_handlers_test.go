@@ -0,0 +1,310 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/fake-edge-server/models"
+	"github.com/fake-edge-server/services"
+	"github.com/fake-edge-server/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupScenarioRouter(db *gorm.DB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	handler := NewScenarioHandler(db, services.NewScenarioRunner(db, services.NewWebSocketHub()))
+	sc := r.Group("/api/scenarios")
+	{
+		sc.POST("", handler.CreateScenario)
+		sc.GET("", handler.GetScenarios)
+		sc.GET("/:id", handler.GetScenarioByID)
+		sc.PUT("/:id", handler.UpdateScenario)
+		sc.DELETE("/:id", handler.DeleteScenario)
+		sc.POST("/:id/run", handler.RunScenario)
+		sc.GET("/:id/runs", handler.GetScenarioRuns)
+		sc.GET("/:id/runs/:run_id", handler.GetScenarioRunByID)
+		sc.POST("/:id/runs/:run_id/stop", handler.StopScenarioRun)
+	}
+	return r
+}
+
+// startLoginDevice는 로그인(0x01)에 토큰 beef로 응답하고, 올바른 토큰을 담은
+// 읽기(0x02 토큰)에는 연결마다 1씩 늘어나는 값을, 틀린 토큰에는 0xEE를 돌려주는 장비를 띄웁니다.
+func startLoginDevice(t *testing.T) int {
+	t.Helper()
+	format := utils.DefaultFrameFormat
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				scanner := bufio.NewScanner(conn)
+				scanner.Split(format.Split)
+				var reads byte
+				reply := func(payload ...byte) {
+					frame, _ := format.Build(payload)
+					conn.Write(frame)
+				}
+				for scanner.Scan() {
+					payload, err := format.Unpack(scanner.Bytes())
+					if err != nil || len(payload) == 0 {
+						continue
+					}
+					switch {
+					case payload[0] == 0x01:
+						reply(0x81, 0xbe, 0xef)
+					case payload[0] == 0x02 && len(payload) == 3 && payload[1] == 0xbe && payload[2] == 0xef:
+						reads++
+						reply(0x82, reads)
+					default:
+						reply(0xee)
+					}
+				}
+			}()
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+func createLoginPackets(t *testing.T, db *gorm.DB, port int) (models.TCPServer, models.TCPPacket, models.TCPPacket) {
+	t.Helper()
+	server := models.TCPServer{Name: "device", Host: "127.0.0.1", Port: port}
+	require.NoError(t, db.Create(&server).Error)
+	login := models.TCPPacket{TCPServerID: server.ID, Name: "login", UseCRC: true, Data: models.PacketData{
+		{Offset: 0, Value: 0x01, Type: models.TypeUint8},
+	}}
+	require.NoError(t, db.Create(&login).Error)
+	read := models.TCPPacket{TCPServerID: server.ID, Name: "read", UseCRC: true, Data: models.PacketData{
+		{Offset: 0, Value: 0x02, Type: models.TypeUint8},
+		{Offset: 1, Value: 0x00, Type: models.TypeUint16},
+	}}
+	require.NoError(t, db.Create(&read).Error)
+	return server, login, read
+}
+
+// loginScenario는 로그인 → 토큰 추출 → 토큰으로 읽기를 값이 3이 될 때까지 반복 → 값 분기를 수행합니다.
+func loginScenario(serverID, loginID, readID uint) models.ScenarioRequest {
+	return models.ScenarioRequest{
+		Name:        "login flow",
+		TCPServerID: serverID,
+		Steps: models.ScenarioSteps{
+			{Name: "login", Type: models.StepSend, PacketID: loginID},
+			{Type: models.StepExpect, Assertions: []models.ScenarioCheck{{Offset: 0, Type: models.TypeUint8, Op: models.OpEq, Value: "129"}}},
+			{Type: models.StepExtract, Extract: models.VarBindings{{Name: "token", Offset: 1, Length: 2}}},
+			{Name: "read", Type: models.StepSend, PacketID: readID, UseVars: models.VarBindings{{Name: "token", Offset: 1}}},
+			{Type: models.StepExpect, Assertions: []models.ScenarioCheck{{Offset: 0, Type: models.TypeUint8, Op: models.OpEq, Value: "130"}}},
+			{Type: models.StepExtract, Extract: models.VarBindings{{Name: "count", Offset: 1, Length: 1}}},
+			{Type: models.StepLoop, Target: "read", Count: 5, Condition: &models.ScenarioCheck{Var: "count", Type: models.TypeUint8, Op: models.OpGe, Value: "3"}},
+			{Type: models.StepBranch, Target: "done", Condition: &models.ScenarioCheck{Var: "count", Type: models.TypeUint8, Op: models.OpEq, Value: "${expected}"}},
+			{Name: "unexpected", Type: models.StepExpect, TimeoutMs: 50},
+			{Name: "done", Type: models.StepWait, DelayMs: 1},
+		},
+		Vars: models.ScenarioVars{"expected": "03"},
+	}
+}
+
+func createScenario(t *testing.T, router *gin.Engine, req models.ScenarioRequest) models.Scenario {
+	t.Helper()
+	body, err := json.Marshal(req)
+	require.NoError(t, err)
+	resp := doJSON(router, "POST", "/api/scenarios", string(body))
+	require.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+	var scenario models.Scenario
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &scenario))
+	return scenario
+}
+
+func runScenario(t *testing.T, router *gin.Engine, id uint, body string) models.ScenarioRun {
+	t.Helper()
+	resp := doJSON(router, "POST", "/api/scenarios/"+itoa(id)+"/run", body)
+	require.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+	var run models.ScenarioRun
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &run))
+	return run
+}
+
+func waitScenarioRun(t *testing.T, router *gin.Engine, scenarioID, runID uint) models.ScenarioRun {
+	t.Helper()
+	var run models.ScenarioRun
+	require.Eventually(t, func() bool {
+		resp := doJSON(router, "GET", fmt.Sprintf("/api/scenarios/%d/runs/%d", scenarioID, runID), "")
+		json.Unmarshal(resp.Body.Bytes(), &run)
+		return run.Status != models.ScenarioRunning
+	}, 5*time.Second, 20*time.Millisecond)
+	return run
+}
+
+func TestScenarioRunPassesVariablesBetweenSteps(t *testing.T) {
+	db := setupTestDB()
+	router := setupScenarioRouter(db)
+	server, login, read := createLoginPackets(t, db, startLoginDevice(t))
+	scenario := createScenario(t, router, loginScenario(server.ID, login.ID, read.ID))
+
+	run := runScenario(t, router, scenario.ID, "")
+	assert.Equal(t, server.ID, run.TCPServerID)
+	run = waitScenarioRun(t, router, scenario.ID, run.ID)
+	require.Equal(t, models.ScenarioPassed, run.Status, run.Error)
+	assert.Equal(t, "beef", run.Vars["token"])
+	assert.Equal(t, "03", run.Vars["count"])
+
+	// 읽기 구간(send, expect, extract, loop)을 세 번 반복하고 unexpected 단계는 건너뜀
+	var names []string
+	for _, result := range run.Log {
+		assert.Equal(t, models.StepPassed, result.Status, result.Message)
+		if result.Name != "" {
+			names = append(names, result.Name)
+		}
+	}
+	assert.Equal(t, []string{"login", "read", "read", "read", "done"}, names)
+	require.Len(t, run.Log, 3+4*3+2)
+	assert.Equal(t, "02beef", run.Log[3].Request)
+	assert.Equal(t, "8203", run.Log[12].Response)
+	assert.Equal(t, "3 → done", run.Log[len(run.Log)-2].Message)
+
+	resp := doJSON(router, "GET", "/api/scenarios/"+itoa(scenario.ID)+"/runs", "")
+	var runs []models.ScenarioRun
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &runs))
+	assert.Len(t, runs, 1)
+}
+
+func TestScenarioRunFailsOnAssertion(t *testing.T) {
+	db := setupTestDB()
+	router := setupScenarioRouter(db)
+	server, login, read := createLoginPackets(t, db, startLoginDevice(t))
+	req := loginScenario(server.ID, login.ID, read.ID)
+	req.Steps = req.Steps[3:]
+	scenario := createScenario(t, router, req)
+
+	// 로그인하지 않고 틀린 토큰으로 읽으면 장비가 0xEE로 응답하므로 expect에서 실패
+	run := runScenario(t, router, scenario.ID, `{"vars":{"token":"0000"}}`)
+	assert.Equal(t, "0000", run.Vars["token"])
+	assert.Equal(t, "03", run.Vars["expected"])
+	run = waitScenarioRun(t, router, scenario.ID, run.ID)
+	assert.Equal(t, models.ScenarioFailed, run.Status)
+	assert.Contains(t, run.Error, "2번 단계 실패: 검증 실패")
+	require.Len(t, run.Log, 2)
+	assert.Equal(t, "020000", run.Log[0].Request)
+	assert.Equal(t, models.StepFailed, run.Log[1].Status)
+	assert.Equal(t, "ee", run.Log[1].Response)
+}
+
+func TestScenarioExpectSkipsFramesForOtherTransactions(t *testing.T) {
+	db := setupTestDB()
+	router := setupScenarioRouter(db)
+
+	// 응답 앞에 다른 트랜잭션 ID의 프레임을 먼저 보내는 Modbus 장비
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		buf := make([]byte, 256)
+		for {
+			if _, err := conn.Read(buf); err != nil {
+				return
+			}
+			txID := binary.BigEndian.Uint16(buf[0:2])
+			conn.Write(utils.BuildMBAP(txID+100, buf[6], []byte{0x03, 0x02, 0x00, 0xFF}))
+			conn.Write(utils.BuildMBAP(txID, buf[6], []byte{0x03, 0x02, 0x00, 0x01}))
+		}
+	}()
+
+	server := models.TCPServer{Name: "plc", Host: "127.0.0.1", Port: ln.Addr().(*net.TCPAddr).Port}
+	require.NoError(t, db.Create(&server).Error)
+	read := models.TCPPacket{TCPServerID: server.ID, Name: "read", Kind: models.PacketKindModbus,
+		Modbus: models.ModbusParams{UnitID: 1, Function: 3, Address: 0, Quantity: 1}}
+	require.NoError(t, db.Create(&read).Error)
+	scenario := createScenario(t, router, models.ScenarioRequest{
+		Name:        "modbus",
+		TCPServerID: server.ID,
+		Steps: models.ScenarioSteps{
+			{Name: "read", Type: models.StepSend, PacketID: read.ID},
+			{Type: models.StepExpect, Assertions: []models.ScenarioCheck{{Offset: 3, Type: models.TypeUint8, Op: models.OpEq, Value: "1"}}},
+			{Type: models.StepSend, PacketID: read.ID},
+			{Type: models.StepExpect, Assertions: []models.ScenarioCheck{{Offset: 3, Type: models.TypeUint8, Op: models.OpEq, Value: "1"}}},
+		},
+	})
+
+	run := runScenario(t, router, scenario.ID, "")
+	run = waitScenarioRun(t, router, scenario.ID, run.ID)
+	require.Equal(t, models.ScenarioPassed, run.Status, run.Error)
+	require.Len(t, run.Log, 4)
+	assert.Equal(t, "03020001", run.Log[1].Response)
+	assert.Contains(t, run.Log[1].Message, "다른 프레임 1개 무시")
+	assert.Equal(t, "03020001", run.Log[3].Response)
+}
+
+func TestScenarioRunStop(t *testing.T) {
+	db := setupTestDB()
+	router := setupScenarioRouter(db)
+	server, login, _ := createLoginPackets(t, db, startLoginDevice(t))
+	scenario := createScenario(t, router, models.ScenarioRequest{
+		Name:        "slow",
+		TCPServerID: server.ID,
+		Steps: models.ScenarioSteps{
+			{Type: models.StepSend, PacketID: login.ID},
+			{Type: models.StepWait, DelayMs: 10000},
+		},
+	})
+
+	run := runScenario(t, router, scenario.ID, "")
+	assert.Equal(t, http.StatusConflict, doJSON(router, "DELETE", "/api/scenarios/"+itoa(scenario.ID), "").Code)
+	path := fmt.Sprintf("/api/scenarios/%d/runs/%d/stop", scenario.ID, run.ID)
+	assert.Equal(t, http.StatusOK, doJSON(router, "POST", path, "").Code)
+	run = waitScenarioRun(t, router, scenario.ID, run.ID)
+	assert.Equal(t, models.ScenarioStopped, run.Status)
+	assert.Equal(t, http.StatusConflict, doJSON(router, "POST", path, "").Code)
+
+	assert.Equal(t, http.StatusOK, doJSON(router, "DELETE", "/api/scenarios/"+itoa(scenario.ID), "").Code)
+	var count int64
+	db.Model(&models.ScenarioRun{}).Count(&count)
+	assert.Zero(t, count)
+}
+
+func TestCreateScenarioValidation(t *testing.T) {
+	db := setupTestDB()
+	router := setupScenarioRouter(db)
+	server, login, read := createLoginPackets(t, db, 1)
+	createScenario(t, router, loginScenario(server.ID, login.ID, read.ID))
+
+	cases := []string{
+		`{"name":"empty"}`,
+		`{"name":"bad type","steps":[{"type":"jump"}]}`,
+		`{"name":"no packet","steps":[{"type":"send","packet_id":999}]}`,
+		fmt.Sprintf(`{"name":"bad loop","steps":[{"type":"send","packet_id":%d},{"type":"loop","target":"later","count":2},{"name":"later","type":"wait","delay_ms":1}]}`, login.ID),
+		`{"name":"bad branch","steps":[{"name":"a","type":"wait","delay_ms":1},{"type":"branch","target":"a"}]}`,
+		`{"name":"bad op","steps":[{"type":"expect","assertions":[{"op":"like"}]}]}`,
+		`{"name":"bad vars","vars":{"x":"zz"},"steps":[{"type":"wait","delay_ms":1}]}`,
+		`{"name":"dup","steps":[{"name":"a","type":"wait","delay_ms":1},{"name":"a","type":"wait","delay_ms":1}]}`,
+	}
+	for _, body := range cases {
+		assert.Equal(t, http.StatusBadRequest, doJSON(router, "POST", "/api/scenarios", body).Code, body)
+	}
+	dup, _ := json.Marshal(loginScenario(server.ID, login.ID, read.ID))
+	assert.Equal(t, http.StatusConflict, doJSON(router, "POST", "/api/scenarios", string(dup)).Code)
+
+	noServer := createScenario(t, router, models.ScenarioRequest{Name: "no server", Steps: models.ScenarioSteps{{Type: models.StepWait, DelayMs: 1}}})
+	assert.Equal(t, http.StatusBadRequest, doJSON(router, "POST", "/api/scenarios/"+itoa(noServer.ID)+"/run", "").Code)
+	assert.Equal(t, http.StatusNotFound, doJSON(router, "POST", "/api/scenarios/"+itoa(noServer.ID)+"/run", `{"tcp_server_id":99}`).Code)
+	assert.Equal(t, http.StatusNotFound, doJSON(router, "POST", "/api/scenarios/99/run", "").Code)
+}
@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/fake-edge-server/models"
+	"github.com/fake-edge-server/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ScheduleHandler는 cron 예약 관리와 실행 기록 조회를 위한 핸들러 구조체입니다.
+type ScheduleHandler struct {
+	DB        *gorm.DB
+	Scheduler *services.Scheduler
+}
+
+// NewScheduleHandler는 새로운 ScheduleHandler 인스턴스를 생성합니다.
+func NewScheduleHandler(db *gorm.DB, scheduler *services.Scheduler) *ScheduleHandler {
+	return &ScheduleHandler{
+		DB:        db,
+		Scheduler: scheduler,
+	}
+}
+
+// applyScheduleRequest는 요청 값을 예약 모델에 반영합니다.
+func applyScheduleRequest(schedule *models.Schedule, req models.ScheduleRequest) {
+	schedule.Name = req.Name
+	schedule.Cron = req.Cron
+	schedule.Timezone = req.Timezone
+	schedule.Target = req.Target
+	schedule.PacketID = req.PacketID
+	schedule.ScenarioID = req.ScenarioID
+	schedule.TCPServerID = req.TCPServerID
+	schedule.AllServers = req.AllServers
+	schedule.Enabled = req.Enabled
+}
+
+// validateSchedule은 실행 시점 설정과 실행 대상, 서버가 존재하는지 검증합니다.
+func (h *ScheduleHandler) validateSchedule(schedule models.Schedule) error {
+	if err := schedule.Validate(); err != nil {
+		return err
+	}
+	if schedule.PacketID != 0 {
+		var packet models.TCPPacket
+		if err := h.DB.First(&packet, schedule.PacketID).Error; err != nil {
+			return errors.New("전송할 패킷을 찾을 수 없습니다")
+		}
+	}
+	if schedule.ScenarioID != 0 {
+		var scenario models.Scenario
+		if err := h.DB.First(&scenario, schedule.ScenarioID).Error; err != nil {
+			return errors.New("실행할 시나리오를 찾을 수 없습니다")
+		}
+		if !schedule.AllServers && schedule.TCPServerID == 0 && scenario.TCPServerID == 0 {
+			return errors.New("시나리오에 기본 서버가 없으면 tcp_server_id 또는 all_servers를 지정해주세요")
+		}
+	}
+	if schedule.TCPServerID != 0 {
+		var server models.TCPServer
+		if err := h.DB.First(&server, schedule.TCPServerID).Error; err != nil {
+			return errors.New("TCP 서버를 찾을 수 없습니다")
+		}
+	}
+	return nil
+}
+
+// getScheduleByID는 URL 파라미터에서 ID를 추출하여 예약을 조회합니다.
+func (h *ScheduleHandler) getScheduleByID(c *gin.Context) (*models.Schedule, bool) {
+	var schedule models.Schedule
+	if err := h.DB.First(&schedule, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "예약을 찾을 수 없습니다"})
+		return nil, false
+	}
+	return &schedule, true
+}
+
+// reload는 예약을 다시 시작하고 다음 실행 시각이 반영된 예약을 읽어옵니다.
+func (h *ScheduleHandler) reload(schedule *models.Schedule) {
+	h.Scheduler.Reload(schedule.ID)
+	var reloaded models.Schedule
+	if err := h.DB.First(&reloaded, schedule.ID).Error; err == nil {
+		*schedule = reloaded
+	}
+}
+
+// CreateSchedule은 새로운 예약을 생성하고, 활성화되어 있으면 다음 실행 시각을 잡습니다.
+func (h *ScheduleHandler) CreateSchedule(c *gin.Context) {
+	var req models.ScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "유효하지 않은 요청 형식: " + err.Error()})
+		return
+	}
+
+	var schedule models.Schedule
+	applyScheduleRequest(&schedule, req)
+	if err := h.validateSchedule(schedule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var existing models.Schedule
+	if h.DB.Where("name = ?", req.Name).First(&existing).RowsAffected > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "같은 이름의 예약이 이미 존재합니다"})
+		return
+	}
+
+	if err := h.DB.Create(&schedule).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "예약 생성 실패: " + err.Error()})
+		return
+	}
+	h.reload(&schedule)
+
+	c.JSON(http.StatusCreated, schedule)
+}
+
+// GetSchedules는 모든 예약 목록을 반환합니다.
+func (h *ScheduleHandler) GetSchedules(c *gin.Context) {
+	var schedules []models.Schedule
+	if err := h.DB.Find(&schedules).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedules)
+}
+
+// GetScheduleByID는 특정 예약 정보와 다음/마지막 실행 결과를 반환합니다.
+func (h *ScheduleHandler) GetScheduleByID(c *gin.Context) {
+	schedule, ok := h.getScheduleByID(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// UpdateSchedule은 예약 정보를 수정하고 새 설정으로 다시 시작합니다.
+func (h *ScheduleHandler) UpdateSchedule(c *gin.Context) {
+	schedule, ok := h.getScheduleByID(c)
+	if !ok {
+		return
+	}
+
+	var req models.ScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "유효하지 않은 요청 형식: " + err.Error()})
+		return
+	}
+
+	if req.Name != schedule.Name {
+		var existing models.Schedule
+		if h.DB.Where("name = ? AND id != ?", req.Name, schedule.ID).First(&existing).RowsAffected > 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "같은 이름의 예약이 이미 존재합니다"})
+			return
+		}
+	}
+
+	applyScheduleRequest(schedule, req)
+	if err := h.validateSchedule(*schedule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.DB.Save(schedule).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "예약 업데이트 실패: " + err.Error()})
+		return
+	}
+	h.reload(schedule)
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// DeleteSchedule은 예약을 중지하고 실행 기록과 함께 삭제합니다.
+func (h *ScheduleHandler) DeleteSchedule(c *gin.Context) {
+	schedule, ok := h.getScheduleByID(c)
+	if !ok {
+		return
+	}
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("schedule_id = ?", schedule.ID).Delete(&models.ScheduleRun{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(schedule).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "예약 삭제 실패: " + err.Error()})
+		return
+	}
+	h.Scheduler.Reload(schedule.ID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "예약이 성공적으로 삭제되었습니다"})
+}
+
+// RunSchedule은 예약 시각을 기다리지 않고 예약을 한 번 실행합니다. 결과는 실행 기록과 WebSocket으로 전달됩니다.
+func (h *ScheduleHandler) RunSchedule(c *gin.Context) {
+	schedule, ok := h.getScheduleByID(c)
+	if !ok {
+		return
+	}
+	h.Scheduler.Trigger(*schedule)
+
+	c.JSON(http.StatusOK, gin.H{"id": schedule.ID, "message": "예약 실행 요청됨"})
+}
+
+// GetScheduleRuns는 예약의 서버별 실행 기록을 최신 순으로 반환합니다.
+func (h *ScheduleHandler) GetScheduleRuns(c *gin.Context) {
+	schedule, ok := h.getScheduleByID(c)
+	if !ok {
+		return
+	}
+
+	var runs []models.ScheduleRun
+	if err := h.DB.Where("schedule_id = ?", schedule.ID).Order("id DESC").Find(&runs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, runs)
+}
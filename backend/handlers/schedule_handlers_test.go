@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/fake-edge-server/models"
+	"github.com/fake-edge-server/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupScheduleRouter(db *gorm.DB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	hub := services.NewWebSocketHub()
+	sender := services.NewPacketSender(db, services.NewTCPConnectionManager(), hub)
+	scenarios := services.NewScenarioRunner(db, hub)
+	handler := NewScheduleHandler(db, services.NewScheduler(db, hub, sender, scenarios))
+	sd := r.Group("/api/schedules")
+	{
+		sd.POST("", handler.CreateSchedule)
+		sd.GET("", handler.GetSchedules)
+		sd.GET("/:id", handler.GetScheduleByID)
+		sd.PUT("/:id", handler.UpdateSchedule)
+		sd.DELETE("/:id", handler.DeleteSchedule)
+		sd.POST("/:id/run", handler.RunSchedule)
+		sd.GET("/:id/runs", handler.GetScheduleRuns)
+	}
+	return r
+}
+
+func createSchedule(t *testing.T, router *gin.Engine, body string) models.Schedule {
+	t.Helper()
+	resp := doJSON(router, "POST", "/api/schedules", body)
+	require.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+	var schedule models.Schedule
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &schedule))
+	return schedule
+}
+
+// waitScheduleRuns는 예약 실행 기록이 n개 쌓이고 모두 끝날 때까지 기다립니다.
+func waitScheduleRuns(t *testing.T, router *gin.Engine, id uint, n int) []models.ScheduleRun {
+	t.Helper()
+	var runs []models.ScheduleRun
+	require.Eventually(t, func() bool {
+		resp := doJSON(router, "GET", "/api/schedules/"+itoa(id)+"/runs", "")
+		runs = nil
+		json.Unmarshal(resp.Body.Bytes(), &runs)
+		if len(runs) != n {
+			return false
+		}
+		for _, run := range runs {
+			if run.Status == models.ScheduleRunning {
+				return false
+			}
+		}
+		return true
+	}, 5*time.Second, 20*time.Millisecond)
+	return runs
+}
+
+func getSchedule(t *testing.T, router *gin.Engine, id uint) models.Schedule {
+	t.Helper()
+	resp := doJSON(router, "GET", "/api/schedules/"+itoa(id), "")
+	require.Equal(t, http.StatusOK, resp.Code)
+	var schedule models.Schedule
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &schedule))
+	return schedule
+}
+
+func TestSchedulePacketOnAllServers(t *testing.T) {
+	db := setupTestDB()
+	router := setupScheduleRouter(db)
+	server, login, _ := createLoginPackets(t, db, startLoginDevice(t))
+	other := models.TCPServer{Name: "device2", Host: "127.0.0.1", Port: startLoginDevice(t)}
+	require.NoError(t, db.Create(&other).Error)
+
+	schedule := createSchedule(t, router, `{"name":"status","cron":"*/15 * * * *","target":"packet","packet_id":`+itoa(login.ID)+`,"all_servers":true,"enabled":true}`)
+	require.NotNil(t, schedule.NextRunAt)
+	assert.True(t, schedule.NextRunAt.After(time.Now()))
+	assert.Zero(t, schedule.NextRunAt.Minute()%15)
+	assert.Nil(t, schedule.LastRunAt)
+
+	resp := doJSON(router, "POST", "/api/schedules/"+itoa(schedule.ID)+"/run", "")
+	require.Equal(t, http.StatusOK, resp.Code)
+	runs := waitScheduleRuns(t, router, schedule.ID, 2)
+	servers := []uint{}
+	for _, run := range runs {
+		assert.Equal(t, models.ScheduleSucceeded, run.Status, run.Error)
+		assert.True(t, run.Manual)
+		assert.NotZero(t, run.HistoryID)
+		servers = append(servers, run.TCPServerID)
+	}
+	assert.ElementsMatch(t, []uint{server.ID, other.ID}, servers)
+
+	require.Eventually(t, func() bool {
+		return getSchedule(t, router, schedule.ID).LastRunAt != nil
+	}, time.Second, 10*time.Millisecond)
+	schedule = getSchedule(t, router, schedule.ID)
+	assert.Equal(t, models.ScheduleSucceeded, schedule.LastStatus)
+	assert.Empty(t, schedule.LastError)
+
+	// 비활성화하면 다음 실행 시각이 비워짐
+	resp = doJSON(router, "PUT", "/api/schedules/"+itoa(schedule.ID), `{"name":"status","cron":"*/15 * * * *","target":"packet","packet_id":`+itoa(login.ID)+`,"all_servers":true}`)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &schedule))
+	assert.Nil(t, schedule.NextRunAt)
+
+	resp = doJSON(router, "DELETE", "/api/schedules/"+itoa(schedule.ID), "")
+	require.Equal(t, http.StatusOK, resp.Code)
+	var count int64
+	db.Model(&models.ScheduleRun{}).Count(&count)
+	assert.Zero(t, count)
+}
+
+func TestScheduleScenarioInTimezone(t *testing.T) {
+	db := setupTestDB()
+	router := setupScheduleRouter(db)
+	server, login, read := createLoginPackets(t, db, startLoginDevice(t))
+	scenarioRouter := setupScenarioRouter(db)
+	scenario := createScenario(t, scenarioRouter, loginScenario(server.ID, login.ID, read.ID))
+
+	schedule := createSchedule(t, router, `{"name":"health","cron":"0 2 * * 1-5","timezone":"Asia/Seoul","target":"scenario","scenario_id":`+itoa(scenario.ID)+`,"enabled":true}`)
+	require.NotNil(t, schedule.NextRunAt)
+	seoul, err := time.LoadLocation("Asia/Seoul")
+	require.NoError(t, err)
+	next := schedule.NextRunAt.In(seoul)
+	assert.Equal(t, 2, next.Hour())
+	assert.Zero(t, next.Minute())
+	assert.NotEqual(t, time.Saturday, next.Weekday())
+	assert.NotEqual(t, time.Sunday, next.Weekday())
+
+	doJSON(router, "POST", "/api/schedules/"+itoa(schedule.ID)+"/run", "")
+	runs := waitScheduleRuns(t, router, schedule.ID, 1)
+	require.Equal(t, models.ScheduleSucceeded, runs[0].Status, runs[0].Error)
+	assert.Equal(t, server.ID, runs[0].TCPServerID)
+	var run models.ScenarioRun
+	require.NoError(t, db.First(&run, runs[0].ScenarioRunID).Error)
+	assert.Equal(t, models.ScenarioPassed, run.Status)
+}
+
+func TestScheduleRecordsFailure(t *testing.T) {
+	db := setupTestDB()
+	router := setupScheduleRouter(db)
+	_, login, _ := createLoginPackets(t, db, startLoginDevice(t))
+	closed := models.TCPServer{Name: "closed", Host: "127.0.0.1", Port: 1}
+	require.NoError(t, db.Create(&closed).Error)
+
+	schedule := createSchedule(t, router, `{"name":"down","cron":"0 * * * *","target":"packet","packet_id":`+itoa(login.ID)+`,"tcp_server_id":`+itoa(closed.ID)+`}`)
+	assert.Nil(t, schedule.NextRunAt)
+
+	doJSON(router, "POST", "/api/schedules/"+itoa(schedule.ID)+"/run", "")
+	runs := waitScheduleRuns(t, router, schedule.ID, 1)
+	assert.Equal(t, models.ScheduleFailed, runs[0].Status)
+	assert.Equal(t, closed.ID, runs[0].TCPServerID)
+	assert.NotEmpty(t, runs[0].Error)
+
+	require.Eventually(t, func() bool {
+		return getSchedule(t, router, schedule.ID).LastStatus == models.ScheduleFailed
+	}, time.Second, 10*time.Millisecond)
+	assert.Contains(t, getSchedule(t, router, schedule.ID).LastError, "서버["+itoa(closed.ID)+"]")
+}
+
+func TestScheduleValidation(t *testing.T) {
+	db := setupTestDB()
+	router := setupScheduleRouter(db)
+	server, login, _ := createLoginPackets(t, db, startLoginDevice(t))
+	packet := itoa(login.ID)
+
+	cases := map[string]string{
+		"잘못된 cron":  `{"name":"a","cron":"61 * * * *","target":"packet","packet_id":` + packet + `}`,
+		"잘못된 시간대":   `{"name":"a","cron":"0 * * * *","timezone":"Mars/Base","target":"packet","packet_id":` + packet + `}`,
+		"알 수 없는 대상": `{"name":"a","cron":"0 * * * *","target":"mock","packet_id":` + packet + `}`,
+		"없는 패킷":     `{"name":"a","cron":"0 * * * *","target":"packet","packet_id":999}`,
+		"없는 시나리오":   `{"name":"a","cron":"0 * * * *","target":"scenario","scenario_id":999}`,
+		"없는 서버":     `{"name":"a","cron":"0 * * * *","target":"packet","packet_id":` + packet + `,"tcp_server_id":999}`,
+		"서버 지정 중복":  `{"name":"a","cron":"0 * * * *","target":"packet","packet_id":` + packet + `,"tcp_server_id":` + itoa(server.ID) + `,"all_servers":true}`,
+		"대상 ID 혼용":  `{"name":"a","cron":"0 * * * *","target":"packet","packet_id":` + packet + `,"scenario_id":1}`,
+		"cron 누락":   `{"name":"a","target":"packet","packet_id":` + packet + `}`,
+	}
+	for name, body := range cases {
+		resp := doJSON(router, "POST", "/api/schedules", body)
+		assert.Equal(t, http.StatusBadRequest, resp.Code, name)
+	}
+
+	createSchedule(t, router, `{"name":"a","cron":"0 * * * *","target":"packet","packet_id":`+packet+`}`)
+	resp := doJSON(router, "POST", "/api/schedules", `{"name":"a","cron":"0 * * * *","target":"packet","packet_id":`+packet+`}`)
+	assert.Equal(t, http.StatusConflict, resp.Code)
+
+	resp = doJSON(router, "POST", "/api/schedules/999/run", "")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
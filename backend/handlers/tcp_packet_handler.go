@@ -1,15 +1,12 @@
 package handlers
 
 import (
-	"bytes"
-	"encoding/binary"
-	"encoding/hex"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"sort"
 	"strconv"
-	"time"
 
 	"github.com/fake-edge-server/models"
 	"github.com/fake-edge-server/services"
@@ -53,11 +50,21 @@ func (h *TCPPacketHandler) CreateTCPPacket(c *gin.Context) {
 	}
 	packet.TCPServerID = uint(servIDInt)
 
+	if err := packet.ValidateKind(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	if err := validatePacketData(packet.Data); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	if err := services.ValidateScripts(packet); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	result := h.DB.Create(&packet)
 	if result.Error != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "패킷 생성 실패: " + result.Error.Error()})
@@ -112,10 +119,18 @@ func (h *TCPPacketHandler) ImportTCPPackets(c *gin.Context) {
 	for i := range packets {
 		packets[i].ID = 0
 		packets[i].TCPServerID = uint(sid)
+		if err := packets[i].ValidateKind(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		if err := validatePacketData(packets[i].Data); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
+		if err := services.ValidateScripts(packets[i]); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		if err := h.DB.Create(&packets[i]).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "패킷 생성 실패: " + err.Error()})
 			return
@@ -162,9 +177,27 @@ func (h *TCPPacketHandler) UpdateTCPPacketInfo(c *gin.Context) {
 		return
 	}
 
+	if err := updatedPacket.ValidateKind(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := services.ValidateScripts(updatedPacket); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	packet.Name = updatedPacket.Name
 	packet.Desc = updatedPacket.Desc
 	packet.UseCRC = updatedPacket.UseCRC
+	packet.Kind = updatedPacket.Kind
+	packet.NodeType = updatedPacket.NodeType
+	packet.CommandType = updatedPacket.CommandType
+	packet.EdgeID = updatedPacket.EdgeID
+	packet.Modbus = updatedPacket.Modbus
+	packet.PreSendScript = updatedPacket.PreSendScript
+	packet.PostReceiveScript = updatedPacket.PostReceiveScript
+	packet.ScriptTimeoutMs = updatedPacket.ScriptTimeoutMs
 
 	if err := h.DB.Save(&packet).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "패킷 업데이트 실패: " + err.Error()})
@@ -244,6 +277,7 @@ func (h *TCPPacketHandler) GetTCPPacketHistory(c *gin.Context) {
 }
 
 // SendTCPPacket은 TCP 패킷을 지정된 서버로 전송합니다.
+// interval_ms를 지정하면 count, duration_ms, jitter_pct, burst에 따라 반복 전송 작업을 시작합니다.
 func (h *TCPPacketHandler) SendTCPPacket(c *gin.Context) {
 	packetID := c.Param("packet_id")
 	serverID := c.Param("id")
@@ -264,16 +298,25 @@ func (h *TCPPacketHandler) SendTCPPacket(c *gin.Context) {
 		return
 	}
 
-	var body struct {
-		IntervalMs int `json:"interval_ms"`
-	}
+	var spec models.SendJobSpec
 	if c.ContentType() == "application/json" {
-		_ = c.ShouldBindJSON(&body)
+		if err := c.ShouldBindJSON(&spec); err != nil && !errors.Is(err, io.EOF) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "유효하지 않은 요청 형식: " + err.Error()})
+			return
+		}
+	}
+	if err := spec.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
-	if body.IntervalMs > 0 {
-		h.Sender.Start(server, packet, time.Duration(body.IntervalMs)*time.Millisecond)
-		c.JSON(http.StatusOK, gin.H{"message": "started"})
+	if spec.Repeat() {
+		job, err := h.Sender.Start(server, packet, spec)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "반복 전송 시작 실패: " + err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "started", "job": job})
 		return
 	}
 
@@ -286,7 +329,41 @@ func (h *TCPPacketHandler) SendTCPPacket(c *gin.Context) {
 	c.JSON(http.StatusOK, history)
 }
 
+// SendModbus는 저장된 패킷 없이 Modbus 작업을 한 번 수행하고 이력을 반환합니다.
+func (h *TCPPacketHandler) SendModbus(c *gin.Context) {
+	var server models.TCPServer
+	if err := h.DB.First(&server, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "서버를 찾을 수 없습니다"})
+		return
+	}
+
+	var params models.ModbusParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "잘못된 요청 형식: " + err.Error()})
+		return
+	}
+
+	packet := models.TCPPacket{
+		TCPServerID: server.ID,
+		Name:        fmt.Sprintf("modbus-0x%02X", params.Function),
+		Kind:        models.PacketKindModbus,
+		Modbus:      params,
+	}
+	if err := packet.ValidateKind(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	history, err := h.Sender.SendOnce(server, packet)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, history)
+}
+
 // StopTCPPacketSend stops the background sending job for a packet.
+// It responds 404 when the packet is not being sent.
 func (h *TCPPacketHandler) StopTCPPacketSend(c *gin.Context) {
 	packetID := c.Param("packet_id")
 	serverID := c.Param("id")
@@ -302,8 +379,16 @@ func (h *TCPPacketHandler) StopTCPPacketSend(c *gin.Context) {
 		return
 	}
 
-	h.Sender.Stop(uint(sid), uint(pid))
-	c.JSON(http.StatusOK, gin.H{"message": "stopped"})
+	job, err := h.Sender.Stop(uint(sid), uint(pid))
+	if errors.Is(err, services.ErrJobNotRunning) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "stopped", "job": job})
 }
 
 // validatePacketData는 체인된 데이터의 길이가 타입 크기와 일치하는지 검증합니다.
@@ -354,77 +439,5 @@ func packetDataToBytes(data models.PacketData) []byte {
 
 // ParseChainedValues는 연결된 값을 타입에 따라 파싱합니다.
 func ParseChainedValues(dataType models.DataType, values []byte) (string, error) {
-	buf := bytes.NewBuffer(values)
-
-	switch dataType {
-	case models.TypeInt8:
-		var v int8
-		err := binary.Read(buf, binary.LittleEndian, &v)
-		return strconv.Itoa(int(v)), err
-
-	case models.TypeInt16:
-		var v int16
-		err := binary.Read(buf, binary.LittleEndian, &v)
-		return strconv.Itoa(int(v)), err
-
-	case models.TypeInt32:
-		var v int32
-		err := binary.Read(buf, binary.LittleEndian, &v)
-		return strconv.Itoa(int(v)), err
-
-	case models.TypeInt64:
-		var v int64
-		err := binary.Read(buf, binary.LittleEndian, &v)
-		return strconv.FormatInt(v, 10), err
-
-	case models.TypeUint8:
-		var v uint8
-		err := binary.Read(buf, binary.LittleEndian, &v)
-		return strconv.FormatUint(uint64(v), 10), err
-
-	case models.TypeUint16:
-		var v uint16
-		err := binary.Read(buf, binary.LittleEndian, &v)
-		return strconv.FormatUint(uint64(v), 10), err
-
-	case models.TypeUint32:
-		var v uint32
-		err := binary.Read(buf, binary.LittleEndian, &v)
-		return strconv.FormatUint(uint64(v), 10), err
-
-	case models.TypeUint64:
-		var v uint64
-		err := binary.Read(buf, binary.LittleEndian, &v)
-		return strconv.FormatUint(v, 10), err
-
-	case models.TypeFloat32:
-		var v float32
-		err := binary.Read(buf, binary.LittleEndian, &v)
-		return fmt.Sprintf("%f", v), err
-
-	case models.TypeFloat64:
-		var v float64
-		err := binary.Read(buf, binary.LittleEndian, &v)
-		return fmt.Sprintf("%f", v), err
-
-	case models.TypeString:
-		return string(values), nil
-
-	case models.TypeHex:
-		return hex.EncodeToString(values), nil
-
-	case models.TypeJSON:
-		var v interface{}
-		if err := json.Unmarshal(values, &v); err != nil {
-			return "", fmt.Errorf("Json으로 호환되는 응답이 아닙니다.")
-		}
-		parsed, err := json.MarshalIndent(v, "", "  ")
-		if err != nil {
-			return "", err
-		}
-		return string(parsed), nil
-
-	default:
-		return "", fmt.Errorf("지원되지 않는 데이터 타입: %d", dataType)
-	}
+	return dataType.Decode(values)
 }
@@ -1,17 +1,27 @@
 package handlers
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/fake-edge-server/models"
 	"github.com/fake-edge-server/services"
+	"github.com/fake-edge-server/utils"
 	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
 )
 
@@ -25,6 +35,7 @@ func setupPacketRouter(db *gorm.DB, connManager *services.TCPConnectionManager)
 	{
 		tc.POST("/:id/packets/:packet_id/send", handler.SendTCPPacket)
 		tc.GET("/:id/history", handler.GetTCPPacketHistory)
+		tc.POST("/:id/modbus", handler.SendModbus)
 	}
 	return r
 }
@@ -105,6 +116,66 @@ func TestSendTCPPacketStoresHistoryWithCRC(t *testing.T) {
 	assert.Equal(t, int64(1), count)
 }
 
+func TestSendTCPPacketReportsUnsolicitedFrames(t *testing.T) {
+	db := setupTestDB()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	hub := services.NewWebSocketHub()
+	connManager := services.NewTCPConnectionManager()
+	handler := NewTCPPacketHandler(db, connManager, hub, services.NewPacketSender(db, connManager, hub))
+	router.POST("/api/tcp/:id/packets/:packet_id/send", handler.SendTCPPacket)
+	router.GET("/api/ws", NewWSHandler(hub).Handle)
+
+	// 장비는 응답 프레임 바로 뒤에 푸시 프레임을 붙여 보낸다
+	push := utils.BuildPacket([]byte{0xEE})
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		scanner := bufio.NewScanner(conn)
+		scanner.Split(utils.SplitPacket)
+		for scanner.Scan() {
+			conn.Write(append(append([]byte(nil), scanner.Bytes()...), push...))
+		}
+	}()
+
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/api/ws", nil)
+	require.NoError(t, err)
+	defer ws.Close()
+
+	server := models.TCPServer{Name: "push", Host: "127.0.0.1", Port: ln.Addr().(*net.TCPAddr).Port}
+	db.Create(&server)
+	packet := models.TCPPacket{TCPServerID: server.ID, UseCRC: true, Data: models.PacketData{{Offset: 0, Value: 1, Type: models.TypeUint8}}}
+	db.Create(&packet)
+
+	// 앞선 교환 뒤에 남은 프레임은 버리지 않고 unsolicited로 방송한 뒤 응답을 읽는다
+	for i := 0; i < 2; i++ {
+		resp := doJSON(router, "POST", fmt.Sprintf("/api/tcp/%d/packets/%d/send", server.ID, packet.ID), "")
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		var history models.TCPPacketHistory
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &history))
+		assert.Equal(t, "01", history.Response)
+	}
+	var msg struct {
+		Type     string `json:"type"`
+		ServerID uint   `json:"server_id"`
+		Payload  string `json:"payload"`
+	}
+	for msg.Type != "unsolicited" {
+		ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+		require.NoError(t, ws.ReadJSON(&msg))
+	}
+	assert.Equal(t, server.ID, msg.ServerID)
+	assert.Equal(t, hex.EncodeToString(push), msg.Payload)
+}
+
 func TestGetTCPPacketHistory(t *testing.T) {
 	db := setupTestDB()
 	connManager := services.NewTCPConnectionManager()
@@ -128,3 +199,348 @@ func TestGetTCPPacketHistory(t *testing.T) {
 	assert.Len(t, histories, 1)
 	assert.Equal(t, "01", histories[0].Request)
 }
+
+func TestSendTCPPacketUsesServerFraming(t *testing.T) {
+	db := setupTestDB()
+	connManager := services.NewTCPConnectionManager()
+	router := setupPacketRouter(db, connManager)
+
+	profile := models.FrameProfile{Magic: "eb90", Endian: "big", LengthSize: 2, Checksum: "crc32c"}
+	format, err := profile.Format()
+	assert.NoError(t, err)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer ln.Close()
+	received := make(chan []byte, 1)
+	go func() {
+		conn, _ := ln.Accept()
+		buf := make([]byte, 1024)
+		n, _ := conn.Read(buf)
+		payload, _ := format.Unpack(buf[:n])
+		received <- payload
+		frame, _ := format.Build([]byte{0x02})
+		conn.Write(frame)
+		conn.Close()
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+	server := models.TCPServer{Name: "framed", Host: "127.0.0.1", Port: addr.Port, Framing: profile}
+	db.Create(&server)
+	packet := models.TCPPacket{TCPServerID: server.ID, UseCRC: true, Data: models.PacketData{{Offset: 0, Value: 1, Type: models.TypeUint8}}}
+	db.Create(&packet)
+
+	req, _ := http.NewRequest("POST", fmt.Sprintf("/api/tcp/%d/packets/%d/send", server.ID, packet.ID), nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, []byte{0x01}, <-received)
+
+	var history models.TCPPacketHistory
+	err = json.Unmarshal(resp.Body.Bytes(), &history)
+	assert.NoError(t, err)
+	assert.Equal(t, "02", history.Response)
+}
+
+func TestSendEdgePacketCorrelatesMsgID(t *testing.T) {
+	db := setupTestDB()
+	connManager := services.NewTCPConnectionManager()
+	router := setupPacketRouter(db, connManager)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		conn, _ := ln.Accept()
+		buf := make([]byte, 1024)
+		n, _ := conn.Read(buf)
+		_, _, payload, _ := utils.UnpackPacketWithType(buf[:n])
+		edgeID, msgID, _, _ := utils.ParseEdgePayload(payload)
+		// 다른 메시지 ID의 프레임을 먼저 보낸 뒤 요청에 대한 응답을 보낸다
+		conn.Write(utils.BuildPacketWithType(1, 9, utils.BuildEdgePayload(edgeID, msgID+100, []byte{0xEE})))
+		conn.Write(utils.BuildPacketWithType(2, 8, utils.BuildEdgePayload(edgeID, msgID, []byte{0xAA, 0xBB})))
+		conn.Close()
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+	server := models.TCPServer{Name: "edge", Host: "127.0.0.1", Port: addr.Port}
+	db.Create(&server)
+	packet := models.TCPPacket{
+		TCPServerID: server.ID,
+		Kind:        models.PacketKindEdge,
+		NodeType:    1,
+		CommandType: 4,
+		EdgeID:      7,
+		Data:        models.PacketData{{Offset: 0, Value: 1, Type: models.TypeUint8}},
+	}
+	db.Create(&packet)
+
+	req, _ := http.NewRequest("POST", fmt.Sprintf("/api/tcp/%d/packets/%d/send", server.ID, packet.ID), nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var history models.TCPPacketHistory
+	err = json.Unmarshal(resp.Body.Bytes(), &history)
+	assert.NoError(t, err)
+	assert.Equal(t, models.PacketKindEdge, history.Kind)
+	assert.Equal(t, uint64(1), history.MsgID)
+	assert.Equal(t, uint8(2), history.NodeType)
+	assert.Equal(t, uint8(8), history.CommandType)
+	assert.Equal(t, "aabb", history.Response)
+}
+
+func TestSendModbusDecodesRegistersAndExceptions(t *testing.T) {
+	db := setupTestDB()
+	connManager := services.NewTCPConnectionManager()
+	router := setupPacketRouter(db, connManager)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		conn, _ := ln.Accept()
+		defer conn.Close()
+		buf := make([]byte, 1024)
+		for {
+			if _, err := conn.Read(buf); err != nil {
+				return
+			}
+			txID := binary.BigEndian.Uint16(buf[0:2])
+			switch binary.BigEndian.Uint16(buf[8:10]) {
+			case 0:
+				conn.Write(utils.BuildMBAP(txID, buf[6], []byte{0x03, 0x04, 0x00, 0x0A, 0x00, 0x0B}))
+			case 600: // 다른 함수 코드로 응답
+				conn.Write(utils.BuildMBAP(txID, buf[6], []byte{0x04, 0x04, 0x00, 0x0A, 0x00, 0x0B}))
+			case 700: // 다른 유닛 ID로 응답
+				conn.Write(utils.BuildMBAP(txID, buf[6]+1, []byte{0x03, 0x04, 0x00, 0x0A, 0x00, 0x0B}))
+			default:
+				conn.Write(utils.BuildMBAP(txID, buf[6], []byte{0x83, 0x02}))
+			}
+		}
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+	server := models.TCPServer{Name: "plc", Host: "127.0.0.1", Port: addr.Port}
+	db.Create(&server)
+
+	send := func(body string) models.TCPPacketHistory {
+		req, _ := http.NewRequest("POST", fmt.Sprintf("/api/tcp/%d/modbus", server.ID), bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		var history models.TCPPacketHistory
+		assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &history))
+		return history
+	}
+
+	history := send(`{"unit_id":1,"function":3,"address":0,"quantity":2}`)
+	assert.Equal(t, models.PacketKindModbus, history.Kind)
+	assert.Equal(t, uint64(1), history.MsgID)
+	var decoded utils.ModbusResponse
+	assert.NoError(t, json.Unmarshal([]byte(history.Decoded), &decoded))
+	assert.Equal(t, []uint16{10, 11}, decoded.Values)
+
+	history = send(`{"unit_id":1,"function":3,"address":500,"quantity":2}`)
+	assert.Equal(t, uint64(2), history.MsgID)
+	decoded = utils.ModbusResponse{}
+	assert.NoError(t, json.Unmarshal([]byte(history.Decoded), &decoded))
+	assert.Equal(t, byte(0x02), decoded.Exception)
+
+	req, _ := http.NewRequest("POST", fmt.Sprintf("/api/tcp/%d/modbus", server.ID), bytes.NewBufferString(`{"function":3,"quantity":0}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	// 트랜잭션 ID만 같고 유닛 ID나 함수 코드가 다른 응답은 거부
+	for _, address := range []int{600, 700} {
+		resp = doJSON(router, "POST", fmt.Sprintf("/api/tcp/%d/modbus", server.ID), fmt.Sprintf(`{"unit_id":1,"function":3,"address":%d,"quantity":2}`, address))
+		assert.NotEqual(t, http.StatusOK, resp.Code)
+		assert.Contains(t, resp.Body.String(), "Modbus 응답 불일치")
+	}
+}
+
+func TestSendTCPPacketOverUDP(t *testing.T) {
+	db := setupTestDB()
+	connManager := services.NewTCPConnectionManager()
+	router := setupPacketRouter(db, connManager)
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer pc.Close()
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, addr, err := pc.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			// 0xFF는 응답하지 않고 0xFE는 응답 대기 시간이 지난 뒤 응답하며 0xFD는 데이터그램 두 개로 응답한다
+			switch buf[0] {
+			case 0xFF:
+			case 0xFD:
+				pc.WriteTo(buf[:n], addr)
+				pc.WriteTo([]byte{0xFC}, addr)
+			case 0xFE:
+				reply := append([]byte(nil), buf[:n]...)
+				time.AfterFunc(200*time.Millisecond, func() { pc.WriteTo(reply, addr) })
+			default:
+				pc.WriteTo(buf[:n], addr)
+			}
+		}
+	}()
+
+	port := pc.LocalAddr().(*net.UDPAddr).Port
+	server := models.TCPServer{Name: "udp", Host: "127.0.0.1", Port: port, Transport: models.TransportUDP, WindowMs: 100}
+	db.Create(&server)
+	echo := models.TCPPacket{TCPServerID: server.ID, Name: "echo", Data: models.PacketData{{Offset: 0, Value: 1, Type: models.TypeUint8}}}
+	db.Create(&echo)
+	silent := models.TCPPacket{TCPServerID: server.ID, Name: "silent", Data: models.PacketData{{Offset: 0, Value: 0xFF, Type: models.TypeUint8}}}
+	db.Create(&silent)
+
+	send := func(packetID uint) models.TCPPacketHistory {
+		req, _ := http.NewRequest("POST", fmt.Sprintf("/api/tcp/%d/packets/%d/send", server.ID, packetID), nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		var history models.TCPPacketHistory
+		assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &history))
+		return history
+	}
+
+	history := send(echo.ID)
+	assert.Equal(t, "01", history.Response)
+	assert.Equal(t, "Alive", connManager.GetStatus(server.ID))
+	info, ok := connManager.GetInfo(server.ID)
+	assert.True(t, ok)
+	assert.Equal(t, models.TransportUDP, info.Transport)
+	assert.NotNil(t, info.LastResponseAt)
+
+	// 응답이 없어도 전송한 데이터그램은 이력에 남는다
+	history = send(silent.ID)
+	assert.Equal(t, "ff", history.Request)
+	assert.Empty(t, history.Response)
+
+	// 늦게 도착한 응답은 다음 전송의 응답이 되지 않고 따로 이력에 남는다
+	slow := models.TCPPacket{TCPServerID: server.ID, Name: "slow", Data: models.PacketData{{Offset: 0, Value: 0xFE, Type: models.TypeUint8}}}
+	db.Create(&slow)
+	history = send(slow.ID)
+	assert.Empty(t, history.Response)
+	time.Sleep(250 * time.Millisecond)
+	history = send(echo.ID)
+	assert.Equal(t, "01", history.Response)
+
+	// 응답 뒤에 온 데이터그램도 하나씩 이력에 남는다
+	double := models.TCPPacket{TCPServerID: server.ID, Name: "double", Data: models.PacketData{{Offset: 0, Value: 0xFD, Type: models.TypeUint8}}}
+	db.Create(&double)
+	history = send(double.ID)
+	assert.Equal(t, "fd", history.Response)
+	time.Sleep(50 * time.Millisecond)
+	send(echo.ID)
+
+	var unsolicited []models.TCPPacketHistory
+	db.Where("direction = ?", models.DirectionUnsolicited).Order("id").Find(&unsolicited)
+	require.Len(t, unsolicited, 2)
+	assert.Equal(t, "fe", unsolicited[0].Response)
+	assert.Equal(t, "fc", unsolicited[1].Response)
+	assert.Equal(t, server.ID, unsolicited[1].TCPServerID)
+
+	var count int64
+	db.Model(&models.TCPPacketHistory{}).Count(&count)
+	assert.Equal(t, int64(8), count)
+}
+
+func TestSendTCPPacketOverUnixSocket(t *testing.T) {
+	db := setupTestDB()
+	connManager := services.NewTCPConnectionManager()
+	router := setupPacketRouter(db, connManager)
+
+	path := filepath.Join(t.TempDir(), "sim.sock")
+	ln, err := net.Listen("unix", path)
+	assert.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		conn, _ := ln.Accept()
+		buf := make([]byte, 1024)
+		n, _ := conn.Read(buf)
+		conn.Write(buf[:n])
+	}()
+
+	server := models.TCPServer{Name: "sim", Transport: models.TransportUnix, Socket: path}
+	db.Create(&server)
+	packet := models.TCPPacket{TCPServerID: server.ID, UseCRC: true, Data: models.PacketData{{Offset: 0, Value: 7, Type: models.TypeUint8}}}
+	db.Create(&packet)
+
+	req, _ := http.NewRequest("POST", fmt.Sprintf("/api/tcp/%d/packets/%d/send", server.ID, packet.ID), nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var history models.TCPPacketHistory
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &history))
+	assert.Equal(t, "07", history.Response)
+	assert.Equal(t, "Alive", connManager.GetStatus(server.ID))
+}
+
+func TestSendTCPPacketRunsScripts(t *testing.T) {
+	db := setupTestDB()
+	connManager := services.NewTCPConnectionManager()
+	router := setupPacketRouter(db, connManager)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		conn, _ := ln.Accept()
+		buf := make([]byte, 1024)
+		n, _ := conn.Read(buf)
+		conn.Write(buf[:n])
+		conn.Close()
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+	server := models.TCPServer{Name: "scripted", Host: "127.0.0.1", Port: addr.Port}
+	db.Create(&server)
+	packet := models.TCPPacket{
+		TCPServerID: server.ID,
+		Name:        "scripted",
+		Data:        models.PacketData{{Offset: 0, Value: 1, Type: models.TypeUint8}, {Offset: 1, Value: 2, Type: models.TypeUint8}},
+		// 독자적인 체크섬(바이트 합)을 붙여 보내고, 응답의 체크섬을 검증
+		PreSendScript: "def pre_send(data):\n    return data + [sum(data) & 0xff]\n",
+		PostReceiveScript: `
+def post_receive(request, response):
+    print("checked", len(response), "bytes")
+    ok = sum(response[:-1]) & 0xff == response[-1]
+    return {"verdict": ok, "message": "checksum ok" if ok else "bad checksum", "decoded": {"sum": response[-1]}}
+`,
+	}
+	db.Create(&packet)
+
+	req, _ := http.NewRequest("POST", fmt.Sprintf("/api/tcp/%d/packets/%d/send", server.ID, packet.ID), nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+	var history models.TCPPacketHistory
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &history))
+	assert.Equal(t, "010203", history.Request)
+	assert.Equal(t, "010203", history.Response)
+	assert.Equal(t, models.VerdictPass, history.Verdict)
+	assert.Equal(t, "checked 3 bytes\nchecksum ok\n", history.ScriptLog)
+	assert.JSONEq(t, `{"sum":3}`, history.Decoded)
+
+	// 전송 전 스크립트가 실패하면 보내지 않고 이력도 남기지 않음
+	packet.PreSendScript = "def pre_send(data):\n    return data[5]\n"
+	db.Save(&packet)
+	req, _ = http.NewRequest("POST", fmt.Sprintf("/api/tcp/%d/packets/%d/send", server.ID, packet.ID), nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.Contains(t, resp.Body.String(), "전송 전 스크립트 실패")
+
+	var count int64
+	db.Model(&models.TCPPacketHistory{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
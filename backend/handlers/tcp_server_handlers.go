@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"net"
 	"net/http"
 
 	"github.com/fake-edge-server/models"
@@ -34,13 +33,8 @@ func (h *TCPServerHandler) CreateTCPServer(c *gin.Context) {
 		return
 	}
 
-	if net.ParseIP(req.Host) == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "유효한 IP 주소를 입력해주세요"})
-		return
-	}
-
-	if req.Port < 1 || req.Port > 65535 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "유효한 포트 번호를 입력해주세요 (1-65535)"})
+	if err := validateServerRequest(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
@@ -53,11 +47,8 @@ func (h *TCPServerHandler) CreateTCPServer(c *gin.Context) {
 	}
 
 	// 새 TCP 서버 생성
-	tcpServer := models.TCPServer{
-		Name: req.Name,
-		Host: req.Host,
-		Port: req.Port,
-	}
+	var tcpServer models.TCPServer
+	applyServerRequest(&tcpServer, req)
 
 	result = h.DB.Create(&tcpServer)
 	if result.Error != nil {
@@ -65,6 +56,7 @@ func (h *TCPServerHandler) CreateTCPServer(c *gin.Context) {
 		return
 	}
 
+	redactServer(&tcpServer)
 	c.JSON(http.StatusCreated, tcpServer)
 }
 
@@ -77,6 +69,9 @@ func (h *TCPServerHandler) GetTCPServers(c *gin.Context) {
 		return
 	}
 
+	for i := range servers {
+		redactServer(&servers[i])
+	}
 	c.JSON(http.StatusOK, servers)
 }
 
@@ -90,6 +85,7 @@ func (h *TCPServerHandler) GetTCPServerByID(c *gin.Context) {
 		return
 	}
 
+	redactServer(&server)
 	c.JSON(http.StatusOK, server)
 }
 
@@ -111,13 +107,9 @@ func (h *TCPServerHandler) UpdateTCPServer(c *gin.Context) {
 		return
 	}
 
-	if net.ParseIP(req.Host) == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "유효한 IP 주소를 입력해주세요"})
-		return
-	}
-
-	if req.Port < 1 || req.Port > 65535 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "유효한 포트 번호를 입력해주세요 (1-65535)"})
+	keepServerSecrets(&req, server)
+	if err := validateServerRequest(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
@@ -132,9 +124,7 @@ func (h *TCPServerHandler) UpdateTCPServer(c *gin.Context) {
 	}
 
 	// 업데이트
-	server.Name = req.Name
-	server.Host = req.Host
-	server.Port = req.Port
+	applyServerRequest(&server, req)
 
 	result = h.DB.Save(&server)
 	if result.Error != nil {
@@ -142,6 +132,7 @@ func (h *TCPServerHandler) UpdateTCPServer(c *gin.Context) {
 		return
 	}
 
+	redactServer(&server)
 	c.JSON(http.StatusOK, server)
 }
 
@@ -2,13 +2,24 @@ package handlers
 
 import (
 	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/fake-edge-server/models"
 	"github.com/fake-edge-server/services"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
 )
 
@@ -18,6 +29,9 @@ func setupTCPServerRouter(db *gorm.DB, mgr *services.TCPConnectionManager) *gin.
 	hub := services.NewWebSocketHub()
 	handler := NewTCPServerHandler(db, mgr, hub)
 	router.POST("/tcp", handler.CreateTCPServer)
+	router.GET("/tcp", handler.GetTCPServers)
+	router.GET("/tcp/:id", handler.GetTCPServerByID)
+	router.PUT("/tcp/:id", handler.UpdateTCPServer)
 	return router
 }
 
@@ -25,7 +39,7 @@ func TestCreateTCPServerInvalidHost(t *testing.T) {
 	db := setupTestDB()
 	router := setupTCPServerRouter(db, services.NewTCPConnectionManager())
 
-	body := `{"name":"s","host":"invalid","port":1234}`
+	body := `{"name":"s","host":"bad host!","port":1234}`
 	req, _ := http.NewRequest("POST", "/tcp", bytes.NewBufferString(body))
 	req.Header.Set("Content-Type", "application/json")
 	resp := httptest.NewRecorder()
@@ -46,3 +60,193 @@ func TestCreateTCPServerInvalidPort(t *testing.T) {
 
 	assert.Equal(t, http.StatusBadRequest, resp.Code)
 }
+
+func TestCreateTCPServerInvalidFraming(t *testing.T) {
+	db := setupTestDB()
+	router := setupTCPServerRouter(db, services.NewTCPConnectionManager())
+
+	body := `{"name":"s","host":"127.0.0.1","port":1234,"framing":{"checksum":"md5"}}`
+	req, _ := http.NewRequest("POST", "/tcp", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestCreateTCPServerRejectsTLSOverUDP(t *testing.T) {
+	db := setupTestDB()
+	router := setupTCPServerRouter(db, services.NewTCPConnectionManager())
+
+	body := `{"name":"s","host":"127.0.0.1","port":1234,"transport":"udp","tls":{"enabled":true}}`
+	req, _ := http.NewRequest("POST", "/tcp", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestCreateTCPServerUnixSocket(t *testing.T) {
+	db := setupTestDB()
+	router := setupTCPServerRouter(db, services.NewTCPConnectionManager())
+
+	body := `{"name":"sim","transport":"unix","socket_path":"/tmp/sim.sock"}`
+	req, _ := http.NewRequest("POST", "/tcp", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusCreated, resp.Code)
+
+	body = `{"name":"sim2","transport":"unix"}`
+	req, _ = http.NewRequest("POST", "/tcp", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	// TLS를 쓰려면 인증서를 확인할 서버 이름이 필요함
+	cases := map[string]int{
+		`{"name":"tls1","transport":"unix","socket_path":"/tmp/sim.sock","tls":{"enabled":true}}`:                             http.StatusBadRequest,
+		`{"name":"tls2","transport":"unix","socket_path":"/tmp/sim.sock","tls":{"enabled":true,"server_name":"edge.lab"}}`:    http.StatusCreated,
+		`{"name":"tls3","transport":"unix","socket_path":"/tmp/sim.sock","tls":{"enabled":true,"insecure_skip_verify":true}}`: http.StatusCreated,
+	}
+	for body, code := range cases {
+		req, _ = http.NewRequest("POST", "/tcp", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp = httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, code, resp.Code, body)
+	}
+}
+
+func TestCreateTCPServerHostnameAndIPv6(t *testing.T) {
+	db := setupTestDB()
+	router := setupTCPServerRouter(db, services.NewTCPConnectionManager())
+
+	for _, host := range []string{"edge-01.lab", "::1", "fe80::1"} {
+		body := `{"name":"` + host + `","host":"` + host + `","port":1234}`
+		req, _ := http.NewRequest("POST", "/tcp", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusCreated, resp.Code, host)
+	}
+}
+
+func TestCreateTCPServerProxySettings(t *testing.T) {
+	db := setupTestDB()
+	router := setupTCPServerRouter(db, services.NewTCPConnectionManager())
+
+	cases := map[string]int{
+		`{"name":"a","host":"10.0.0.5","port":502,"proxy":{"type":"socks5","address":"jump.lab:1080","username":"lab","password":"pw"}}`: http.StatusCreated,
+		`{"name":"b","host":"10.0.0.5","port":502,"proxy":{"type":"http","address":"jump.lab:3128"}}`:                                    http.StatusCreated,
+		`{"name":"c","host":"10.0.0.5","port":502,"proxy":{"type":"socks4","address":"jump.lab:1080"}}`:                                  http.StatusBadRequest,
+		`{"name":"d","host":"10.0.0.5","port":502,"proxy":{"type":"socks5","address":"jump.lab"}}`:                                       http.StatusBadRequest,
+		`{"name":"e","host":"10.0.0.5","port":502,"transport":"udp","proxy":{"type":"socks5","address":"jump.lab:1080"}}`:                http.StatusBadRequest,
+	}
+	for body, code := range cases {
+		req, _ := http.NewRequest("POST", "/tcp", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, code, resp.Code, body)
+	}
+}
+
+// selfSignedPair는 클라이언트 인증서로 쓸 자체 서명 인증서와 키를 PEM으로 만듭니다.
+func selfSignedPair(t *testing.T) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "tester"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return string(certPEM), string(keyPEM)
+}
+
+func TestTCPServerClientKeyIsWriteOnly(t *testing.T) {
+	db := setupTestDB()
+	router := setupTCPServerRouter(db, services.NewTCPConnectionManager())
+	cert, key := selfSignedPair(t)
+
+	req := models.TCPServerRequest{Name: "mtls", Host: "127.0.0.1", Port: 8443,
+		TLS: models.TLSSettings{Enabled: true, ClientCert: cert, ClientKey: key}}
+	body, _ := json.Marshal(req)
+	resp := doJSON(router, "POST", "/tcp", string(body))
+	require.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+	var created models.TCPServer
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &created))
+	assert.Equal(t, cert, created.TLS.ClientCert)
+	assert.Empty(t, created.TLS.ClientKey)
+	assert.NotContains(t, resp.Body.String(), "PRIVATE KEY")
+
+	// 조회 응답에도 키가 없지만 DB에는 남아 있음
+	for _, path := range []string{"/tcp", "/tcp/" + itoa(created.ID)} {
+		resp = doJSON(router, "GET", path, "")
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.NotContains(t, resp.Body.String(), "PRIVATE KEY", path)
+	}
+	var stored models.TCPServer
+	require.NoError(t, db.First(&stored, created.ID).Error)
+	assert.Equal(t, key, stored.TLS.ClientKey)
+
+	// 키를 비운 수정 요청은 저장된 키를 유지함
+	req.Port = 9443
+	req.TLS.ClientKey = ""
+	body, _ = json.Marshal(req)
+	resp = doJSON(router, "PUT", "/tcp/"+itoa(created.ID), string(body))
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	assert.NotContains(t, resp.Body.String(), "PRIVATE KEY")
+	require.NoError(t, db.First(&stored, created.ID).Error)
+	assert.Equal(t, 9443, stored.Port)
+	assert.Equal(t, key, stored.TLS.ClientKey)
+
+	// 인증서를 바꾸면 키도 함께 보내야 함
+	otherCert, _ := selfSignedPair(t)
+	req.TLS.ClientCert = otherCert
+	body, _ = json.Marshal(req)
+	resp = doJSON(router, "PUT", "/tcp/"+itoa(created.ID), string(body))
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestTCPServerProxyPasswordIsWriteOnly(t *testing.T) {
+	db := setupTestDB()
+	router := setupTCPServerRouter(db, services.NewTCPConnectionManager())
+
+	body := `{"name":"a","host":"10.0.0.5","port":502,"proxy":{"type":"socks5","address":"jump.lab:1080","username":"lab","password":"s3cret"}}`
+	resp := doJSON(router, "POST", "/tcp", body)
+	require.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+	assert.NotContains(t, resp.Body.String(), "s3cret")
+	var created models.TCPServer
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &created))
+	assert.Equal(t, "lab", created.Proxy.Username)
+
+	for _, path := range []string{"/tcp", "/tcp/" + itoa(created.ID)} {
+		resp = doJSON(router, "GET", path, "")
+		assert.NotContains(t, resp.Body.String(), "s3cret", path)
+	}
+
+	// 비밀번호를 비운 수정은 저장된 값을 유지하고, 프록시 주소가 바뀌면 유지하지 않음
+	resp = doJSON(router, "PUT", "/tcp/"+itoa(created.ID), `{"name":"a","host":"10.0.0.6","port":502,"proxy":{"type":"socks5","address":"jump.lab:1080","username":"lab"}}`)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	assert.NotContains(t, resp.Body.String(), "s3cret")
+	var stored models.TCPServer
+	require.NoError(t, db.First(&stored, created.ID).Error)
+	assert.Equal(t, "10.0.0.6", stored.Host)
+	assert.Equal(t, "s3cret", stored.Proxy.Password)
+
+	resp = doJSON(router, "PUT", "/tcp/"+itoa(created.ID), `{"name":"a","host":"10.0.0.6","port":502,"proxy":{"type":"socks5","address":"other.lab:1080","username":"lab"}}`)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	require.NoError(t, db.First(&stored, created.ID).Error)
+	assert.Empty(t, stored.Proxy.Password)
+}
@@ -1,9 +1,11 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/fake-edge-server/models"
+	"github.com/fake-edge-server/utils"
 	"github.com/gin-gonic/gin"
 )
 
@@ -18,3 +20,94 @@ func (h *TCPServerHandler) getServerByID(c *gin.Context) (*models.TCPServer, boo
 	}
 	return &server, true
 }
+
+// validateServerRequest는 TCP 서버 생성/수정 요청의 주소와 연결 설정을 검증합니다.
+func validateServerRequest(req models.TCPServerRequest) error {
+	switch req.Transport {
+	case "", models.TransportTCP, models.TransportUDP:
+		if !utils.IsValidHost(req.Host) {
+			return errors.New("유효한 IP 주소 또는 호스트 이름을 입력해주세요")
+		}
+
+		if req.Port < 1 || req.Port > 65535 {
+			return errors.New("유효한 포트 번호를 입력해주세요 (1-65535)")
+		}
+
+		if req.Transport == models.TransportUDP && req.TLS.Enabled {
+			return errors.New("UDP 전송에서는 TLS를 사용할 수 없습니다")
+		}
+
+		if req.Transport == models.TransportUDP && req.Proxy.Enabled() {
+			return errors.New("UDP 전송에서는 프록시를 사용할 수 없습니다")
+		}
+	case models.TransportUnix:
+		if req.Socket == "" {
+			return errors.New("Unix 소켓 경로를 입력해주세요")
+		}
+
+		if req.Proxy.Enabled() {
+			return errors.New("Unix 소켓 전송에서는 프록시를 사용할 수 없습니다")
+		}
+
+		// 소켓 경로로는 인증서의 호스트 이름을 확인할 수 없음
+		if req.TLS.Enabled && req.TLS.ServerName == "" && !req.TLS.InsecureSkipVerify {
+			return errors.New("Unix 소켓에서 TLS를 사용하려면 tls.server_name을 지정하거나 tls.insecure_skip_verify를 켜주세요")
+		}
+	default:
+		return errors.New("지원되지 않는 전송 방식: " + req.Transport)
+	}
+
+	if req.WindowMs < 0 {
+		return errors.New("응답 대기 시간은 0 이상이어야 합니다")
+	}
+
+	if _, err := req.Framing.Format(); err != nil {
+		return errors.New("유효하지 않은 프레임 설정: " + err.Error())
+	}
+
+	if err := req.Proxy.Validate(); err != nil {
+		return errors.New("유효하지 않은 프록시 설정: " + err.Error())
+	}
+
+	if err := req.Reconnect.Validate(); err != nil {
+		return errors.New("유효하지 않은 재연결 설정: " + err.Error())
+	}
+
+	if _, err := req.TLS.Config(req.Host); err != nil {
+		return errors.New("유효하지 않은 TLS 설정: " + err.Error())
+	}
+	return nil
+}
+
+// keepServerSecrets는 응답에서 비워 보낸 비밀 값을 수정 요청이 비워 두었으면 저장된 값으로 채웁니다.
+// 클라이언트 인증서가 바뀌면 키를, 프록시 주소나 사용자가 바뀌면 비밀번호를 새로 받아야 합니다.
+func keepServerSecrets(req *models.TCPServerRequest, server models.TCPServer) {
+	if req.TLS.ClientKey == "" && req.TLS.ClientCert != "" && req.TLS.ClientCert == server.TLS.ClientCert {
+		req.TLS.ClientKey = server.TLS.ClientKey
+	}
+	stored := server.Proxy
+	if req.Proxy.Password == "" && req.Proxy.Username != "" && req.Proxy.Type == stored.Type &&
+		req.Proxy.Address == stored.Address && req.Proxy.Username == stored.Username {
+		req.Proxy.Password = stored.Password
+	}
+}
+
+// redactServer는 응답으로 보내기 전에 서버의 비밀 값을 비웁니다.
+func redactServer(server *models.TCPServer) {
+	server.TLS = server.TLS.Redacted()
+	server.Proxy = server.Proxy.Redacted()
+}
+
+// applyServerRequest는 요청 값을 TCP 서버 모델에 반영합니다.
+func applyServerRequest(server *models.TCPServer, req models.TCPServerRequest) {
+	server.Name = req.Name
+	server.Host = req.Host
+	server.Port = req.Port
+	server.Framing = req.Framing
+	server.TLS = req.TLS
+	server.Proxy = req.Proxy
+	server.Reconnect = req.Reconnect
+	server.Transport = req.Transport
+	server.Socket = req.Socket
+	server.WindowMs = req.WindowMs
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
@@ -8,6 +9,8 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/fake-edge-server/models"
+	"github.com/fake-edge-server/services"
 	"github.com/gin-gonic/gin"
 )
 
@@ -19,11 +22,26 @@ func (h *TCPServerHandler) CheckTCPStatus(c *gin.Context) {
 	}
 
 	status := h.ConnManager.GetStatus(server.ID)
-	c.JSON(http.StatusOK, gin.H{
-		"id":     server.ID,
-		"name":   server.Name,
-		"status": status,
-	})
+	resp := gin.H{
+		"id":        server.ID,
+		"name":      server.Name,
+		"status":    status,
+		"transport": server.Network(),
+	}
+	if attempt, ok := h.ConnManager.ReconnectAttempt(server.ID); ok {
+		resp["reconnect_attempt"] = attempt
+		resp["max_attempts"] = server.Reconnect.MaxAttempts
+	}
+	if info, ok := h.ConnManager.GetInfo(server.ID); ok {
+		resp["remote_addr"] = info.RemoteAddr
+		if info.LastResponseAt != nil {
+			resp["last_response_at"] = info.LastResponseAt
+		}
+		if info.TLS != nil {
+			resp["tls"] = info.TLS
+		}
+	}
+	c.JSON(http.StatusOK, resp)
 }
 
 // StartTCPServer는 TCP 서버와의 연결을 시작합니다.
@@ -32,23 +50,33 @@ func (h *TCPServerHandler) StartTCPServer(c *gin.Context) {
 	if !ok {
 		return
 	}
-	if err := h.ConnManager.Connect(server.ID, server.Host, server.Port); err != nil {
+	if err := h.ConnManager.ConnectServer(*server); err != nil {
+		// 프록시 실패인지 대상 서버 실패인지 구분한다
+		stage := services.DialStageTarget
+		var dialErr *services.DialError
+		if errors.As(err, &dialErr) {
+			stage = dialErr.Stage
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"id":      server.ID,
 			"name":    server.Name,
 			"message": "TCP 서버 연결 실패",
+			"error":   err.Error(),
+			"stage":   stage,
 			"status":  h.ConnManager.GetStatus(server.ID),
 		})
 		return
 	}
 
-	h.Hub.Broadcast(gin.H{"type": "status", "server_id": server.ID, "status": "Alive"})
+	// UDP는 연결 상태가 없으므로 마지막 응답 기준의 상태를 알린다
+	status := h.ConnManager.GetStatus(server.ID)
+	h.Hub.Broadcast(gin.H{"type": "status", "server_id": server.ID, "status": status})
 	h.Hub.Broadcast(gin.H{"type": "log", "message": "server started", "server_id": server.ID})
 	c.JSON(http.StatusOK, gin.H{
 		"id":      server.ID,
 		"name":    server.Name,
 		"message": "TCP 서버 시작됨",
-		"status":  "Alive",
+		"status":  status,
 	})
 }
 
@@ -98,6 +126,11 @@ func (h *TCPServerHandler) KillTCPServer(c *gin.Context) {
 		return
 	}
 
+	if server.Network() != models.TransportTCP {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "TCP 포트로 실행 중인 서버만 종료할 수 있습니다"})
+		return
+	}
+
 	if server.Host != "127.0.0.1" && server.Host != "localhost" {
 		c.JSON(http.StatusForbidden, gin.H{"error": "로컬 서버만 종료할 수 있습니다"})
 		return
@@ -0,0 +1,122 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// 주입한 결함 이름 (이력의 Faults에 기록)
+const (
+	FaultLatency   = "latency"   // 전송 지연 (LatencyMs ± JitterMs)
+	FaultDrop      = "drop"      // 프레임을 보내지 않음
+	FaultCorrupt   = "corrupt"   // 임의의 비트를 뒤집음
+	FaultCRC       = "crc"       // 프레임 체크섬 필드를 틀린 값으로 바꿈
+	FaultTruncate  = "truncate"  // 프레임 뒷부분을 잘라 보냄
+	FaultDuplicate = "duplicate" // 같은 프레임을 두 번 보냄
+	FaultReorder   = "reorder"   // 다음 프레임 뒤로 순서를 바꿔 보냄
+	FaultReset     = "reset"     // 연결을 RST로 끊음
+)
+
+// FaultProfile은 목 엔드포인트와 중계가 보내는 프레임에 의도적으로 주입할 결함 설정입니다.
+// 각 결함은 *Rate 확률(0~1)로 프레임마다 독립적으로 적용되며, 0이면 사용하지 않습니다.
+type FaultProfile struct {
+	LatencyRate   float64 `json:"latency_rate"`
+	LatencyMs     int     `json:"latency_ms"`
+	JitterMs      int     `json:"jitter_ms"` // 지연에 더하거나 뺄 최대 값
+	DropRate      float64 `json:"drop_rate"`
+	CorruptRate   float64 `json:"corrupt_rate"`
+	CorruptBits   int     `json:"corrupt_bits"` // 뒤집을 비트 수, 0이면 1
+	CRCRate       float64 `json:"crc_rate"`     // CRC 프레임을 사용할 때만 적용
+	TruncateRate  float64 `json:"truncate_rate"`
+	DuplicateRate float64 `json:"duplicate_rate"`
+	ReorderRate   float64 `json:"reorder_rate"`
+	ResetRate     float64 `json:"reset_rate"`
+	// Direction은 중계에서 결함을 적용할 방향입니다. 비어 있으면 양방향에 적용합니다.
+	Direction string `json:"direction"`
+	Seed      int64  `json:"seed"` // 0이 아니면 같은 순서로 결함을 재현
+}
+
+// Enabled는 적용할 결함이 하나라도 있는지 확인합니다.
+func (p FaultProfile) Enabled() bool {
+	for _, rate := range p.rates() {
+		if rate > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Applies는 중계의 한 방향에 결함을 적용하는지 확인합니다.
+func (p FaultProfile) Applies(direction string) bool {
+	return p.Direction == "" || p.Direction == direction
+}
+
+// Jitter는 기본 지연과 지터의 최대 값을 반환합니다.
+func (p FaultProfile) Jitter() (time.Duration, time.Duration) {
+	return time.Duration(p.LatencyMs) * time.Millisecond, time.Duration(p.JitterMs) * time.Millisecond
+}
+
+// rates는 결함 이름별 적용 확률을 반환합니다.
+func (p FaultProfile) rates() map[string]float64 {
+	return map[string]float64{
+		FaultLatency:   p.LatencyRate,
+		FaultDrop:      p.DropRate,
+		FaultCorrupt:   p.CorruptRate,
+		FaultCRC:       p.CRCRate,
+		FaultTruncate:  p.TruncateRate,
+		FaultDuplicate: p.DuplicateRate,
+		FaultReorder:   p.ReorderRate,
+		FaultReset:     p.ResetRate,
+	}
+}
+
+// Validate는 확률과 시간 값의 범위를 검증합니다.
+func (p FaultProfile) Validate() error {
+	for name, rate := range p.rates() {
+		if rate < 0 || rate > 1 {
+			return fmt.Errorf("%s 확률은 0과 1 사이여야 합니다: %v", name, rate)
+		}
+	}
+	if p.LatencyMs < 0 || p.JitterMs < 0 {
+		return errors.New("latency_ms와 jitter_ms는 0 이상이어야 합니다")
+	}
+	if p.CorruptBits < 0 {
+		return errors.New("corrupt_bits는 0 이상이어야 합니다")
+	}
+	switch p.Direction {
+	case "", DirectionClientToServer, DirectionServerToClient:
+	default:
+		return fmt.Errorf("지원되지 않는 결함 방향: %s", p.Direction)
+	}
+	return nil
+}
+
+// Value는 GORM을 위한 driver.Value 인터페이스를 구현합니다.
+func (p FaultProfile) Value() (driver.Value, error) {
+	return json.Marshal(p)
+}
+
+// Scan은 GORM을 위한 sql.Scanner 인터페이스를 구현합니다.
+func (p *FaultProfile) Scan(value interface{}) error {
+	var bytes []byte
+	switch v := value.(type) {
+	case nil:
+		*p = FaultProfile{}
+		return nil
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return errors.New("결함 설정을 스캔할 수 없음")
+	}
+
+	if len(bytes) == 0 {
+		*p = FaultProfile{}
+		return nil
+	}
+	return json.Unmarshal(bytes, p)
+}
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/fake-edge-server/utils"
+)
+
+// FrameProfile은 서버별 CRC 프레임 헤더 설정입니다.
+// 비어 있는 항목은 기본 프레임(0xABCD1234 + 길이 + IEEE CRC32, 리틀 엔디안) 값을 사용합니다.
+type FrameProfile struct {
+	Magic         string   `json:"magic"`          // 와이어 순서의 HEX 문자열 (예: "3412cdab")
+	Fields        []string `json:"fields"`         // 헤더 필드 순서 (magic, length, checksum)
+	Endian        string   `json:"endian"`         // little | big
+	LengthSize    int      `json:"length_size"`    // 길이 필드 크기 (2 또는 4)
+	Checksum      string   `json:"checksum"`       // none | crc32 | crc32c
+	ChecksumScope string   `json:"checksum_scope"` // payload | frame
+}
+
+// Format은 프로필을 검증하고 프레임 빌드/해석에 사용할 utils.FrameFormat으로 변환합니다.
+func (p FrameProfile) Format() (utils.FrameFormat, error) {
+	format := utils.DefaultFrameFormat
+
+	if p.Magic != "" {
+		magic, err := hex.DecodeString(p.Magic)
+		if err != nil {
+			return format, fmt.Errorf("magic 값은 HEX 문자열이어야 합니다: %v", err)
+		}
+		format.Magic = magic
+	}
+	if len(p.Fields) > 0 {
+		format.Fields = p.Fields
+	}
+	switch p.Endian {
+	case "", "little":
+	case "big":
+		format.Order = binary.BigEndian
+	default:
+		return format, fmt.Errorf("지원되지 않는 엔디안: %s", p.Endian)
+	}
+	if p.LengthSize != 0 {
+		format.LengthSize = p.LengthSize
+	}
+	if p.Checksum != "" {
+		format.Checksum = p.Checksum
+	}
+	if p.ChecksumScope != "" {
+		format.ChecksumScope = p.ChecksumScope
+	}
+
+	// 체크섬을 끄면서 필드 순서를 지정하지 않았다면 기본 순서에서 checksum 필드를 뺀다
+	if format.Checksum == utils.ChecksumNone && len(p.Fields) == 0 {
+		format.Fields = []string{utils.FieldMagic, utils.FieldLength}
+	}
+
+	if err := format.Validate(); err != nil {
+		return format, err
+	}
+	return format, nil
+}
+
+// Value는 GORM을 위한 driver.Value 인터페이스를 구현합니다.
+func (p FrameProfile) Value() (driver.Value, error) {
+	return json.Marshal(p)
+}
+
+// Scan은 GORM을 위한 sql.Scanner 인터페이스를 구현합니다.
+func (p *FrameProfile) Scan(value interface{}) error {
+	var bytes []byte
+	switch v := value.(type) {
+	case nil:
+		*p = FrameProfile{}
+		return nil
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return errors.New("프레임 프로필을 스캔할 수 없음")
+	}
+
+	if len(bytes) == 0 {
+		*p = FrameProfile{}
+		return nil
+	}
+	return json.Unmarshal(bytes, p)
+}
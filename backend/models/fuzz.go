@@ -0,0 +1,159 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 퍼징 변형 종류
+const (
+	MutationBoundary = "boundary"        // 정수 필드에 0, 1, 최소/최대값 같은 경계값
+	MutationFloat    = "float_special"   // 실수 필드에 NaN, ±Inf, -0, 극단값
+	MutationOverlong = "overlong_string" // 문자열/HEX 필드를 아주 긴 값이나 서식 문자열로 교체
+	MutationJSON     = "invalid_json"    // JSON 필드를 깨진 JSON으로 교체
+	MutationBitFlip  = "bit_flip"        // 임의 필드의 비트 반전
+	MutationLength   = "length_mismatch" // 헤더 길이 필드(또는 raw 데이터 길이)를 실제와 다르게
+	MutationCRC      = "crc_mismatch"    // 체크섬 훼손 (체크섬이 있는 프레임에서만)
+)
+
+// Mutations는 지원하는 모든 변형 종류입니다.
+var Mutations = []string{
+	MutationBoundary, MutationFloat, MutationOverlong, MutationJSON,
+	MutationBitFlip, MutationLength, MutationCRC,
+}
+
+// 퍼징 크래시 종류
+const (
+	FuzzCrashDisconnect = "disconnect" // 장비가 연결을 끊음
+	FuzzCrashReset      = "reset"      // 장비가 RST로 연결을 끊음
+	FuzzCrashTimeout    = "timeout"    // 응답이 없고 정상 패킷에도 응답하지 않음
+)
+
+// 퍼징 작업 상태
+const (
+	FuzzRunning   = "running"
+	FuzzCompleted = "completed" // 지정한 횟수를 모두 실행함
+	FuzzStopped   = "stopped"   // 사용자가 중지했거나 크래시로 중단함
+	FuzzFailed    = "failed"    // 기준 패킷에 응답이 없거나 접속할 수 없음
+)
+
+// FuzzJob은 하나의 패킷 정의를 변형해 보내며 장비의 크래시를 찾는 퍼징 작업입니다.
+// Seed가 같으면 같은 순서로 입력이 만들어집니다.
+type FuzzJob struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	TCPServerID uint           `json:"tcp_server_id" gorm:"index"`
+	TCPPacketID uint           `json:"tcp_packet_id"`
+	Name        string         `json:"name"`
+	Iterations  int            `json:"iterations"`
+	Seed        int64          `json:"seed"`
+	Mutations   MutationList   `json:"mutations" gorm:"type:text"` // 비어 있으면 적용 가능한 모든 변형
+	TimeoutMs   int            `json:"timeout_ms"`                 // 0이면 서버의 응답 대기 시간
+	StopOnCrash bool           `json:"stop_on_crash"`
+	Status      string         `json:"status"`
+	Error       string         `json:"error"`
+	Executed    int            `json:"executed"` // 보낸 변형 입력 수
+	Crashes     int            `json:"crashes"`
+	StartedAt   *time.Time     `json:"started_at"`
+	FinishedAt  *time.Time     `json:"finished_at"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+// FuzzJobRequest는 퍼징 작업 시작 요청 구조체입니다.
+type FuzzJobRequest struct {
+	Name        string       `json:"name"`
+	PacketID    uint         `json:"packet_id" binding:"required"`
+	Iterations  int          `json:"iterations" binding:"required"`
+	Seed        int64        `json:"seed"` // 0이면 임의로 정해 작업에 저장
+	Mutations   MutationList `json:"mutations"`
+	TimeoutMs   int          `json:"timeout_ms"`
+	StopOnCrash bool         `json:"stop_on_crash"`
+}
+
+// Validate는 퍼징 요청 값의 범위와 변형 종류를 검증합니다.
+func (r FuzzJobRequest) Validate() error {
+	if r.Iterations <= 0 || r.Iterations > 1000000 {
+		return errors.New("반복 횟수는 1~1000000 사이여야 합니다")
+	}
+	if r.TimeoutMs < 0 {
+		return errors.New("응답 대기 시간은 0 이상이어야 합니다")
+	}
+	for _, mutation := range r.Mutations {
+		if !isMutation(mutation) {
+			return fmt.Errorf("지원되지 않는 변형: %s", mutation)
+		}
+	}
+	return nil
+}
+
+func isMutation(name string) bool {
+	for _, mutation := range Mutations {
+		if mutation == name {
+			return true
+		}
+	}
+	return false
+}
+
+// FuzzCase는 크래시를 일으킨 입력입니다. Input은 전송한 바이트 그대로이므로 재생하면 같은 입력이 전송됩니다.
+type FuzzCase struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	FuzzJobID   uint      `json:"fuzz_job_id" gorm:"index"`
+	TCPServerID uint      `json:"tcp_server_id"`
+	TCPPacketID uint      `json:"tcp_packet_id"`
+	Iteration   int       `json:"iteration"`
+	Mutation    string    `json:"mutation"`
+	Offset      int       `json:"offset"` // 변형한 필드의 오프셋, 프레임 단위 변형이면 -1
+	Detail      string    `json:"detail"` // 변형 내용 (예: "int16 min")
+	Input       string    `json:"input"`  // 전송한 바이트 (HEX)
+	Crash       string    `json:"crash"`
+	Error       string    `json:"error"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// FuzzReplayResult는 크래시 입력을 다시 보낸 결과입니다.
+type FuzzReplayResult struct {
+	Crash    string `json:"crash"`    // 비어 있으면 크래시가 재현되지 않음
+	Response string `json:"response"` // 받은 응답 (HEX)
+	Error    string `json:"error"`
+	Alive    bool   `json:"alive"` // 재생 후 정상 패킷에 응답하는지
+}
+
+// MutationList는 변형 종류 이름의 배열입니다.
+type MutationList []string
+
+// Value는 GORM을 위한 driver.Value 인터페이스를 구현합니다.
+func (l MutationList) Value() (driver.Value, error) {
+	if l == nil {
+		return nil, nil
+	}
+	return json.Marshal(l)
+}
+
+// Scan은 GORM을 위한 sql.Scanner 인터페이스를 구현합니다.
+func (l *MutationList) Scan(value interface{}) error {
+	var bytes []byte
+	switch v := value.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return errors.New("변형 목록을 스캔할 수 없음")
+	}
+
+	if len(bytes) == 0 {
+		*l = nil
+		return nil
+	}
+	return json.Unmarshal(bytes, l)
+}
@@ -0,0 +1,157 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 부하 시험 상태
+const (
+	LoadTestRunning   = "running"
+	LoadTestCompleted = "completed" // 지정한 시간 동안 실행을 마침
+	LoadTestStopped   = "stopped"   // 사용자가 중지함
+)
+
+// 부하 시험 오류 종류 (LoadTestReport.Errors의 키)
+const (
+	LoadErrorConnect = "connect" // 접속 실패
+	LoadErrorWrite   = "write"   // 전송 실패
+	LoadErrorTimeout = "timeout" // 응답 대기 시간 초과
+	LoadErrorClosed  = "closed"  // 응답 전에 연결이 끊김
+	LoadErrorFrame   = "frame"   // 응답 프레임 해석 실패
+)
+
+// LoadTest는 하나의 TCP 서버에 여러 연결로 패킷을 보내는 부하 시험입니다.
+// Rate가 0이면 각 연결이 응답을 받자마자 다음 패킷을 보내는 closed-loop로 동작하고,
+// 0보다 크면 모든 연결을 합쳐 초당 Rate개의 메시지를 보냅니다.
+type LoadTest struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	TCPServerID uint           `json:"tcp_server_id" gorm:"index"`
+	Name        string         `json:"name"`
+	Connections int            `json:"connections"`
+	Rate        float64        `json:"rate"`        // 초당 전체 메시지 수
+	RampUpMs    int            `json:"ramp_up_ms"`  // 모든 연결을 여는 데 걸리는 시간
+	DurationMs  int            `json:"duration_ms"` // 램프업을 포함한 전체 실행 시간
+	PacketIDs   IDList         `json:"packet_ids" gorm:"type:text"`
+	Status      string         `json:"status"`
+	StartedAt   *time.Time     `json:"started_at"`
+	FinishedAt  *time.Time     `json:"finished_at"`
+	Report      LoadTestReport `json:"report" gorm:"type:text"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+// LoadTestRequest는 부하 시험 시작 요청 구조체입니다.
+type LoadTestRequest struct {
+	Name        string  `json:"name"`
+	Connections int     `json:"connections" binding:"required"`
+	Rate        float64 `json:"rate"`
+	RampUpMs    int     `json:"ramp_up_ms"`
+	DurationMs  int     `json:"duration_ms" binding:"required"`
+	PacketIDs   IDList  `json:"packet_ids" binding:"required"`
+}
+
+// LatencyStats는 응답 시간 분포(밀리초)입니다.
+type LatencyStats struct {
+	P50 float64 `json:"p50_ms"`
+	P90 float64 `json:"p90_ms"`
+	P99 float64 `json:"p99_ms"`
+	Max float64 `json:"max_ms"`
+}
+
+// LoadTestReport는 부하 시험 결과입니다. 실행 중에는 현재까지의 값을 나타냅니다.
+type LoadTestReport struct {
+	ElapsedMs    int64            `json:"elapsed_ms"`
+	Active       int              `json:"active"` // 현재 열린 연결 수
+	Sent         int64            `json:"sent"`
+	Received     int64            `json:"received"`
+	Uncorrelated int64            `json:"uncorrelated"` // 요청과 짝지을 수 없는 응답 수 (시간 초과 뒤에 도착한 raw 응답 등)
+	Errors       map[string]int64 `json:"errors"`
+	Throughput   float64          `json:"throughput"` // 초당 응답 수
+	Latency      LatencyStats     `json:"latency"`
+}
+
+// Validate는 부하 시험 요청 값의 범위를 검증합니다.
+func (r LoadTestRequest) Validate() error {
+	if r.Connections <= 0 || r.Connections > 10000 {
+		return errors.New("connections는 1에서 10000 사이여야 합니다")
+	}
+	if r.Rate < 0 {
+		return errors.New("rate는 0 이상이어야 합니다")
+	}
+	if r.DurationMs <= 0 {
+		return errors.New("duration_ms는 0보다 커야 합니다")
+	}
+	if r.RampUpMs < 0 || r.RampUpMs > r.DurationMs {
+		return errors.New("ramp_up_ms는 0 이상이고 duration_ms 이하여야 합니다")
+	}
+	if len(r.PacketIDs) == 0 {
+		return errors.New("보낼 패킷을 하나 이상 지정해주세요")
+	}
+	return nil
+}
+
+// IDList는 ID 배열입니다.
+type IDList []uint
+
+// Value는 GORM을 위한 driver.Value 인터페이스를 구현합니다.
+func (l IDList) Value() (driver.Value, error) {
+	if l == nil {
+		return nil, nil
+	}
+	return json.Marshal(l)
+}
+
+// Scan은 GORM을 위한 sql.Scanner 인터페이스를 구현합니다.
+func (l *IDList) Scan(value interface{}) error {
+	var bytes []byte
+	switch v := value.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return errors.New("ID 목록을 스캔할 수 없음")
+	}
+
+	if len(bytes) == 0 {
+		*l = nil
+		return nil
+	}
+	return json.Unmarshal(bytes, l)
+}
+
+// Value는 GORM을 위한 driver.Value 인터페이스를 구현합니다.
+func (r LoadTestReport) Value() (driver.Value, error) {
+	return json.Marshal(r)
+}
+
+// Scan은 GORM을 위한 sql.Scanner 인터페이스를 구현합니다.
+func (r *LoadTestReport) Scan(value interface{}) error {
+	var bytes []byte
+	switch v := value.(type) {
+	case nil:
+		*r = LoadTestReport{}
+		return nil
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return errors.New("부하 시험 결과를 스캔할 수 없음")
+	}
+
+	if len(bytes) == 0 {
+		*r = LoadTestReport{}
+		return nil
+	}
+	return json.Unmarshal(bytes, r)
+}
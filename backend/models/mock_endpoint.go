@@ -0,0 +1,114 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 목 엔드포인트 이력의 프레임 방향
+const (
+	DirectionInbound  = "inbound"  // 클라이언트 → 목 서버
+	DirectionOutbound = "outbound" // 목 서버 → 클라이언트
+)
+
+// MockEndpoint는 Edge 장비를 흉내 내는 수신 대기(리스닝) 엔드포인트입니다.
+type MockEndpoint struct {
+	ID       uint         `json:"id" gorm:"primaryKey"`
+	Name     string       `json:"name" gorm:"uniqueIndex"`
+	BindAddr string       `json:"bind_address"` // 비어 있으면 모든 인터페이스
+	Port     int          `json:"port"`         // 0이면 임의의 포트
+	UseCRC   bool         `json:"use_crc"`      // 프레임 단위로 수신/전송
+	Framing  FrameProfile `json:"framing" gorm:"type:text"`
+	// States는 상태 머신의 상태 이름 목록이며, 비어 있으면 상태 이름을 검증하지 않습니다.
+	States       StateList      `json:"states" gorm:"type:text"`
+	InitialState string         `json:"initial_state"`           // 새 연결의 시작 상태
+	Faults       FaultProfile   `json:"faults" gorm:"type:text"` // 보내는 프레임에 주입할 결함
+	Running      bool           `json:"running" gorm:"-"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+// MockEndpointRequest는 목 엔드포인트 생성/수정 요청 구조체입니다.
+type MockEndpointRequest struct {
+	Name     string       `json:"name" binding:"required"`
+	BindAddr string       `json:"bind_address"`
+	Port     int          `json:"port"`
+	UseCRC   bool         `json:"use_crc"`
+	Framing  FrameProfile `json:"framing"`
+	// States/InitialState는 연결별 상태 머신 설정입니다.
+	States       StateList    `json:"states"`
+	InitialState string       `json:"initial_state"`
+	Faults       FaultProfile `json:"faults"`
+}
+
+// StateList는 목 엔드포인트 상태 이름의 배열입니다.
+type StateList []string
+
+// Has는 상태 목록에 name이 있는지 확인합니다.
+func (l StateList) Has(name string) bool {
+	for _, state := range l {
+		if state == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate는 상태 목록에 빈 이름이나 중복된 이름이 없는지 검증합니다.
+func (l StateList) Validate() error {
+	for i, state := range l {
+		if state == "" {
+			return errors.New("상태 이름은 비어 있을 수 없습니다")
+		}
+		if l[:i].Has(state) {
+			return fmt.Errorf("중복된 상태 이름: %s", state)
+		}
+	}
+	return nil
+}
+
+// CheckState는 시작 상태나 규칙의 상태로 name을 쓸 수 있는지 검증합니다.
+// 빈 이름은 상태를 지정하지 않는다는 뜻이므로 허용하고, 그 밖의 이름은
+// 상태 목록이 비어 있지 않으면 목록에 있어야 합니다.
+func (l StateList) CheckState(name string) error {
+	if name == "" || len(l) == 0 || l.Has(name) {
+		return nil
+	}
+	return fmt.Errorf("정의되지 않은 상태입니다: %s", name)
+}
+
+// Value는 GORM을 위한 driver.Value 인터페이스를 구현합니다.
+func (l StateList) Value() (driver.Value, error) {
+	if l == nil {
+		return nil, nil
+	}
+	return json.Marshal(l)
+}
+
+// Scan은 GORM을 위한 sql.Scanner 인터페이스를 구현합니다.
+func (l *StateList) Scan(value interface{}) error {
+	var bytes []byte
+	switch v := value.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return errors.New("상태 목록을 스캔할 수 없음")
+	}
+
+	if len(bytes) == 0 {
+		*l = nil
+		return nil
+	}
+	return json.Unmarshal(bytes, l)
+}
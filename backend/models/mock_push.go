@@ -0,0 +1,73 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/fake-edge-server/utils"
+	"gorm.io/gorm"
+)
+
+// DirectionPush는 목 엔드포인트가 요청 없이 보낸 프레임의 이력 방향입니다.
+const DirectionPush = "push"
+
+// MockPush는 목 엔드포인트가 연결된 모든 클라이언트에 주기적으로 보내는 패킷입니다.
+// IntervalMs와 Cron 중 하나로 실행 시점을 지정합니다.
+type MockPush struct {
+	ID             uint           `json:"id" gorm:"primaryKey"`
+	MockEndpointID uint           `json:"mock_endpoint_id" gorm:"index"`
+	Name           string         `json:"name"`
+	PacketID       uint           `json:"packet_id"`
+	IntervalMs     int            `json:"interval_ms"`
+	Cron           string         `json:"cron"`     // 분 시 일 월 요일
+	Timezone       string         `json:"timezone"` // 비어 있으면 서버 시간대
+	Enabled        bool           `json:"enabled"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+// MockPushRequest는 주기 전송 생성/수정 요청 구조체입니다.
+type MockPushRequest struct {
+	Name       string `json:"name"`
+	PacketID   uint   `json:"packet_id" binding:"required"`
+	IntervalMs int    `json:"interval_ms"`
+	Cron       string `json:"cron"`
+	Timezone   string `json:"timezone"`
+	Enabled    bool   `json:"enabled"`
+}
+
+// Schedule은 cron 표현식과 시간대를 해석합니다. Cron이 비어 있으면 nil을 반환합니다.
+func (p MockPush) Schedule() (*utils.CronSchedule, error) {
+	if p.Cron == "" {
+		return nil, nil
+	}
+	return parseCron(p.Cron, p.Timezone)
+}
+
+// parseCron은 cron 표현식을 시간대 기준으로 해석합니다. 시간대가 비어 있으면 서버 시간대입니다.
+func parseCron(spec, timezone string) (*utils.CronSchedule, error) {
+	loc := time.Local
+	if timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(timezone); err != nil {
+			return nil, errors.New("알 수 없는 시간대: " + timezone)
+		}
+	}
+	return utils.ParseCron(spec, loc)
+}
+
+// Validate는 실행 시점 설정을 검증합니다.
+func (p MockPush) Validate() error {
+	if (p.IntervalMs > 0) == (p.Cron != "") {
+		return errors.New("interval_ms와 cron 중 하나만 지정해주세요")
+	}
+	if p.IntervalMs < 0 {
+		return errors.New("interval_ms는 0보다 커야 합니다")
+	}
+	if p.Cron == "" && p.Timezone != "" {
+		return errors.New("timezone은 cron과 함께 사용합니다")
+	}
+	_, err := p.Schedule()
+	return err
+}
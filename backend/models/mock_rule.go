@@ -0,0 +1,386 @@
+package models
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 목 규칙의 매칭 방식
+const (
+	MatchAny   = "any"   // 모든 요청과 일치
+	MatchBytes = "bytes" // Offset부터 Pattern 바이트가 일치
+	MatchExact = "exact" // 요청 전체가 Pattern과 일치
+	MatchMask  = "mask"  // Offset부터 Mask를 적용한 Pattern이 일치
+	MatchField = "field" // 패킷 정의의 필드를 해석한 값이 FieldValue와 일치
+)
+
+// CopyField는 요청의 바이트 구간을 응답으로 복사하는 설정입니다.
+// 예: 요청의 시퀀스 번호를 응답에 그대로 돌려줄 때 사용합니다.
+type CopyField struct {
+	From   int `json:"from"`   // 요청 오프셋
+	To     int `json:"to"`     // 응답 오프셋
+	Length int `json:"length"` // 복사할 바이트 수
+}
+
+// CopyFields는 복사 설정의 배열입니다.
+type CopyFields []CopyField
+
+// VarBinding은 연결별 변수를 설정하거나 응답에 넣는 설정입니다.
+// SetVars에서는 Value(HEX)가 있으면 그 값을, 없으면 요청의 Offset부터 Length 바이트를 저장합니다.
+// UseVars에서는 변수 값을 응답의 Offset 위치에 쓰며, Length가 있으면 그 길이로 자르거나 0으로 채웁니다.
+type VarBinding struct {
+	Name   string `json:"name"`
+	Offset int    `json:"offset"`
+	Length int    `json:"length"`
+	Value  string `json:"value"`
+}
+
+// VarBindings는 변수 설정의 배열입니다.
+type VarBindings []VarBinding
+
+// MockRule은 목 엔드포인트가 수신한 프레임에 응답하는 규칙입니다.
+// 우선순위(Priority)가 낮은 규칙부터 검사하며, 일치하는 규칙이 없으면
+// 기본 규칙(IsDefault)으로 응답합니다. State가 지정된 규칙은 연결이 그 상태일 때만
+// 사용되고, 일치하면 연결 상태를 NextState로 바꿉니다.
+type MockRule struct {
+	ID               uint           `json:"id" gorm:"primaryKey"`
+	MockEndpointID   uint           `json:"mock_endpoint_id" gorm:"index"`
+	Name             string         `json:"name"`
+	Priority         int            `json:"priority"`
+	MatchType        string         `json:"match_type"`      // any | bytes | exact | mask | field
+	Offset           int            `json:"offset"`          // bytes/mask 매칭 시작 위치
+	Pattern          string         `json:"pattern"`         // HEX 문자열
+	Mask             string         `json:"mask"`            // HEX 문자열, Pattern과 같은 길이
+	FieldPacketID    uint           `json:"field_packet_id"` // 필드 정의를 가진 패킷
+	FieldOffset      int            `json:"field_offset"`
+	FieldValue       string         `json:"field_value"`
+	IsDefault        bool           `json:"is_default"`         // 일치하는 규칙이 없을 때 사용
+	NoResponse       bool           `json:"no_response"`        // 일치해도 응답하지 않음
+	ResponsePacketID uint           `json:"response_packet_id"` // 응답으로 보낼 패킷
+	ResponseHex      string         `json:"response_hex"`       // 패킷 대신 보낼 HEX 응답
+	CopyFields       CopyFields     `json:"copy_fields" gorm:"type:text"`
+	DelayMs          int            `json:"delay_ms"`
+	State            string         `json:"state"`      // 비어 있으면 모든 상태
+	NextState        string         `json:"next_state"` // 비어 있으면 상태 유지
+	SetVars          VarBindings    `json:"set_vars" gorm:"type:text"`
+	UseVars          VarBindings    `json:"use_vars" gorm:"type:text"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+// MockRuleRequest는 목 규칙 생성/수정 요청 구조체입니다.
+type MockRuleRequest struct {
+	Name             string      `json:"name"`
+	Priority         int         `json:"priority"`
+	MatchType        string      `json:"match_type"`
+	Offset           int         `json:"offset"`
+	Pattern          string      `json:"pattern"`
+	Mask             string      `json:"mask"`
+	FieldPacketID    uint        `json:"field_packet_id"`
+	FieldOffset      int         `json:"field_offset"`
+	FieldValue       string      `json:"field_value"`
+	IsDefault        bool        `json:"is_default"`
+	NoResponse       bool        `json:"no_response"`
+	ResponsePacketID uint        `json:"response_packet_id"`
+	ResponseHex      string      `json:"response_hex"`
+	CopyFields       CopyFields  `json:"copy_fields"`
+	DelayMs          int         `json:"delay_ms"`
+	State            string      `json:"state"`
+	NextState        string      `json:"next_state"`
+	SetVars          VarBindings `json:"set_vars"`
+	UseVars          VarBindings `json:"use_vars"`
+}
+
+// Validate는 매칭 방식별 필수 값과 응답 설정을 검증합니다.
+func (r MockRule) Validate() error {
+	switch r.MatchType {
+	case "", MatchAny:
+	case MatchExact:
+		if _, err := hex.DecodeString(r.Pattern); err != nil {
+			return errors.New("pattern은 HEX 문자열이어야 합니다")
+		}
+	case MatchBytes, MatchMask:
+		pattern, err := hex.DecodeString(r.Pattern)
+		if err != nil || len(pattern) == 0 {
+			return errors.New("pattern은 비어 있지 않은 HEX 문자열이어야 합니다")
+		}
+		if r.MatchType == MatchMask {
+			mask, err := hex.DecodeString(r.Mask)
+			if err != nil || len(mask) != len(pattern) {
+				return errors.New("mask는 pattern과 같은 길이의 HEX 문자열이어야 합니다")
+			}
+		}
+		if r.Offset < 0 {
+			return errors.New("offset은 0 이상이어야 합니다")
+		}
+	case MatchField:
+		if r.FieldPacketID == 0 {
+			return errors.New("field 매칭에는 field_packet_id가 필요합니다")
+		}
+	default:
+		return fmt.Errorf("지원되지 않는 매칭 방식: %s", r.MatchType)
+	}
+
+	if !r.NoResponse && r.ResponsePacketID == 0 && r.ResponseHex == "" {
+		return errors.New("응답 패킷이나 response_hex를 지정하거나 no_response를 사용해주세요")
+	}
+	if r.ResponseHex != "" {
+		if r.ResponsePacketID != 0 {
+			return errors.New("response_packet_id와 response_hex는 함께 사용할 수 없습니다")
+		}
+		if _, err := hex.DecodeString(r.ResponseHex); err != nil {
+			return errors.New("response_hex는 HEX 문자열이어야 합니다")
+		}
+	}
+	if r.DelayMs < 0 {
+		return errors.New("delay_ms는 0 이상이어야 합니다")
+	}
+	for _, cf := range r.CopyFields {
+		if cf.From < 0 || cf.To < 0 || cf.Length <= 0 {
+			return fmt.Errorf("잘못된 복사 설정: %+v", cf)
+		}
+	}
+	if err := r.SetVars.ValidateCapture(); err != nil {
+		return err
+	}
+	for _, v := range r.UseVars {
+		if v.Name == "" || v.Offset < 0 || v.Length < 0 {
+			return fmt.Errorf("잘못된 변수 사용 설정: %+v", v)
+		}
+	}
+	return nil
+}
+
+// Capture는 SetVars에 따라 요청 값을 연결별 변수에 저장합니다.
+// 요청을 벗어나는 변수는 이전 값을 지우고 오류를 반환합니다.
+func (r MockRule) Capture(request []byte, vars map[string][]byte) error {
+	for _, v := range r.SetVars {
+		if v.Value == "" && v.Offset+v.Length > len(request) {
+			delete(vars, v.Name)
+		}
+	}
+	return r.SetVars.Capture(request, vars)
+}
+
+// CheckBounds는 복사와 변수 구간이 요청과 응답 범위 안에 있는지 검증합니다.
+// 응답에 쓰는 위치는 응답 길이(responseLen)를 넘을 수 없으며, 응답 끝에 이어 쓰는 것은 허용합니다.
+// 요청 길이는 exact 매칭일 때만 알 수 있으므로 그때만 읽는 구간을 검사합니다.
+// responseLen이 음수이면(no_response) 응답 쪽 검사는 건너뜁니다.
+func (r MockRule) CheckBounds(responseLen int) error {
+	requestLen := -1
+	if r.MatchType == MatchExact {
+		pattern, _ := hex.DecodeString(r.Pattern)
+		requestLen = len(pattern)
+	}
+	for _, cf := range r.CopyFields {
+		if requestLen >= 0 && cf.From+cf.Length > requestLen {
+			return fmt.Errorf("복사 설정 %+v: %d바이트 요청에서 %d~%d 구간을 읽을 수 없습니다", cf, requestLen, cf.From, cf.From+cf.Length)
+		}
+		if responseLen >= 0 && cf.To > responseLen {
+			return fmt.Errorf("복사 설정 %+v: 응답 오프셋 %d가 응답 길이 %d를 넘습니다", cf, cf.To, responseLen)
+		}
+	}
+	for _, v := range r.SetVars {
+		if requestLen >= 0 && v.Value == "" && v.Offset+v.Length > requestLen {
+			return fmt.Errorf("변수 %s: %d바이트 요청에서 %d~%d 구간을 읽을 수 없습니다", v.Name, requestLen, v.Offset, v.Offset+v.Length)
+		}
+	}
+	for _, v := range r.UseVars {
+		if responseLen >= 0 && v.Offset > responseLen {
+			return fmt.Errorf("변수 %s: 응답 오프셋 %d가 응답 길이 %d를 넘습니다", v.Name, v.Offset, responseLen)
+		}
+	}
+	return nil
+}
+
+// Matches는 요청 페이로드가 규칙과 일치하는지 확인합니다.
+// field 매칭에는 FieldPacketID 패킷의 데이터 정의(fields)가 필요합니다.
+func (r MockRule) Matches(payload []byte, fields PacketData) bool {
+	switch r.MatchType {
+	case "", MatchAny:
+		return true
+
+	case MatchBytes:
+		pattern, _ := hex.DecodeString(r.Pattern)
+		if r.Offset+len(pattern) > len(payload) {
+			return false
+		}
+		return bytes.Equal(payload[r.Offset:r.Offset+len(pattern)], pattern)
+
+	case MatchExact:
+		pattern, _ := hex.DecodeString(r.Pattern)
+		return bytes.Equal(payload, pattern)
+
+	case MatchMask:
+		pattern, _ := hex.DecodeString(r.Pattern)
+		mask, _ := hex.DecodeString(r.Mask)
+		if len(mask) != len(pattern) || r.Offset+len(pattern) > len(payload) {
+			return false
+		}
+		for i := range pattern {
+			if payload[r.Offset+i]&mask[i] != pattern[i]&mask[i] {
+				return false
+			}
+		}
+		return true
+
+	case MatchField:
+		dt, length, ok := fields.Field(r.FieldOffset)
+		if !ok || r.FieldOffset+length > len(payload) {
+			return false
+		}
+		value, err := dt.Decode(payload[r.FieldOffset : r.FieldOffset+length])
+		return err == nil && value == r.FieldValue
+	}
+	return false
+}
+
+// Apply는 요청의 지정된 구간과 연결별 변수를 응답에 씁니다. 응답이 짧으면 늘립니다.
+func (r MockRule) Apply(request, response []byte, vars map[string][]byte) []byte {
+	out := append([]byte(nil), response...)
+	put := func(at int, value []byte) {
+		if need := at + len(value); need > len(out) {
+			out = append(out, make([]byte, need-len(out))...)
+		}
+		copy(out[at:], value)
+	}
+	for _, cf := range r.CopyFields {
+		if cf.From+cf.Length > len(request) {
+			continue
+		}
+		put(cf.To, request[cf.From:cf.From+cf.Length])
+	}
+	return r.UseVars.Apply(out, vars)
+}
+
+// Delay는 응답 전 대기 시간을 반환합니다.
+func (r MockRule) Delay() time.Duration {
+	return time.Duration(r.DelayMs) * time.Millisecond
+}
+
+// Value는 GORM을 위한 driver.Value 인터페이스를 구현합니다.
+func (c CopyFields) Value() (driver.Value, error) {
+	if c == nil {
+		return nil, nil
+	}
+	return json.Marshal(c)
+}
+
+// Scan은 GORM을 위한 sql.Scanner 인터페이스를 구현합니다.
+func (c *CopyFields) Scan(value interface{}) error {
+	var bytes []byte
+	switch v := value.(type) {
+	case nil:
+		*c = nil
+		return nil
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return errors.New("복사 설정을 스캔할 수 없음")
+	}
+
+	if len(bytes) == 0 {
+		*c = nil
+		return nil
+	}
+	return json.Unmarshal(bytes, c)
+}
+
+// ValidateCapture는 변수 저장 설정에 이름과 HEX 값 또는 읽을 구간이 있는지 검증합니다.
+func (b VarBindings) ValidateCapture() error {
+	for _, v := range b {
+		if v.Name == "" {
+			return errors.New("변수 이름이 필요합니다")
+		}
+		if v.Value != "" {
+			if _, err := hex.DecodeString(v.Value); err != nil {
+				return fmt.Errorf("변수 %s의 값은 HEX 문자열이어야 합니다", v.Name)
+			}
+		} else if v.Offset < 0 || v.Length <= 0 {
+			return fmt.Errorf("변수 %s의 읽을 구간이 잘못되었습니다", v.Name)
+		}
+	}
+	return nil
+}
+
+// Capture는 Value(HEX)가 있으면 그 값을, 없으면 src의 Offset부터 Length 바이트를 변수에 저장합니다.
+// src를 벗어나는 변수는 건너뛰고 그 중 첫 번째에 대한 오류를 반환합니다.
+func (b VarBindings) Capture(src []byte, vars map[string][]byte) error {
+	var err error
+	for _, v := range b {
+		if v.Value != "" {
+			vars[v.Name], _ = hex.DecodeString(v.Value)
+			continue
+		}
+		if v.Offset+v.Length > len(src) {
+			if err == nil {
+				err = fmt.Errorf("변수 %s: %d바이트 데이터에서 %d~%d 구간을 읽을 수 없습니다", v.Name, len(src), v.Offset, v.Offset+v.Length)
+			}
+			continue
+		}
+		vars[v.Name] = append([]byte(nil), src[v.Offset:v.Offset+v.Length]...)
+	}
+	return err
+}
+
+// Apply는 변수 값을 dst의 Offset 위치에 쓴 사본을 반환합니다. Length가 있으면 그 길이로 자르거나
+// 0으로 채우고, dst가 짧으면 늘립니다. 정의되지 않은 변수는 건너뜁니다.
+func (b VarBindings) Apply(dst []byte, vars map[string][]byte) []byte {
+	out := append([]byte(nil), dst...)
+	for _, v := range b {
+		value, ok := vars[v.Name]
+		if !ok {
+			continue
+		}
+		if v.Length > 0 {
+			fixed := make([]byte, v.Length)
+			copy(fixed, value)
+			value = fixed
+		}
+		if need := v.Offset + len(value); need > len(out) {
+			out = append(out, make([]byte, need-len(out))...)
+		}
+		copy(out[v.Offset:], value)
+	}
+	return out
+}
+
+// Value는 GORM을 위한 driver.Value 인터페이스를 구현합니다.
+func (b VarBindings) Value() (driver.Value, error) {
+	if b == nil {
+		return nil, nil
+	}
+	return json.Marshal(b)
+}
+
+// Scan은 GORM을 위한 sql.Scanner 인터페이스를 구현합니다.
+func (b *VarBindings) Scan(value interface{}) error {
+	var bytes []byte
+	switch v := value.(type) {
+	case nil:
+		*b = nil
+		return nil
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return errors.New("변수 설정을 스캔할 수 없음")
+	}
+
+	if len(bytes) == 0 {
+		*b = nil
+		return nil
+	}
+	return json.Unmarshal(bytes, b)
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+
+	"github.com/fake-edge-server/utils"
+)
+
+// ModbusParams는 Modbus 패킷의 작업 파라미터입니다.
+// 읽기 함수는 Address/Quantity를, 쓰기 함수는 Address/Values를 사용합니다.
+type ModbusParams struct {
+	UnitID   uint8    `json:"unit_id"`
+	Function uint8    `json:"function"`
+	Address  uint16   `json:"address"`
+	Quantity uint16   `json:"quantity"`
+	Values   []uint16 `json:"values"`
+}
+
+// PDU는 파라미터를 검증하고 Modbus PDU를 생성합니다.
+func (m ModbusParams) PDU() ([]byte, error) {
+	return utils.BuildModbusPDU(m.Function, m.Address, m.Quantity, m.Values)
+}
+
+// Value는 GORM을 위한 driver.Value 인터페이스를 구현합니다.
+func (m ModbusParams) Value() (driver.Value, error) {
+	return json.Marshal(m)
+}
+
+// Scan은 GORM을 위한 sql.Scanner 인터페이스를 구현합니다.
+func (m *ModbusParams) Scan(value interface{}) error {
+	var bytes []byte
+	switch v := value.(type) {
+	case nil:
+		*m = ModbusParams{}
+		return nil
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return errors.New("Modbus 파라미터를 스캔할 수 없음")
+	}
+
+	if len(bytes) == 0 {
+		*m = ModbusParams{}
+		return nil
+	}
+	return json.Unmarshal(bytes, m)
+}
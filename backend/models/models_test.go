@@ -183,3 +183,243 @@ func TestModelValidation(t *testing.T) {
 	// 현재는 기본적인 검증만 수행 (실제 검증 로직이 있다면 에러 확인)
 	assert.NoError(t, result.Error)
 }
+
+func TestFrameProfileFormat(t *testing.T) {
+	// 빈 프로필은 기본 프레임을 사용
+	format, err := FrameProfile{}.Format()
+	assert.NoError(t, err)
+	assert.Equal(t, 12, format.HeaderSize())
+
+	format, err = FrameProfile{Magic: "eb90", Endian: "big", Checksum: "crc32c"}.Format()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0xEB, 0x90}, format.Magic)
+	assert.Equal(t, 10, format.HeaderSize())
+
+	_, err = FrameProfile{Magic: "zz"}.Format()
+	assert.Error(t, err)
+
+	_, err = FrameProfile{Endian: "middle"}.Format()
+	assert.Error(t, err)
+}
+
+func TestTCPServerFramingPersistence(t *testing.T) {
+	db := setupTestDB()
+
+	server := TCPServer{
+		Name:    "framed-server",
+		Host:    "127.0.0.1",
+		Port:    9000,
+		Framing: FrameProfile{Magic: "a55a", Checksum: "crc32c"},
+	}
+	assert.NoError(t, db.Create(&server).Error)
+
+	var retrieved TCPServer
+	assert.NoError(t, db.First(&retrieved, server.ID).Error)
+	assert.Equal(t, "a55a", retrieved.Framing.Magic)
+	assert.Equal(t, "crc32c", retrieved.Framing.Checksum)
+}
+
+func TestPacketDataFieldAndDecode(t *testing.T) {
+	data := PacketData{
+		{Offset: 0, Type: TypeUint8},
+		{Offset: 1, Type: TypeInt16, IsChained: true},
+		{Offset: 2, Type: TypeInt16, IsChained: true},
+		{Offset: 3, Type: TypeUint16, IsChained: true},
+		{Offset: 4, Type: TypeUint16, IsChained: true},
+	}
+	dt, length, ok := data.Field(1)
+	assert.True(t, ok)
+	assert.Equal(t, TypeInt16, dt)
+	assert.Equal(t, 2, length)
+
+	// 인접한 체인 필드는 타입 크기에서 끊김
+	_, length, _ = data.Field(3)
+	assert.Equal(t, 2, length)
+	_, _, ok = data.Field(9)
+	assert.False(t, ok)
+
+	value, err := TypeInt16.Decode([]byte{0xFE, 0xFF})
+	assert.NoError(t, err)
+	assert.Equal(t, "-2", value)
+	value, _ = TypeHex.Decode([]byte{0xAB})
+	assert.Equal(t, "ab", value)
+
+	fields := data.DecodeFields([]byte{0x07, 0xFE, 0xFF, 0x34, 0x12})
+	assert.Equal(t, []DecodedField{
+		{Offset: 0, Length: 1, Type: TypeUint8, Value: "7"},
+		{Offset: 1, Length: 2, Type: TypeInt16, Value: "-2"},
+		{Offset: 3, Length: 2, Type: TypeUint16, Value: "4660"},
+	}, fields)
+	assert.Len(t, data.DecodeFields([]byte{0x07, 0xFE}), 1)
+
+	packet := TCPPacket{Data: PacketData{{Offset: 0, Value: 0x07}, {Offset: 4}}}
+	assert.True(t, packet.Identifies([]byte{0x07, 0, 0, 0, 0}))
+	assert.False(t, packet.Identifies([]byte{0x08, 0, 0, 0, 0}))
+	assert.False(t, packet.Identifies([]byte{0x07, 0}))
+	packet.Kind = PacketKindModbus
+	assert.False(t, packet.Identifies([]byte{0x07, 0, 0, 0, 0}))
+}
+
+func TestMockRuleMatchesAndApply(t *testing.T) {
+	payload := []byte{0x10, 0x34, 0x12, 0x7F}
+
+	assert.True(t, MockRule{MatchType: MatchBytes, Offset: 1, Pattern: "3412"}.Matches(payload, nil))
+	assert.False(t, MockRule{MatchType: MatchBytes, Offset: 3, Pattern: "7f00"}.Matches(payload, nil))
+	assert.True(t, MockRule{MatchType: MatchMask, Pattern: "1f", Mask: "f0"}.Matches(payload, nil))
+	assert.False(t, MockRule{MatchType: MatchMask, Pattern: "20", Mask: "f0"}.Matches(payload, nil))
+	assert.True(t, MockRule{MatchType: MatchExact, Pattern: "1034127f"}.Matches(payload, nil))
+	assert.False(t, MockRule{MatchType: MatchExact, Pattern: "103412"}.Matches(payload, nil))
+
+	assert.NoError(t, MockRule{MatchType: MatchExact, Pattern: "01", ResponseHex: "11"}.Validate())
+	assert.Error(t, MockRule{MatchType: MatchExact, Pattern: "01", ResponseHex: "11", ResponsePacketID: 1}.Validate())
+	assert.Error(t, MockRule{MatchType: MatchExact, Pattern: "zz", NoResponse: true}.Validate())
+
+	defs := PacketData{{Offset: 1, Type: TypeUint16, IsChained: true}, {Offset: 2, Type: TypeUint16, IsChained: true}}
+	assert.True(t, MockRule{MatchType: MatchField, FieldOffset: 1, FieldValue: "4660"}.Matches(payload, defs))
+	assert.False(t, MockRule{MatchType: MatchField, FieldOffset: 1, FieldValue: "1"}.Matches(payload, defs))
+
+	rule := MockRule{CopyFields: CopyFields{{From: 1, To: 2, Length: 2}}}
+	assert.Equal(t, []byte{0xAA, 0x00, 0x34, 0x12}, rule.Apply(payload, []byte{0xAA}, nil))
+}
+
+func TestMockRuleVars(t *testing.T) {
+	vars := map[string][]byte{}
+	rule := MockRule{
+		SetVars: VarBindings{{Name: "seq", Offset: 1, Length: 2}, {Name: "mode", Value: "07"}},
+		UseVars: VarBindings{{Name: "seq", Offset: 2}, {Name: "mode", Offset: 0, Length: 1}, {Name: "missing", Offset: 5}},
+	}
+	assert.NoError(t, MockRule{SetVars: rule.SetVars, NoResponse: true}.Validate())
+	rule.Capture([]byte{0x01, 0x34, 0x12}, vars)
+	assert.Equal(t, []byte{0x34, 0x12}, vars["seq"])
+	assert.Equal(t, []byte{0x07}, vars["mode"])
+	assert.Equal(t, []byte{0x07, 0xBB, 0x34, 0x12}, rule.Apply(nil, []byte{0xAA, 0xBB}, vars))
+
+	assert.Error(t, MockRule{SetVars: VarBindings{{Name: "x"}}, NoResponse: true}.Validate())
+
+	// 요청을 벗어나는 변수는 오류를 반환하고 이전 값을 남기지 않음
+	assert.Error(t, rule.Capture([]byte{0x01}, vars))
+	assert.NotContains(t, vars, "seq")
+	assert.Equal(t, []byte{0x07}, vars["mode"])
+
+	// 응답에 쓰는 위치는 응답 끝까지, 읽는 구간은 exact 매칭의 요청 길이까지 허용
+	assert.NoError(t, MockRule{CopyFields: CopyFields{{From: 0, To: 2, Length: 4}}}.CheckBounds(2))
+	assert.Error(t, MockRule{CopyFields: CopyFields{{From: 0, To: 3, Length: 1}}}.CheckBounds(2))
+	assert.Error(t, MockRule{MatchType: MatchExact, Pattern: "0102", CopyFields: CopyFields{{From: 1, To: 0, Length: 2}}}.CheckBounds(2))
+	assert.Error(t, MockRule{MatchType: MatchExact, Pattern: "01", SetVars: VarBindings{{Name: "s", Offset: 1, Length: 1}}}.CheckBounds(-1))
+	assert.Error(t, MockRule{UseVars: VarBindings{{Name: "s", Offset: 3}}}.CheckBounds(2))
+	assert.NoError(t, MockRule{UseVars: VarBindings{{Name: "s", Offset: 3}}}.CheckBounds(-1))
+	assert.True(t, StateList{"a", "b"}.Has("b"))
+	assert.False(t, StateList{"a"}.Has("c"))
+	assert.False(t, StateList{"a"}.Has(""))
+	assert.False(t, StateList(nil).Has("c"))
+
+	// 빈 이름은 항상 허용하고, 상태 목록이 있으면 그 안의 이름만 허용
+	assert.NoError(t, StateList{"a"}.CheckState(""))
+	assert.NoError(t, StateList{"a"}.CheckState("a"))
+	assert.Error(t, StateList{"a"}.CheckState("c"))
+	assert.NoError(t, StateList(nil).CheckState("c"))
+	assert.NoError(t, StateList{"a", "b"}.Validate())
+	assert.Error(t, StateList{"a", ""}.Validate())
+	assert.Error(t, StateList{"a", "a"}.Validate())
+}
+
+func TestScenarioCheckEval(t *testing.T) {
+	vars := map[string][]byte{"limit": {0x10, 0x00}}
+	subject := []byte{0x01, 0x20, 0x00, 'o', 'k'}
+
+	actual, ok, err := ScenarioCheck{Offset: 1, Type: TypeUint16, Op: OpGt, Value: "${limit}"}.Eval(subject, vars)
+	assert.NoError(t, err)
+	assert.Equal(t, "32", actual)
+	assert.True(t, ok)
+
+	// 숫자로 해석되지 않으면 문자열로 비교
+	_, ok, err = ScenarioCheck{Offset: 3, Type: TypeString, Op: OpContains, Value: "k"}.Eval(subject, vars)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	_, _, err = ScenarioCheck{Offset: 4, Type: TypeUint16, Op: OpEq}.Eval(subject, vars)
+	assert.Error(t, err)
+	_, _, err = ScenarioCheck{Type: TypeUint8, Op: OpEq, Value: "${missing}"}.Eval(subject, vars)
+	assert.ErrorContains(t, err, "정의되지 않은 변수")
+}
+
+func TestScenarioStepsValidate(t *testing.T) {
+	steps := ScenarioSteps{
+		{Name: "read", Type: StepSend, PacketID: 1},
+		{Type: StepLoop, Target: "read", Count: 3},
+		{Type: StepBranch, Target: "read", Condition: &ScenarioCheck{Op: OpEq}},
+	}
+	assert.NoError(t, steps.Validate())
+	assert.Equal(t, 0, steps.Index("read"))
+	assert.Equal(t, -1, steps.Index("missing"))
+
+	steps[1].Target = "missing"
+	assert.ErrorContains(t, steps.Validate(), "2번 단계")
+	steps[1].Target = "read"
+	steps[2].Else = "missing"
+	assert.ErrorContains(t, steps.Validate(), "else 단계를 찾을 수 없습니다")
+}
+
+func TestScheduleValidate(t *testing.T) {
+	schedule := Schedule{Cron: "0 2 * * 1-5", Timezone: "Asia/Seoul", Target: ScheduleTargetScenario, ScenarioID: 1}
+	assert.NoError(t, schedule.Validate())
+	cron, err := schedule.CronSchedule()
+	assert.NoError(t, err)
+	// 2026-10-16(금) 03:00 KST 다음은 월요일 02:00 KST
+	seoul, _ := time.LoadLocation("Asia/Seoul")
+	next := cron.Next(time.Date(2026, 10, 16, 3, 0, 0, 0, seoul))
+	assert.Equal(t, time.Date(2026, 10, 19, 2, 0, 0, 0, seoul), next.In(seoul))
+
+	schedule.PacketID = 2
+	assert.ErrorContains(t, schedule.Validate(), "scenario_id만")
+	schedule.PacketID = 0
+	schedule.AllServers, schedule.TCPServerID = true, 1
+	assert.ErrorContains(t, schedule.Validate(), "all_servers")
+	schedule.Target = "mock"
+	assert.ErrorContains(t, schedule.Validate(), "target")
+	schedule.Timezone = "Nowhere/City"
+	assert.Error(t, schedule.Validate())
+}
+
+func TestSendJobSpecValidate(t *testing.T) {
+	assert.NoError(t, SendJobSpec{}.Validate())
+	assert.NoError(t, SendJobSpec{IntervalMs: 500, Count: 1000, DurationMs: 600000, JitterPct: 20, Burst: 20}.Validate())
+	assert.ErrorContains(t, SendJobSpec{Count: 10}.Validate(), "interval_ms와 함께")
+	assert.ErrorContains(t, SendJobSpec{IntervalMs: 500, JitterPct: 101}.Validate(), "jitter_pct")
+	assert.Error(t, SendJobSpec{IntervalMs: 500, Burst: MaxJobBurst + 1}.Validate())
+	assert.Error(t, SendJobSpec{IntervalMs: -1}.Validate())
+	assert.True(t, SendJobSpec{IntervalMs: 500}.Repeat())
+	assert.False(t, SendJobSpec{}.Repeat())
+}
+
+func TestReconnectPolicy(t *testing.T) {
+	assert.False(t, ReconnectPolicy{}.Enabled())
+	assert.False(t, ReconnectPolicy{Mode: ReconnectDisabled}.Enabled())
+	assert.NoError(t, ReconnectPolicy{}.Validate())
+	assert.NoError(t, ReconnectPolicy{Mode: ReconnectBackoff, MaxAttempts: 5, InitialMs: 500, MaxMs: 10000}.Validate())
+	assert.ErrorContains(t, ReconnectPolicy{Mode: "forever"}.Validate(), "재연결 방식")
+	assert.Error(t, ReconnectPolicy{Mode: ReconnectBackoff, MaxAttempts: -1}.Validate())
+	assert.Error(t, ReconnectPolicy{Mode: ReconnectBackoff, InitialMs: 2000, MaxMs: 1000}.Validate())
+
+	// 지수 백오프는 max_ms에서 멈춤
+	backoff := ReconnectPolicy{Mode: ReconnectBackoff, InitialMs: 100, MaxMs: 1000}
+	var delays []time.Duration
+	for attempt := 1; attempt <= 6; attempt++ {
+		delays = append(delays, backoff.Delay(attempt))
+	}
+	assert.Equal(t, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second}, delays)
+	assert.Equal(t, 30*time.Second, ReconnectPolicy{Mode: ReconnectBackoff}.Delay(100))
+
+	// immediate는 첫 시도만 바로 하고 이후 initial_ms 간격
+	immediate := ReconnectPolicy{Mode: ReconnectImmediate, InitialMs: 200}
+	assert.Zero(t, immediate.Delay(1))
+	assert.Equal(t, 200*time.Millisecond, immediate.Delay(2))
+	assert.Equal(t, 200*time.Millisecond, immediate.Delay(9))
+
+	db := setupTestDB()
+	server := TCPServer{Name: "reconnecting", Host: "127.0.0.1", Port: 9000, Reconnect: backoff}
+	assert.NoError(t, db.Create(&server).Error)
+	var retrieved TCPServer
+	assert.NoError(t, db.First(&retrieved, server.ID).Error)
+	assert.Equal(t, backoff, retrieved.Reconnect)
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+)
+
+// 프록시 종류
+const (
+	ProxySOCKS5 = "socks5"
+	ProxyHTTP   = "http" // HTTP CONNECT 터널
+)
+
+// ProxySettings는 대상 서버에 접속할 때 거치는 프록시 설정입니다.
+// Type이 비어 있으면 직접 접속합니다.
+type ProxySettings struct {
+	Type     string `json:"type"`    // socks5 | http
+	Address  string `json:"address"` // 프록시 host:port
+	Username string `json:"username"`
+	Password string `json:"password"` // 요청으로만 받고 응답에서는 비움
+}
+
+// Enabled는 프록시를 사용하는지 확인합니다.
+func (p ProxySettings) Enabled() bool {
+	return p.Type != ""
+}
+
+// Validate는 프록시 종류와 주소를 검증합니다.
+func (p ProxySettings) Validate() error {
+	if !p.Enabled() {
+		return nil
+	}
+	if p.Type != ProxySOCKS5 && p.Type != ProxyHTTP {
+		return fmt.Errorf("지원되지 않는 프록시 종류: %s", p.Type)
+	}
+	if _, _, err := net.SplitHostPort(p.Address); err != nil {
+		return fmt.Errorf("프록시 주소 오류: %v", err)
+	}
+	if p.Type == ProxySOCKS5 && (len(p.Username) > 255 || len(p.Password) > 255) {
+		return errors.New("SOCKS5 사용자 이름과 비밀번호는 255바이트 이하여야 합니다")
+	}
+	return nil
+}
+
+// Redacted는 비밀번호를 비운 설정을 반환합니다. 응답에는 비밀번호를 내보내지 않습니다.
+func (p ProxySettings) Redacted() ProxySettings {
+	p.Password = ""
+	return p
+}
+
+// Value는 GORM을 위한 driver.Value 인터페이스를 구현합니다.
+func (p ProxySettings) Value() (driver.Value, error) {
+	return json.Marshal(p)
+}
+
+// Scan은 GORM을 위한 sql.Scanner 인터페이스를 구현합니다.
+func (p *ProxySettings) Scan(value interface{}) error {
+	var bytes []byte
+	switch v := value.(type) {
+	case nil:
+		*p = ProxySettings{}
+		return nil
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return errors.New("프록시 설정을 스캔할 수 없음")
+	}
+
+	if len(bytes) == 0 {
+		*p = ProxySettings{}
+		return nil
+	}
+	return json.Unmarshal(bytes, p)
+}
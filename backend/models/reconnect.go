@@ -0,0 +1,103 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// 연결이 끊어졌을 때의 재연결 방식
+const (
+	ReconnectDisabled  = "disabled"  // 재연결하지 않고 Dead로 표시 (기본값)
+	ReconnectImmediate = "immediate" // 바로 재연결하고, 실패하면 initial_ms 간격으로 재시도
+	ReconnectBackoff   = "backoff"   // initial_ms부터 두 배씩 늘려 max_ms까지 기다린 뒤 재시도
+)
+
+// 재연결 대기 시간 기본값
+const (
+	defaultReconnectInitial = time.Second
+	defaultReconnectMax     = 30 * time.Second
+)
+
+// ReconnectPolicy는 서버 연결이 끊어졌을 때 다시 접속하는 정책입니다.
+// Mode가 비어 있으면 재연결하지 않습니다.
+type ReconnectPolicy struct {
+	Mode        string `json:"mode"`         // disabled | immediate | backoff
+	MaxAttempts int    `json:"max_attempts"` // 최대 시도 횟수 (0이면 무제한)
+	InitialMs   int    `json:"initial_ms"`   // 첫 대기 시간 (0이면 1초)
+	MaxMs       int    `json:"max_ms"`       // backoff 대기 시간 상한 (0이면 30초)
+}
+
+// Enabled는 재연결을 사용하는지 확인합니다.
+func (p ReconnectPolicy) Enabled() bool {
+	return p.Mode != "" && p.Mode != ReconnectDisabled
+}
+
+// Validate는 재연결 방식과 대기 시간을 검증합니다.
+func (p ReconnectPolicy) Validate() error {
+	switch p.Mode {
+	case "", ReconnectDisabled, ReconnectImmediate, ReconnectBackoff:
+	default:
+		return fmt.Errorf("지원되지 않는 재연결 방식: %s", p.Mode)
+	}
+	if p.MaxAttempts < 0 || p.InitialMs < 0 || p.MaxMs < 0 {
+		return errors.New("max_attempts, initial_ms, max_ms는 0 이상이어야 합니다")
+	}
+	if p.MaxMs > 0 && p.InitialMs > p.MaxMs {
+		return errors.New("initial_ms는 max_ms보다 클 수 없습니다")
+	}
+	return nil
+}
+
+// Delay는 attempt번째(1부터) 재연결 시도 전에 기다릴 시간을 반환합니다.
+func (p ReconnectPolicy) Delay(attempt int) time.Duration {
+	initial := time.Duration(p.InitialMs) * time.Millisecond
+	if initial == 0 {
+		initial = defaultReconnectInitial
+	}
+	if p.Mode == ReconnectImmediate {
+		if attempt <= 1 {
+			return 0
+		}
+		return initial
+	}
+
+	limit := time.Duration(p.MaxMs) * time.Millisecond
+	if limit == 0 {
+		limit = max(defaultReconnectMax, initial)
+	}
+	delay := initial
+	for i := 1; i < attempt && delay < limit; i++ {
+		delay *= 2
+	}
+	return min(delay, limit)
+}
+
+// Value는 GORM을 위한 driver.Value 인터페이스를 구현합니다.
+func (p ReconnectPolicy) Value() (driver.Value, error) {
+	return json.Marshal(p)
+}
+
+// Scan은 GORM을 위한 sql.Scanner 인터페이스를 구현합니다.
+func (p *ReconnectPolicy) Scan(value interface{}) error {
+	var bytes []byte
+	switch v := value.(type) {
+	case nil:
+		*p = ReconnectPolicy{}
+		return nil
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return errors.New("재연결 정책을 스캔할 수 없음")
+	}
+
+	if len(bytes) == 0 {
+		*p = ReconnectPolicy{}
+		return nil
+	}
+	return json.Unmarshal(bytes, p)
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Recording은 대상 서버와 주고받은 요청/응답을 시간 정보와 함께 기록한 세션입니다.
+// StoppedAt이 비어 있으면 기록 중입니다.
+type Recording struct {
+	ID          uint             `json:"id" gorm:"primaryKey"`
+	TCPServerID uint             `json:"tcp_server_id" gorm:"index"`
+	Name        string           `json:"name"`
+	StartedAt   time.Time        `json:"started_at"`
+	StoppedAt   *time.Time       `json:"stopped_at"`
+	Entries     []RecordingEntry `json:"entries,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt   `json:"deleted_at" gorm:"index"`
+}
+
+// RecordingEntry는 기록된 요청/응답 한 쌍입니다.
+type RecordingEntry struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	RecordingID uint      `json:"recording_id" gorm:"index"`
+	Seq         int       `json:"seq"`
+	TCPPacketID uint      `json:"tcp_packet_id"`
+	Kind        string    `json:"kind"`                      // 패킷 종류 (raw | edge | modbus)
+	Request     string    `json:"request" gorm:"type:text"`  // HEX
+	Response    string    `json:"response" gorm:"type:text"` // HEX, 응답이 없으면 빈 문자열
+	OffsetMs    int64     `json:"offset_ms"`                 // 기록 시작부터 요청까지의 시간
+	LatencyMs   int64     `json:"latency_ms"`                // 요청부터 응답까지의 시간
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ReplayRequest는 기록으로 목 엔드포인트를 생성하는 요청입니다.
+type ReplayRequest struct {
+	Name     string  `json:"name" binding:"required"`
+	BindAddr string  `json:"bind_address"`
+	Port     int     `json:"port"`
+	UseCRC   bool    `json:"use_crc"`
+	Speed    float64 `json:"speed"` // 응답 지연 배속, 0이면 1(원래 속도)
+	// 기록에 없는 요청에 보낼 응답입니다. 둘 다 비어 있으면 응답하지 않습니다.
+	FallbackHex      string `json:"fallback_hex"`
+	FallbackPacketID uint   `json:"fallback_packet_id"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 중계 이력의 프레임 방향
+const (
+	DirectionClientToServer = "client_to_server" // 클라이언트 → 대상 서버
+	DirectionServerToClient = "server_to_client" // 대상 서버 → 클라이언트
+)
+
+// Relay는 로컬 포트에서 클라이언트를 받아 TCP 서버로 중계하는 투명 프록시입니다.
+// 중계한 프레임은 양방향 모두 이력에 RelayID와 함께 저장됩니다.
+type Relay struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	Name        string         `json:"name" gorm:"uniqueIndex"`
+	BindAddr    string         `json:"bind_address"` // 비어 있으면 모든 인터페이스
+	Port        int            `json:"port"`         // 0이면 임의의 포트
+	TCPServerID uint           `json:"tcp_server_id" gorm:"index"`
+	UseCRC      bool           `json:"use_crc"`                 // 서버 프레임 설정으로 프레임을 나누어 기록
+	Faults      FaultProfile   `json:"faults" gorm:"type:text"` // 전달하는 프레임에 주입할 결함
+	Running     bool           `json:"running" gorm:"-"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+// RelayRequest는 중계 생성/수정 요청 구조체입니다.
+type RelayRequest struct {
+	Name        string       `json:"name" binding:"required"`
+	BindAddr    string       `json:"bind_address"`
+	Port        int          `json:"port"`
+	TCPServerID uint         `json:"tcp_server_id" binding:"required"`
+	UseCRC      bool         `json:"use_crc"`
+	Faults      FaultProfile `json:"faults"`
+}
@@ -0,0 +1,441 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 시나리오 단계 종류
+const (
+	StepSend    = "send"    // 패킷 전송 (UseVars로 변수 값을 데이터에 씀)
+	StepExpect  = "expect"  // 응답 한 프레임을 기다리고 검증
+	StepWait    = "wait"    // 지정한 시간만큼 대기
+	StepExtract = "extract" // 마지막 응답의 구간(또는 HEX 값)을 변수에 저장
+	StepLoop    = "loop"    // Target 단계로 돌아가 Count번까지 반복
+	StepBranch  = "branch"  // 조건에 따라 Target 또는 Else 단계로 이동
+)
+
+// 시나리오 검사 연산자
+const (
+	OpEq       = "eq"
+	OpNe       = "ne"
+	OpGt       = "gt"
+	OpGe       = "ge"
+	OpLt       = "lt"
+	OpLe       = "le"
+	OpContains = "contains"
+)
+
+// 시나리오 실행 상태
+const (
+	ScenarioRunning = "running"
+	ScenarioPassed  = "passed"
+	ScenarioFailed  = "failed"
+	ScenarioStopped = "stopped"
+)
+
+// 시나리오 단계 결과
+const (
+	StepPassed = "passed"
+	StepFailed = "failed"
+)
+
+// Scenario는 하나의 연결에서 순서대로 실행하는 단계 목록입니다.
+// 예: 로그인 → 토큰 추출 → 설정 읽기 → 설정 쓰기 → 확인
+type Scenario struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	Name        string         `json:"name" gorm:"uniqueIndex"`
+	Desc        string         `json:"desc"`
+	TCPServerID uint           `json:"tcp_server_id"` // 실행 요청에 서버가 없을 때 사용할 기본 서버
+	Vars        ScenarioVars   `json:"vars" gorm:"type:text"`
+	Steps       ScenarioSteps  `json:"steps" gorm:"type:text"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+// ScenarioRequest는 시나리오 생성/수정 요청 구조체입니다.
+type ScenarioRequest struct {
+	Name        string        `json:"name" binding:"required"`
+	Desc        string        `json:"desc"`
+	TCPServerID uint          `json:"tcp_server_id"`
+	Vars        ScenarioVars  `json:"vars"`
+	Steps       ScenarioSteps `json:"steps"`
+}
+
+// ScenarioRunRequest는 시나리오 실행 요청 구조체입니다.
+type ScenarioRunRequest struct {
+	TCPServerID uint         `json:"tcp_server_id"` // 비어 있으면 시나리오의 기본 서버
+	Vars        ScenarioVars `json:"vars"`          // 시나리오 초기 변수를 덮어씀
+}
+
+// ScenarioCheck는 마지막 응답 또는 변수의 구간을 Type으로 해석해 Value와 비교합니다.
+// Value의 ${이름}은 같은 타입으로 해석한 변수 값으로 바뀝니다.
+type ScenarioCheck struct {
+	Var    string   `json:"var"` // 비어 있으면 마지막 응답을 검사
+	Offset int      `json:"offset"`
+	Length int      `json:"length"` // 0이면 타입 크기, 가변 길이 타입은 끝까지
+	Type   DataType `json:"type"`
+	Op     string   `json:"op"` // eq | ne | gt | ge | lt | le | contains
+	Value  string   `json:"value"`
+}
+
+// ScenarioStep은 시나리오의 한 단계입니다. Type에 따라 사용하는 필드가 다릅니다.
+type ScenarioStep struct {
+	Name       string          `json:"name"` // loop/branch의 이동 대상으로 쓰는 이름
+	Type       string          `json:"type"`
+	PacketID   uint            `json:"packet_id"`  // send
+	UseVars    VarBindings     `json:"use_vars"`   // send: 변수 값을 패킷 데이터의 Offset 위치에 씀
+	TimeoutMs  int             `json:"timeout_ms"` // expect: 0이면 서버의 응답 대기 시간
+	Assertions []ScenarioCheck `json:"assertions"` // expect
+	DelayMs    int             `json:"delay_ms"`   // wait
+	Extract    VarBindings     `json:"extract"`    // extract
+	Target     string          `json:"target"`     // loop: 돌아갈 단계, branch: 조건이 참일 때 이동할 단계
+	Else       string          `json:"else"`       // branch: 조건이 거짓일 때 이동할 단계, 비어 있으면 다음 단계
+	Count      int             `json:"count"`      // loop: 최대 반복 횟수
+	Condition  *ScenarioCheck  `json:"condition"`  // branch 조건, loop 종료(until) 조건
+}
+
+// ScenarioSteps는 시나리오 단계의 배열입니다.
+type ScenarioSteps []ScenarioStep
+
+// ScenarioVars는 변수 이름과 HEX 값의 맵입니다.
+type ScenarioVars map[string]string
+
+// ScenarioStepResult는 실행한 단계 하나의 결과입니다. 반복과 분기로 같은 단계가 여러 번 기록될 수 있습니다.
+type ScenarioStepResult struct {
+	Index      int          `json:"index"`
+	Name       string       `json:"name"`
+	Type       string       `json:"type"`
+	Status     string       `json:"status"`
+	Message    string       `json:"message"`
+	Request    string       `json:"request,omitempty"`  // send: 보낸 데이터 (HEX)
+	Response   string       `json:"response,omitempty"` // expect: 받은 데이터 (HEX)
+	Vars       ScenarioVars `json:"vars,omitempty"`     // extract: 저장한 변수
+	DurationMs int64        `json:"duration_ms"`
+	At         time.Time    `json:"at"`
+}
+
+// ScenarioLog는 단계 결과의 배열입니다.
+type ScenarioLog []ScenarioStepResult
+
+// ScenarioRun은 시나리오 한 번의 실행과 단계별 결과입니다.
+type ScenarioRun struct {
+	ID          uint         `json:"id" gorm:"primaryKey"`
+	ScenarioID  uint         `json:"scenario_id" gorm:"index"`
+	TCPServerID uint         `json:"tcp_server_id"`
+	Status      string       `json:"status"`
+	Error       string       `json:"error"`
+	Vars        ScenarioVars `json:"vars" gorm:"type:text"` // 종료 시점의 변수
+	Log         ScenarioLog  `json:"log" gorm:"type:text"`
+	StartedAt   *time.Time   `json:"started_at"`
+	FinishedAt  *time.Time   `json:"finished_at"`
+	CreatedAt   time.Time    `json:"created_at"`
+}
+
+var checkOps = map[string]bool{OpEq: true, OpNe: true, OpGt: true, OpGe: true, OpLt: true, OpLe: true, OpContains: true}
+
+// Validate는 검사 설정을 검증합니다.
+func (c ScenarioCheck) Validate() error {
+	if !checkOps[c.Op] {
+		return fmt.Errorf("지원되지 않는 연산자: %s", c.Op)
+	}
+	if c.Type < TypeInt8 || c.Type > TypeJSON {
+		return fmt.Errorf("지원되지 않는 데이터 타입: %d", c.Type)
+	}
+	if c.Offset < 0 || c.Length < 0 {
+		return errors.New("검사 구간은 0 이상이어야 합니다")
+	}
+	return nil
+}
+
+var varRef = regexp.MustCompile(`\$\{(\w+)\}`)
+
+// Eval은 subject의 구간을 해석해 기대값과 비교하고, 해석한 실제 값을 함께 반환합니다.
+func (c ScenarioCheck) Eval(subject []byte, vars map[string][]byte) (string, bool, error) {
+	end := c.Offset + c.Length
+	if c.Length == 0 {
+		end = len(subject)
+		if size := c.Type.Size(); size > 0 {
+			end = c.Offset + size
+		}
+	}
+	if c.Offset > len(subject) || end > len(subject) {
+		return "", false, fmt.Errorf("%d바이트 데이터에서 %d~%d 구간을 읽을 수 없습니다", len(subject), c.Offset, end)
+	}
+	actual, err := c.Type.Decode(subject[c.Offset:end])
+	if err != nil {
+		return "", false, err
+	}
+
+	var missing string
+	expected := varRef.ReplaceAllStringFunc(c.Value, func(ref string) string {
+		name := varRef.FindStringSubmatch(ref)[1]
+		value, ok := vars[name]
+		if !ok {
+			missing = name
+			return ref
+		}
+		if decoded, err := c.Type.Decode(value); err == nil {
+			return decoded
+		}
+		return hex.EncodeToString(value)
+	})
+	if missing != "" {
+		return actual, false, fmt.Errorf("정의되지 않은 변수: %s", missing)
+	}
+	return actual, compare(actual, c.Op, expected), nil
+}
+
+// compare는 두 값이 모두 숫자면 숫자로, 아니면 문자열로 비교합니다.
+func compare(actual, op, expected string) bool {
+	if op == OpContains {
+		return strings.Contains(actual, expected)
+	}
+	a, errA := strconv.ParseFloat(actual, 64)
+	e, errE := strconv.ParseFloat(expected, 64)
+	cmp := strings.Compare(actual, expected)
+	if errA == nil && errE == nil {
+		switch {
+		case a < e:
+			cmp = -1
+		case a > e:
+			cmp = 1
+		default:
+			cmp = 0
+		}
+	}
+	switch op {
+	case OpEq:
+		return cmp == 0
+	case OpNe:
+		return cmp != 0
+	case OpGt:
+		return cmp > 0
+	case OpGe:
+		return cmp >= 0
+	case OpLt:
+		return cmp < 0
+	case OpLe:
+		return cmp <= 0
+	}
+	return false
+}
+
+// Index는 이름이 name인 단계의 위치를 반환합니다. 없으면 -1입니다.
+func (s ScenarioSteps) Index(name string) int {
+	for i, step := range s {
+		if step.Name != "" && step.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Validate는 단계 종류별 필수 값과 이동 대상을 검증합니다.
+func (s ScenarioSteps) Validate() error {
+	names := make(map[string]bool)
+	for _, step := range s {
+		if step.Name == "" {
+			continue
+		}
+		if names[step.Name] {
+			return fmt.Errorf("단계 이름이 중복됩니다: %s", step.Name)
+		}
+		names[step.Name] = true
+	}
+
+	for i, step := range s {
+		label := fmt.Sprintf("%d번 단계", i+1)
+		if step.Name != "" {
+			label = fmt.Sprintf("%s(%s)", label, step.Name)
+		}
+		switch step.Type {
+		case StepSend:
+			if step.PacketID == 0 {
+				return fmt.Errorf("%s: packet_id가 필요합니다", label)
+			}
+			for _, v := range step.UseVars {
+				if v.Name == "" || v.Offset < 0 || v.Length < 0 {
+					return fmt.Errorf("%s: 잘못된 변수 사용 설정: %+v", label, v)
+				}
+			}
+		case StepExpect:
+			if step.TimeoutMs < 0 {
+				return fmt.Errorf("%s: timeout_ms는 0 이상이어야 합니다", label)
+			}
+			for _, check := range step.Assertions {
+				if err := check.Validate(); err != nil {
+					return fmt.Errorf("%s: %v", label, err)
+				}
+			}
+		case StepWait:
+			if step.DelayMs <= 0 {
+				return fmt.Errorf("%s: delay_ms는 0보다 커야 합니다", label)
+			}
+		case StepExtract:
+			if len(step.Extract) == 0 {
+				return fmt.Errorf("%s: 저장할 변수가 없습니다", label)
+			}
+			if err := step.Extract.ValidateCapture(); err != nil {
+				return fmt.Errorf("%s: %v", label, err)
+			}
+		case StepLoop:
+			target := s.Index(step.Target)
+			if target < 0 || target >= i {
+				return fmt.Errorf("%s: target은 앞선 단계의 이름이어야 합니다", label)
+			}
+			if step.Count <= 0 {
+				return fmt.Errorf("%s: count는 0보다 커야 합니다", label)
+			}
+			if step.Condition != nil {
+				if err := step.Condition.Validate(); err != nil {
+					return fmt.Errorf("%s: %v", label, err)
+				}
+			}
+		case StepBranch:
+			if step.Condition == nil {
+				return fmt.Errorf("%s: condition이 필요합니다", label)
+			}
+			if err := step.Condition.Validate(); err != nil {
+				return fmt.Errorf("%s: %v", label, err)
+			}
+			if s.Index(step.Target) < 0 {
+				return fmt.Errorf("%s: target 단계를 찾을 수 없습니다: %s", label, step.Target)
+			}
+			if step.Else != "" && s.Index(step.Else) < 0 {
+				return fmt.Errorf("%s: else 단계를 찾을 수 없습니다: %s", label, step.Else)
+			}
+		default:
+			return fmt.Errorf("%s: 지원되지 않는 단계 종류: %s", label, step.Type)
+		}
+	}
+	return nil
+}
+
+// Validate는 초기 변수 값이 HEX 문자열인지 확인합니다.
+func (v ScenarioVars) Validate() error {
+	for name, value := range v {
+		if _, err := hex.DecodeString(value); err != nil {
+			return fmt.Errorf("변수 %s의 값은 HEX 문자열이어야 합니다", name)
+		}
+	}
+	return nil
+}
+
+// Bytes는 변수 값을 바이트로 바꾼 맵을 반환합니다.
+func (v ScenarioVars) Bytes() map[string][]byte {
+	out := make(map[string][]byte, len(v))
+	for name, value := range v {
+		out[name], _ = hex.DecodeString(value)
+	}
+	return out
+}
+
+// VarsFromBytes는 바이트 변수 맵을 HEX 변수 맵으로 바꿉니다.
+func VarsFromBytes(vars map[string][]byte) ScenarioVars {
+	out := make(ScenarioVars, len(vars))
+	for name, value := range vars {
+		out[name] = hex.EncodeToString(value)
+	}
+	return out
+}
+
+// Value는 GORM을 위한 driver.Value 인터페이스를 구현합니다.
+func (s ScenarioSteps) Value() (driver.Value, error) {
+	if s == nil {
+		return nil, nil
+	}
+	return json.Marshal(s)
+}
+
+// Scan은 GORM을 위한 sql.Scanner 인터페이스를 구현합니다.
+func (s *ScenarioSteps) Scan(value interface{}) error {
+	var bytes []byte
+	switch v := value.(type) {
+	case nil:
+		*s = nil
+		return nil
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return errors.New("시나리오 단계를 스캔할 수 없음")
+	}
+
+	if len(bytes) == 0 {
+		*s = nil
+		return nil
+	}
+	return json.Unmarshal(bytes, s)
+}
+
+// Value는 GORM을 위한 driver.Value 인터페이스를 구현합니다.
+func (v ScenarioVars) Value() (driver.Value, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+// Scan은 GORM을 위한 sql.Scanner 인터페이스를 구현합니다.
+func (v *ScenarioVars) Scan(value interface{}) error {
+	var bytes []byte
+	switch val := value.(type) {
+	case nil:
+		*v = nil
+		return nil
+	case []byte:
+		bytes = val
+	case string:
+		bytes = []byte(val)
+	default:
+		return errors.New("시나리오 변수를 스캔할 수 없음")
+	}
+
+	if len(bytes) == 0 {
+		*v = nil
+		return nil
+	}
+	return json.Unmarshal(bytes, v)
+}
+
+// Value는 GORM을 위한 driver.Value 인터페이스를 구현합니다.
+func (l ScenarioLog) Value() (driver.Value, error) {
+	if l == nil {
+		return nil, nil
+	}
+	return json.Marshal(l)
+}
+
+// Scan은 GORM을 위한 sql.Scanner 인터페이스를 구현합니다.
+func (l *ScenarioLog) Scan(value interface{}) error {
+	var bytes []byte
+	switch v := value.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return errors.New("시나리오 실행 기록을 스캔할 수 없음")
+	}
+
+	if len(bytes) == 0 {
+		*l = nil
+		return nil
+	}
+	return json.Unmarshal(bytes, l)
+}
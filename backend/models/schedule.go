@@ -0,0 +1,99 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/fake-edge-server/utils"
+	"gorm.io/gorm"
+)
+
+// 예약 실행 대상
+const (
+	ScheduleTargetPacket   = "packet"   // 패킷을 한 번 전송
+	ScheduleTargetScenario = "scenario" // 시나리오를 끝까지 실행
+)
+
+// 예약 실행 결과
+const (
+	ScheduleRunning   = "running"
+	ScheduleSucceeded = "succeeded"
+	ScheduleFailed    = "failed"
+)
+
+// Schedule은 cron 표현식에 맞춰 패킷 전송이나 시나리오를 실행하는 예약입니다.
+// 예: 평일 02:00에 상태 점검 시나리오 실행, 15분마다 모든 서버에 상태 요청 전송
+type Schedule struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	Name        string         `json:"name" gorm:"uniqueIndex"`
+	Cron        string         `json:"cron"`     // 분 시 일 월 요일
+	Timezone    string         `json:"timezone"` // 비어 있으면 서버 시간대
+	Target      string         `json:"target"`   // packet | scenario
+	PacketID    uint           `json:"packet_id"`
+	ScenarioID  uint           `json:"scenario_id"`
+	TCPServerID uint           `json:"tcp_server_id"` // 0이면 패킷은 소속 서버, 시나리오는 기본 서버
+	AllServers  bool           `json:"all_servers"`   // 등록된 모든 서버에 실행
+	Enabled     bool           `json:"enabled"`
+	NextRunAt   *time.Time     `json:"next_run_at"`
+	LastRunAt   *time.Time     `json:"last_run_at"`
+	LastStatus  string         `json:"last_status"`
+	LastError   string         `json:"last_error"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+// ScheduleRequest는 예약 생성/수정 요청 구조체입니다.
+type ScheduleRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Cron        string `json:"cron" binding:"required"`
+	Timezone    string `json:"timezone"`
+	Target      string `json:"target" binding:"required"`
+	PacketID    uint   `json:"packet_id"`
+	ScenarioID  uint   `json:"scenario_id"`
+	TCPServerID uint   `json:"tcp_server_id"`
+	AllServers  bool   `json:"all_servers"`
+	Enabled     bool   `json:"enabled"`
+}
+
+// ScheduleRun은 예약 실행 한 번의 서버별 결과입니다.
+type ScheduleRun struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	ScheduleID    uint       `json:"schedule_id" gorm:"index"`
+	TCPServerID   uint       `json:"tcp_server_id"`
+	Status        string     `json:"status"`
+	Error         string     `json:"error"`
+	HistoryID     uint       `json:"history_id"`      // 패킷 전송 이력
+	ScenarioRunID uint       `json:"scenario_run_id"` // 시나리오 실행 기록
+	Manual        bool       `json:"manual"`          // 예약 시각이 아닌 즉시 실행 요청
+	StartedAt     time.Time  `json:"started_at"`
+	FinishedAt    *time.Time `json:"finished_at"`
+}
+
+// CronSchedule은 cron 표현식과 시간대를 해석합니다.
+func (s Schedule) CronSchedule() (*utils.CronSchedule, error) {
+	return parseCron(s.Cron, s.Timezone)
+}
+
+// Validate는 실행 시점과 대상 설정을 검증합니다.
+func (s Schedule) Validate() error {
+	if _, err := s.CronSchedule(); err != nil {
+		return err
+	}
+	switch s.Target {
+	case ScheduleTargetPacket:
+		if s.PacketID == 0 || s.ScenarioID != 0 {
+			return errors.New("packet 대상은 packet_id만 지정해주세요")
+		}
+	case ScheduleTargetScenario:
+		if s.ScenarioID == 0 || s.PacketID != 0 {
+			return errors.New("scenario 대상은 scenario_id만 지정해주세요")
+		}
+	default:
+		return errors.New("target은 packet 또는 scenario여야 합니다")
+	}
+	if s.AllServers && s.TCPServerID != 0 {
+		return errors.New("all_servers와 tcp_server_id는 함께 사용할 수 없습니다")
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// 반복 전송 작업 상태
+const (
+	SendJobRunning     = "running"
+	SendJobPaused      = "paused" // 서버가 재연결 중이라 전송을 멈춤
+	SendJobStopped     = "stopped"
+	SendJobInterrupted = "interrupted" // 재시작 전에 실행 중이었지만 재개 정책에 따라 다시 실행하지 않음
+	SendJobFailed      = "failed"      // 재시작 후 서버나 패킷을 찾지 못했거나 서버 재연결에 실패함
+	SendJobCompleted   = "completed"   // 전송 횟수나 실행 시간 제한에 도달해 끝남
+)
+
+// MaxJobBurst는 간격마다 연달아 보낼 수 있는 최대 전송 수입니다.
+const MaxJobBurst = 1000
+
+// 재시작 후 실행 중이던 작업의 재개 정책 (config.json의 job_resume_policy)
+const (
+	JobResumeContinue = "resume"  // 시작 시각을 유지하고 이어서 실행 (기본값)
+	JobResumeRestart  = "restart" // 시작 시각을 재시작 시각으로 바꿔 새로 실행
+	JobResumeNone     = "none"    // 다시 실행하지 않고 interrupted로 표시
+)
+
+// SendJob은 패킷을 일정 간격으로 반복 전송하는 백그라운드 작업입니다.
+// 백엔드가 재시작되어도 남아 있어 재개 여부를 확인할 수 있습니다.
+type SendJob struct {
+	ID          uint   `json:"id" gorm:"primaryKey"`
+	TCPServerID uint   `json:"tcp_server_id" gorm:"index"`
+	ServerName  string `json:"server_name"`
+	TCPPacketID uint   `json:"tcp_packet_id" gorm:"index"`
+	PacketName  string `json:"packet_name"`
+	SendJobSpec
+	Status    string     `json:"status"`
+	Error     string     `json:"error"`
+	StartedAt time.Time  `json:"started_at"`
+	StoppedAt *time.Time `json:"stopped_at"`
+	Resumes   int        `json:"resumes"`    // 재시작 후 다시 실행된 횟수
+	ResumedAt *time.Time `json:"resumed_at"` // 마지막으로 다시 실행된 시각
+	SendJobMetrics
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SendJobSpec은 반복 전송의 간격과 제한입니다. 패킷 전송 요청 본문으로도 쓰이며,
+// interval_ms가 0이면 한 번만 전송합니다.
+type SendJobSpec struct {
+	IntervalMs int `json:"interval_ms"`
+	Count      int `json:"count"`       // 이 횟수만큼 보내면 완료 (0이면 무제한)
+	DurationMs int `json:"duration_ms"` // 시작 후 이 시간이 지나면 완료 (0이면 무제한)
+	JitterPct  int `json:"jitter_pct"`  // 간격을 ±이 비율(%) 안에서 무작위로 바꿈
+	Burst      int `json:"burst"`       // 간격마다 연달아 보내는 수 (0이면 1)
+}
+
+// Repeat는 반복 전송 요청인지 반환합니다.
+func (s SendJobSpec) Repeat() bool {
+	return s.IntervalMs > 0
+}
+
+// Validate는 간격과 제한 값을 검증합니다.
+func (s SendJobSpec) Validate() error {
+	if s.IntervalMs < 0 || s.Count < 0 || s.DurationMs < 0 || s.Burst < 0 {
+		return errors.New("interval_ms, count, duration_ms, burst는 0 이상이어야 합니다")
+	}
+	if !s.Repeat() && (s.Count > 0 || s.DurationMs > 0 || s.JitterPct > 0 || s.Burst > 0) {
+		return errors.New("count, duration_ms, jitter_pct, burst는 interval_ms와 함께 지정해주세요")
+	}
+	if s.JitterPct < 0 || s.JitterPct > 100 {
+		return errors.New("jitter_pct는 0~100 사이여야 합니다")
+	}
+	if s.Burst > MaxJobBurst {
+		return errors.New("burst가 너무 큽니다")
+	}
+	return nil
+}
+
+// SendJobMetrics는 반복 전송 작업의 전송 통계입니다. 응답이 없거나 수신 후
+// 스크립트 판정이 fail/error인 전송은 실패로 셉니다.
+type SendJobMetrics struct {
+	Sent         int64      `json:"sent"`
+	Succeeded    int64      `json:"succeeded"`
+	Failed       int64      `json:"failed"`
+	LastError    string     `json:"last_error"`
+	LastRTTMs    float64    `json:"last_rtt_ms"`
+	AvgRTTMs     float64    `json:"avg_rtt_ms"` // 성공한 전송의 평균 응답 시간
+	LastResponse string     `json:"last_response"`
+	LastSentAt   *time.Time `json:"last_sent_at"`
+}
+
+// ValidResumePolicy는 재개 정책 값이 올바른지 확인합니다. 빈 값은 기본값(resume)입니다.
+func ValidResumePolicy(policy string) bool {
+	switch policy {
+	case "", JobResumeContinue, JobResumeRestart, JobResumeNone:
+		return true
+	}
+	return false
+}
@@ -1,9 +1,15 @@
 package models
 
 import (
+	"bytes"
 	"database/sql/driver"
+	"encoding/binary"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"sort"
+	"strconv"
 	"time"
 
 	"gorm.io/gorm"
@@ -45,6 +51,83 @@ func (dt DataType) Size() int {
 	}
 }
 
+// Decode는 리틀 엔디언으로 연결된 값을 타입에 따라 문자열로 해석합니다.
+func (dt DataType) Decode(values []byte) (string, error) {
+	buf := bytes.NewBuffer(values)
+
+	switch dt {
+	case TypeInt8:
+		var v int8
+		err := binary.Read(buf, binary.LittleEndian, &v)
+		return strconv.Itoa(int(v)), err
+
+	case TypeInt16:
+		var v int16
+		err := binary.Read(buf, binary.LittleEndian, &v)
+		return strconv.Itoa(int(v)), err
+
+	case TypeInt32:
+		var v int32
+		err := binary.Read(buf, binary.LittleEndian, &v)
+		return strconv.Itoa(int(v)), err
+
+	case TypeInt64:
+		var v int64
+		err := binary.Read(buf, binary.LittleEndian, &v)
+		return strconv.FormatInt(v, 10), err
+
+	case TypeUint8:
+		var v uint8
+		err := binary.Read(buf, binary.LittleEndian, &v)
+		return strconv.FormatUint(uint64(v), 10), err
+
+	case TypeUint16:
+		var v uint16
+		err := binary.Read(buf, binary.LittleEndian, &v)
+		return strconv.FormatUint(uint64(v), 10), err
+
+	case TypeUint32:
+		var v uint32
+		err := binary.Read(buf, binary.LittleEndian, &v)
+		return strconv.FormatUint(uint64(v), 10), err
+
+	case TypeUint64:
+		var v uint64
+		err := binary.Read(buf, binary.LittleEndian, &v)
+		return strconv.FormatUint(v, 10), err
+
+	case TypeFloat32:
+		var v float32
+		err := binary.Read(buf, binary.LittleEndian, &v)
+		return fmt.Sprintf("%f", v), err
+
+	case TypeFloat64:
+		var v float64
+		err := binary.Read(buf, binary.LittleEndian, &v)
+		return fmt.Sprintf("%f", v), err
+
+	case TypeString:
+		return string(values), nil
+
+	case TypeHex:
+		return hex.EncodeToString(values), nil
+
+	case TypeJSON:
+		var v interface{}
+		if err := json.Unmarshal(values, &v); err != nil {
+			return "", fmt.Errorf("Json으로 호환되는 응답이 아닙니다.")
+		}
+		parsed, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(parsed), nil
+
+	default:
+		return "", fmt.Errorf("지원되지 않는 데이터 타입: %d", dt)
+	}
+}
+
 // PacketDataItem은 패킷의 개별 데이터 항목을 나타냅니다.
 type PacketDataItem struct {
 	Offset    int      `json:"offset"`
@@ -57,6 +140,89 @@ type PacketDataItem struct {
 // PacketData는 패킷 데이터 항목의 배열입니다.
 type PacketData []PacketDataItem
 
+// Field는 offset에서 시작하는 필드의 타입과 바이트 길이를 반환합니다.
+// 체인된 항목은 이어지는 오프셋의 체인 항목까지 하나의 필드로 봅니다.
+func (pd PacketData) Field(offset int) (DataType, int, bool) {
+	byOffset := make(map[int]PacketDataItem, len(pd))
+	for _, item := range pd {
+		byOffset[item.Offset] = item
+	}
+	item, ok := byOffset[offset]
+	if !ok {
+		return 0, 0, false
+	}
+	if !item.IsChained {
+		return item.Type, 1, true
+	}
+	length := 1
+	for length != item.Type.Size() {
+		next, ok := byOffset[offset+length]
+		if !ok || !next.IsChained {
+			break
+		}
+		length++
+	}
+	return item.Type, length, true
+}
+
+// DecodedField는 데이터 정의로 해석한 필드 값입니다.
+type DecodedField struct {
+	Offset int      `json:"offset"`
+	Length int      `json:"length"`
+	Type   DataType `json:"type"`
+	Desc   string   `json:"desc"`
+	Value  string   `json:"value"`
+}
+
+// Len은 데이터 정의가 차지하는 바이트 수(마지막 오프셋 + 1)를 반환합니다.
+func (pd PacketData) Len() int {
+	length := 0
+	for _, item := range pd {
+		if item.Offset+1 > length {
+			length = item.Offset + 1
+		}
+	}
+	return length
+}
+
+// Fields는 데이터 정의의 필드 배치를 오프셋 순으로 반환합니다. 값(Value)은 비어 있습니다.
+// 체인된 항목은 하나의 필드로 묶습니다.
+func (pd PacketData) Fields() []DecodedField {
+	items := append(PacketData(nil), pd...)
+	sort.Slice(items, func(i, j int) bool { return items[i].Offset < items[j].Offset })
+
+	var fields []DecodedField
+	next := 0
+	for _, item := range items {
+		if item.Offset < next {
+			continue // 앞 필드에 체인된 항목
+		}
+		dt, length, _ := pd.Field(item.Offset)
+		next = item.Offset + length
+		fields = append(fields, DecodedField{Offset: item.Offset, Length: length, Type: dt, Desc: item.Desc})
+	}
+	return fields
+}
+
+// DecodeFields는 데이터 정의에 따라 페이로드를 필드별로 해석합니다.
+// 체인된 항목은 하나의 필드로 해석하며, 페이로드를 벗어나거나 해석할 수 없는 필드는 건너뜁니다.
+func (pd PacketData) DecodeFields(payload []byte) []DecodedField {
+	var fields []DecodedField
+	for _, field := range pd.Fields() {
+		end := field.Offset + field.Length
+		if end > len(payload) {
+			continue
+		}
+		value, err := field.Type.Decode(payload[field.Offset:end])
+		if err != nil {
+			continue
+		}
+		field.Value = value
+		fields = append(fields, field)
+	}
+	return fields
+}
+
 // Value는 GORM을 위한 driver.Value 인터페이스를 구현합니다.
 func (pd PacketData) Value() (driver.Value, error) {
 	if pd == nil {
@@ -84,15 +250,70 @@ func (pd *PacketData) Scan(value interface{}) error {
 	return json.Unmarshal(bytes, pd)
 }
 
+// 패킷 종류
+const (
+	PacketKindRaw    = "raw"    // 정의된 바이트를 그대로 전송
+	PacketKindEdge   = "edge"   // 노드/커맨드 타입과 메시지 ID를 포함한 Edge 프레임
+	PacketKindModbus = "modbus" // MBAP 헤더를 붙인 Modbus TCP 요청
+)
+
 // TCPPacket은 TCP 패킷 모델을 정의합니다.
 type TCPPacket struct {
-	ID          uint           `json:"id" gorm:"primaryKey"`
-	TCPServerID uint           `json:"tcp_server_id"`
-	Data        PacketData     `json:"data" gorm:"type:text"`
-	Name        string         `json:"name" gorm:"index:tcp_packet_name_idx,unique"`
-	Desc        string         `json:"desc"`
-	UseCRC      bool           `json:"use_crc"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `json:"deleted_at" gorm:"index:tcp_packet_name_idx"`
+	ID                uint           `json:"id" gorm:"primaryKey"`
+	TCPServerID       uint           `json:"tcp_server_id"`
+	Data              PacketData     `json:"data" gorm:"type:text"`
+	Name              string         `json:"name" gorm:"index:tcp_packet_name_idx,unique"`
+	Desc              string         `json:"desc"`
+	UseCRC            bool           `json:"use_crc"`
+	Kind              string         `json:"kind"`
+	NodeType          uint8          `json:"node_type"`    // Edge 패킷의 노드 타입
+	CommandType       uint8          `json:"command_type"` // Edge 패킷의 커맨드 타입
+	EdgeID            uint8          `json:"edge_id"`      // Edge 페이로드의 edgeId
+	Modbus            ModbusParams   `json:"modbus" gorm:"type:text"`
+	PreSendScript     string         `json:"pre_send_script" gorm:"type:text"`     // Starlark pre_send(data): 보낼 데이터를 바꿈
+	PostReceiveScript string         `json:"post_receive_script" gorm:"type:text"` // Starlark post_receive(request, response): 응답을 해석하고 판정
+	ScriptTimeoutMs   int            `json:"script_timeout_ms"`                    // 스크립트 한 번의 실행 제한 시간, 0이면 1초
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `json:"deleted_at" gorm:"index:tcp_packet_name_idx"`
+}
+
+// Identifies는 페이로드가 이 패킷 정의로 만든 프레임으로 보이는지 확인합니다.
+// raw 패킷만 대상으로 하며, 길이가 정의와 같고 첫 바이트(명령 코드)가 정의 값과 같으면 일치로 봅니다.
+func (p TCPPacket) Identifies(payload []byte) bool {
+	if p.Kind != "" && p.Kind != PacketKindRaw {
+		return false
+	}
+	if len(p.Data) == 0 || len(payload) != p.Data.Len() {
+		return false
+	}
+	for _, item := range p.Data {
+		if item.Offset == 0 {
+			return payload[0] == byte(item.Value)
+		}
+	}
+	return false
+}
+
+// CheckPushable은 목 엔드포인트가 클라이언트에게 푸시할 수 있는 패킷인지 확인합니다.
+// 목 엔드포인트는 메시지 ID나 MBAP 헤더를 붙이지 않으므로 raw 패킷만 푸시할 수 있습니다.
+func (p TCPPacket) CheckPushable() error {
+	if p.Kind != "" && p.Kind != PacketKindRaw {
+		return fmt.Errorf("%s 패킷은 푸시할 수 없습니다 (raw 패킷만 지원)", p.Kind)
+	}
+	return nil
+}
+
+// ValidateKind는 패킷 종류와 종류별 설정을 검증합니다. 빈 종류는 raw로 취급합니다.
+func (p TCPPacket) ValidateKind() error {
+	switch p.Kind {
+	case "", PacketKindRaw, PacketKindEdge:
+		return nil
+	case PacketKindModbus:
+		if _, err := p.Modbus.PDU(); err != nil {
+			return fmt.Errorf("Modbus 설정 오류: %v", err)
+		}
+		return nil
+	}
+	return fmt.Errorf("지원되지 않는 패킷 종류: %s", p.Kind)
 }
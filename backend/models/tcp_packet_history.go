@@ -5,16 +5,44 @@ import (
 	"time"
 )
 
+// 수신 후 스크립트 판정
+const (
+	VerdictPass  = "pass"
+	VerdictFail  = "fail"
+	VerdictError = "error" // 스크립트 실행 실패
+)
+
+// DirectionUnsolicited는 요청의 응답이 아닌, 서버가 보낸 데이터그램의 방향입니다.
+const DirectionUnsolicited = "unsolicited"
+
 // TCPPacketHistory stores request/response pairs for sent packets.
+// Frames exchanged by mock endpoints are stored one per row with MockEndpointID
+// and Direction set: inbound data goes to Request, outbound data to Response.
+// Frames forwarded by relays are stored the same way with RelayID set: data from
+// the client goes to Request, data from the server to Response.
+// Datagrams from UDP servers that are not the response to a send are stored
+// one per row with Direction set to DirectionUnsolicited and only Response.
 type TCPPacketHistory struct {
-	ID          uint           `json:"id" gorm:"primaryKey"`
-	TCPServerID uint           `json:"tcp_server_id"`
-	TCPPacketID uint           `json:"tcp_packet_id"`
-	PacketName  string         `json:"packet_name"`
-	PacketDesc  string         `json:"packet_desc"`
-	Request     string         `json:"request" gorm:"type:text"`
-	Response    string         `json:"response" gorm:"type:text"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+	ID             uint           `json:"id" gorm:"primaryKey"`
+	TCPServerID    uint           `json:"tcp_server_id"`
+	TCPPacketID    uint           `json:"tcp_packet_id"`
+	MockEndpointID uint           `json:"mock_endpoint_id" gorm:"index"`
+	RelayID        uint           `json:"relay_id" gorm:"index"`
+	Direction      string         `json:"direction"`
+	Peer           string         `json:"peer"` // 목 엔드포인트나 중계에 접속한 클라이언트 주소
+	PacketName     string         `json:"packet_name"`
+	PacketDesc     string         `json:"packet_desc"`
+	Kind           string         `json:"kind"`
+	NodeType       uint8          `json:"node_type"`
+	CommandType    uint8          `json:"command_type"`
+	MsgID          uint64         `json:"msg_id"`
+	Request        string         `json:"request" gorm:"type:text"`
+	Response       string         `json:"response" gorm:"type:text"`
+	Faults         string         `json:"faults"`                      // 주입한 결함 이름(쉼표 구분)
+	Decoded        string         `json:"decoded" gorm:"type:text"`    // 프로토콜별로 해석한 응답 또는 중계 프레임(JSON)
+	Verdict        string         `json:"verdict"`                     // 수신 후 스크립트의 판정 (pass | fail | error)
+	ScriptLog      string         `json:"script_log" gorm:"type:text"` // 스크립트 출력과 판정 메시지
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `json:"deleted_at" gorm:"index"`
 }
@@ -1,25 +1,88 @@
 package models
 
 import (
+	"net"
+	"strconv"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+// 서버 전송 방식
+const (
+	TransportTCP  = "tcp"
+	TransportUDP  = "udp"
+	TransportUnix = "unix" // Unix 도메인 스트림 소켓
+)
+
+// defaultResponseWindow는 UDP 응답을 기다리는 기본 시간입니다.
+const defaultResponseWindow = time.Second
+
 // TCPServer는 TCP 서버 연결 정보를 저장하는 모델입니다.
 type TCPServer struct {
-	ID        uint           `json:"id" gorm:"primaryKey"`
-	Name      string         `json:"name" gorm:"uniqueIndex"`
-	Host      string         `json:"host"`
-	Port      int            `json:"port"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+	ID        uint            `json:"id" gorm:"primaryKey"`
+	Name      string          `json:"name" gorm:"uniqueIndex"`
+	Host      string          `json:"host"`
+	Port      int             `json:"port"`
+	Transport string          `json:"transport"`          // tcp(기본) | udp | unix
+	Socket    string          `json:"socket_path"`        // unix 전송의 소켓 경로
+	WindowMs  int             `json:"response_window_ms"` // UDP 응답 대기 시간
+	Framing   FrameProfile    `json:"framing" gorm:"type:text"`
+	TLS       TLSSettings     `json:"tls" gorm:"type:text"`
+	Proxy     ProxySettings   `json:"proxy" gorm:"type:text"`
+	Reconnect ReconnectPolicy `json:"reconnect" gorm:"type:text"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+	DeletedAt gorm.DeletedAt  `json:"deleted_at" gorm:"index"`
 }
 
 // TCPServerRequest는 TCP 서버 생성/수정 요청 구조체입니다.
 type TCPServerRequest struct {
 	Name string `json:"name" binding:"required"`
-	Host string `json:"host" binding:"required"`
-	Port int    `json:"port" binding:"required"`
+	// Host/Port는 unix 전송에서는 사용하지 않습니다.
+	Host string `json:"host"`
+	Port int    `json:"port"`
+	// Framing은 CRC 사용 패킷의 헤더 구성입니다. 생략하면 기본 프레임을 사용합니다.
+	Framing FrameProfile `json:"framing"`
+	// TLS는 대상 서버 접속 시 사용할 TLS 설정입니다.
+	TLS TLSSettings `json:"tls"`
+	// Proxy는 대상 서버 접속 시 거칠 SOCKS5/HTTP CONNECT 프록시입니다.
+	Proxy ProxySettings `json:"proxy"`
+	// Reconnect는 연결이 끊어졌을 때의 재연결 정책입니다. 생략하면 재연결하지 않습니다.
+	Reconnect ReconnectPolicy `json:"reconnect"`
+	// Transport는 전송 방식(tcp, udp, unix)이며 생략하면 tcp입니다.
+	Transport string `json:"transport"`
+	// Socket은 unix 전송에서 접속할 소켓 경로입니다.
+	Socket string `json:"socket_path"`
+	// WindowMs는 UDP 전송 후 응답 데이터그램을 기다리는 시간(ms)입니다.
+	WindowMs int `json:"response_window_ms"`
+}
+
+// Network는 net.Dial에 사용할 네트워크 이름을 반환합니다.
+func (s TCPServer) Network() string {
+	if s.Transport == "" {
+		return TransportTCP
+	}
+	return s.Transport
+}
+
+// Address는 net.Dial에 사용할 주소를 반환합니다.
+func (s TCPServer) Address() string {
+	if s.Network() == TransportUnix {
+		return s.Socket
+	}
+	return net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+}
+
+// IsDatagram은 데이터그램 기반 전송인지 확인합니다.
+func (s TCPServer) IsDatagram() bool {
+	return s.Network() == TransportUDP
+}
+
+// ResponseWindow는 UDP 응답 대기 시간을 반환합니다.
+func (s TCPServer) ResponseWindow() time.Duration {
+	if s.WindowMs <= 0 {
+		return defaultResponseWindow
+	}
+	return time.Duration(s.WindowMs) * time.Millisecond
 }
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// tlsVersions는 MinVersion 설정값과 crypto/tls 버전 상수의 매핑입니다.
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// TLSSettings는 대상 서버에 TLS로 접속할 때 사용하는 설정입니다.
+// 인증서와 키는 PEM 문자열로 저장합니다.
+type TLSSettings struct {
+	Enabled            bool   `json:"enabled"`
+	ServerName         string `json:"server_name"` // 비어 있으면 Host를 사용
+	CACert             string `json:"ca_cert"`     // 서버 인증서를 검증할 CA 번들
+	ClientCert         string `json:"client_cert"` // 상호 인증용 클라이언트 인증서
+	ClientKey          string `json:"client_key"`  // 요청으로만 받고 응답에서는 비움
+	MinVersion         string `json:"min_version"` // 1.0 | 1.1 | 1.2 | 1.3 (기본 1.2)
+	InsecureSkipVerify bool   `json:"insecure_skip_verify"`
+}
+
+// Config는 설정을 검증하고 host에 접속할 tls.Config를 생성합니다.
+func (t TLSSettings) Config(host string) (*tls.Config, error) {
+	cfg := &tls.Config{
+		ServerName:         t.ServerName,
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+	}
+	if cfg.ServerName == "" {
+		cfg.ServerName = host
+	}
+
+	if t.MinVersion != "" {
+		version, ok := tlsVersions[t.MinVersion]
+		if !ok {
+			return nil, fmt.Errorf("지원되지 않는 TLS 버전: %s", t.MinVersion)
+		}
+		cfg.MinVersion = version
+	}
+
+	if t.CACert != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(t.CACert)) {
+			return nil, errors.New("CA 인증서를 해석할 수 없습니다")
+		}
+		cfg.RootCAs = pool
+	}
+
+	if t.ClientCert != "" || t.ClientKey != "" {
+		cert, err := tls.X509KeyPair([]byte(t.ClientCert), []byte(t.ClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("클라이언트 인증서/키 오류: %v", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// Redacted는 클라이언트 키를 비운 설정을 반환합니다. 응답에는 키를 내보내지 않습니다.
+func (t TLSSettings) Redacted() TLSSettings {
+	t.ClientKey = ""
+	return t
+}
+
+// Value는 GORM을 위한 driver.Value 인터페이스를 구현합니다.
+func (t TLSSettings) Value() (driver.Value, error) {
+	return json.Marshal(t)
+}
+
+// Scan은 GORM을 위한 sql.Scanner 인터페이스를 구현합니다.
+func (t *TLSSettings) Scan(value interface{}) error {
+	var bytes []byte
+	switch v := value.(type) {
+	case nil:
+		*t = TLSSettings{}
+		return nil
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return errors.New("TLS 설정을 스캔할 수 없음")
+	}
+
+	if len(bytes) == 0 {
+		*t = TLSSettings{}
+		return nil
+	}
+	return json.Unmarshal(bytes, t)
+}
@@ -1,6 +1,7 @@
 package routes
 
 import (
+	"github.com/fake-edge-server/config"
 	"github.com/fake-edge-server/handlers"
 	"github.com/fake-edge-server/services"
 	"github.com/gin-gonic/gin"
@@ -13,13 +14,31 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB) {
 	tcpService := services.NewTCPService(db)
 	connManager := services.NewTCPConnectionManager()
 	hub := services.NewWebSocketHub()
+	connManager.SetHub(hub)
+	connManager.SetDB(db)
 	sender := services.NewPacketSender(db, connManager, hub)
+	sender.ResumeJobs(config.GetConfig().JobResumePolicy)
+	mocks := services.NewMockServerManager(db, hub)
+	relays := services.NewRelayManager(db, hub)
+	loadTests := services.NewLoadTestRunner(db, hub)
+	fuzzer := services.NewFuzzRunner(db, hub)
+	scenarios := services.NewScenarioRunner(db, hub)
+	scheduler := services.NewScheduler(db, hub, sender, scenarios)
+	scheduler.Start()
 
 	// API 핸들러 생성
 	apiHandler := handlers.NewAPIHandler(db, tcpService)
 	tcpServerHandler := handlers.NewTCPServerHandler(db, connManager, hub)
 	tcpPacketHandler := handlers.NewTCPPacketHandler(db, connManager, hub, sender)
 	wsHandler := handlers.NewWSHandler(hub)
+	mockHandler := handlers.NewMockHandler(db, mocks, hub)
+	recordingHandler := handlers.NewRecordingHandler(db, sender)
+	relayHandler := handlers.NewRelayHandler(db, relays, hub)
+	loadTestHandler := handlers.NewLoadTestHandler(db, loadTests)
+	fuzzHandler := handlers.NewFuzzHandler(db, fuzzer)
+	scenarioHandler := handlers.NewScenarioHandler(db, scenarios)
+	scheduleHandler := handlers.NewScheduleHandler(db, scheduler)
+	jobHandler := handlers.NewJobHandler(db, sender)
 
 	// 라우트 그룹
 	api := r.Group("/api")
@@ -65,7 +84,114 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB) {
 			tc.POST("/:id/packets/:packet_id/send", tcpPacketHandler.SendTCPPacket)
 			tc.POST("/:id/packets/:packet_id/stop", tcpPacketHandler.StopTCPPacketSend)
 			tc.GET("/:id/history", tcpPacketHandler.GetTCPPacketHistory)
+			tc.POST("/:id/modbus", tcpPacketHandler.SendModbus) // Modbus 작업 즉시 실행
 
+			tc.GET("/:id/recordings", recordingHandler.GetRecordings)       // 요청/응답 기록 목록
+			tc.POST("/:id/recordings", recordingHandler.StartRecording)     // 기록 시작
+			tc.POST("/:id/recordings/stop", recordingHandler.StopRecording) // 기록 종료
+
+			tc.GET("/:id/loadtests", loadTestHandler.GetLoadTests)   // 부하 시험 목록
+			tc.POST("/:id/loadtests", loadTestHandler.StartLoadTest) // 부하 시험 시작
+
+			tc.GET("/:id/fuzz", fuzzHandler.GetFuzzJobs)   // 퍼징 작업 목록
+			tc.POST("/:id/fuzz", fuzzHandler.StartFuzzJob) // 퍼징 작업 시작
+
+		}
+
+		mk := api.Group("/mocks")
+		{ // 목(수신 대기) 엔드포인트 관리
+			mk.POST("", mockHandler.CreateMockEndpoint)
+			mk.GET("", mockHandler.GetMockEndpoints)
+			mk.GET("/:id", mockHandler.GetMockEndpointByID)
+			mk.PUT("/:id", mockHandler.UpdateMockEndpoint)
+			mk.DELETE("/:id", mockHandler.DeleteMockEndpoint)
+
+			mk.GET("/:id/status", mockHandler.GetMockStatus)           // 실행 상태 및 수신 주소
+			mk.POST("/:id/start", mockHandler.StartMockEndpoint)       // 수신 대기 시작
+			mk.POST("/:id/stop", mockHandler.StopMockEndpoint)         // 수신 대기 중지
+			mk.GET("/:id/connections", mockHandler.GetMockConnections) // 연결된 클라이언트 목록
+			mk.GET("/:id/history", mockHandler.GetMockHistory)         // 송수신 프레임 이력
+
+			mk.GET("/:id/rules", mockHandler.GetMockRules) // 응답 규칙 관리
+			mk.POST("/:id/rules", mockHandler.CreateMockRule)
+			mk.PUT("/:id/rules/:rule_id", mockHandler.UpdateMockRule)
+			mk.DELETE("/:id/rules/:rule_id", mockHandler.DeleteMockRule)
+
+			mk.POST("/:id/push", mockHandler.PushMockPacket) // 패킷 즉시 전송 (하나 또는 모든 연결)
+			mk.GET("/:id/pushes", mockHandler.GetMockPushes) // 주기/cron 전송 관리
+			mk.POST("/:id/pushes", mockHandler.CreateMockPush)
+			mk.PUT("/:id/pushes/:push_id", mockHandler.UpdateMockPush)
+			mk.DELETE("/:id/pushes/:push_id", mockHandler.DeleteMockPush)
+		}
+
+		rc := api.Group("/recordings")
+		{ // 기록 조회 및 목 재생
+			rc.GET("/:id", recordingHandler.GetRecordingByID)
+			rc.DELETE("/:id", recordingHandler.DeleteRecording)
+			rc.POST("/:id/mock", recordingHandler.CreateReplayMock) // 기록으로 목 엔드포인트 생성
+		}
+
+		rl := api.Group("/relays")
+		{ // 투명 TCP 중계(클라이언트 ↔ TCP 서버) 관리
+			rl.POST("", relayHandler.CreateRelay)
+			rl.GET("", relayHandler.GetRelays)
+			rl.GET("/:id", relayHandler.GetRelayByID)
+			rl.PUT("/:id", relayHandler.UpdateRelay)
+			rl.DELETE("/:id", relayHandler.DeleteRelay)
+
+			rl.GET("/:id/status", relayHandler.GetRelayStatus)   // 실행 상태, 수신 주소, 중계 중인 연결
+			rl.POST("/:id/start", relayHandler.StartRelay)       // 수신 대기 시작
+			rl.POST("/:id/stop", relayHandler.StopRelay)         // 수신 대기 및 연결 종료
+			rl.GET("/:id/history", relayHandler.GetRelayHistory) // 양방향 프레임 이력
+		}
+
+		lt := api.Group("/loadtests")
+		{ // 부하 시험 결과 조회 및 중지
+			lt.GET("/:id", loadTestHandler.GetLoadTestByID)
+			lt.DELETE("/:id", loadTestHandler.DeleteLoadTest)
+			lt.POST("/:id/stop", loadTestHandler.StopLoadTest)
+		}
+
+		fz := api.Group("/fuzz")
+		{ // 퍼징 작업 조회/중지와 크래시 입력 재생
+			fz.GET("/:id", fuzzHandler.GetFuzzJobByID)
+			fz.DELETE("/:id", fuzzHandler.DeleteFuzzJob)
+			fz.POST("/:id/stop", fuzzHandler.StopFuzzJob)
+			fz.GET("/:id/cases", fuzzHandler.GetFuzzCases)                    // 크래시 입력 목록
+			fz.POST("/:id/cases/:case_id/replay", fuzzHandler.ReplayFuzzCase) // 크래시 입력 재생
+		}
+
+		sc := api.Group("/scenarios")
+		{ // 다단계 시나리오 관리와 실행
+			sc.POST("", scenarioHandler.CreateScenario)
+			sc.GET("", scenarioHandler.GetScenarios)
+			sc.GET("/:id", scenarioHandler.GetScenarioByID)
+			sc.PUT("/:id", scenarioHandler.UpdateScenario)
+			sc.DELETE("/:id", scenarioHandler.DeleteScenario)
+
+			sc.POST("/:id/run", scenarioHandler.RunScenario)                   // 시나리오 실행
+			sc.GET("/:id/runs", scenarioHandler.GetScenarioRuns)               // 실행 기록 목록
+			sc.GET("/:id/runs/:run_id", scenarioHandler.GetScenarioRunByID)    // 단계별 결과
+			sc.POST("/:id/runs/:run_id/stop", scenarioHandler.StopScenarioRun) // 실행 중지
+		}
+
+		sd := api.Group("/schedules")
+		{ // cron 예약 전송/시나리오 관리
+			sd.POST("", scheduleHandler.CreateSchedule)
+			sd.GET("", scheduleHandler.GetSchedules)
+			sd.GET("/:id", scheduleHandler.GetScheduleByID)
+			sd.PUT("/:id", scheduleHandler.UpdateSchedule)
+			sd.DELETE("/:id", scheduleHandler.DeleteSchedule)
+
+			sd.POST("/:id/run", scheduleHandler.RunSchedule)     // 즉시 한 번 실행
+			sd.GET("/:id/runs", scheduleHandler.GetScheduleRuns) // 서버별 실행 기록
+		}
+
+		jb := api.Group("/jobs")
+		{ // 반복 전송 작업 조회와 중지 (전송 통계, 재시작 후 재개 여부 포함)
+			jb.GET("", jobHandler.GetJobs)
+			jb.GET("/:id", jobHandler.GetJobByID)
+			jb.POST("/:id/stop", jobHandler.StopJob)
 		}
 	}
 
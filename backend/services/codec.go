@@ -0,0 +1,127 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+
+	"github.com/fake-edge-server/models"
+	"github.com/fake-edge-server/utils"
+)
+
+// packetPayload returns the bytes a packet carries: the Modbus PDU for Modbus
+// packets and the packet data otherwise.
+func packetPayload(packet models.TCPPacket) ([]byte, error) {
+	if packet.Kind == models.PacketKindModbus {
+		return packet.Modbus.PDU()
+	}
+	return packetDataToBytes(packet.Data), nil
+}
+
+// encodePayload wraps payload the way the packet is sent: in an Edge frame for
+// Edge packets, in an MBAP header for Modbus packets (payload is the PDU) and
+// in the server's frame for raw packets with UseCRC. seq is used as the Edge
+// message ID or Modbus transaction ID. It returns the split function for the
+// response, which is nil for unframed raw packets, and fails when the payload
+// does not fit the frame's length field.
+func encodePayload(format utils.FrameFormat, packet models.TCPPacket, payload []byte, seq uint64) ([]byte, bufio.SplitFunc, error) {
+	var wire []byte
+	var err error
+	switch {
+	case packet.Kind == models.PacketKindEdge:
+		edge := utils.BuildEdgePayload(packet.EdgeID, seq, payload)
+		wire, err = format.BuildWithType(packet.NodeType, packet.CommandType, edge)
+	case packet.Kind == models.PacketKindModbus:
+		wire = utils.BuildMBAP(uint16(seq), packet.Modbus.UnitID, payload)
+	case packet.UseCRC:
+		wire, err = format.Build(payload)
+	default:
+		wire = payload
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return wire, responseSplit(format, packet), nil
+}
+
+// responseSplit returns the split function for the packet's response, which is
+// nil for unframed raw packets.
+func responseSplit(format utils.FrameFormat, packet models.TCPPacket) bufio.SplitFunc {
+	switch {
+	case packet.Kind == models.PacketKindModbus:
+		return utils.SplitMBAP
+	case packet.Kind == models.PacketKindEdge || packet.UseCRC:
+		return format.Split
+	}
+	return nil
+}
+
+// replyMatch returns a function that reports whether a response frame answers
+// the request encodePayload built with seq, by the Edge message ID or Modbus
+// transaction ID, and fails if the frame is malformed. It returns nil for raw
+// packets, whose replies carry no ID.
+func replyMatch(format utils.FrameFormat, packet models.TCPPacket, seq uint64) func([]byte) (bool, error) {
+	switch packet.Kind {
+	case models.PacketKindEdge:
+		return func(frame []byte) (bool, error) {
+			_, _, body, err := format.UnpackWithType(frame)
+			if err != nil {
+				return false, err
+			}
+			_, msgID, _, err := utils.ParseEdgePayload(body)
+			if err != nil {
+				return false, err
+			}
+			return msgID == seq, nil
+		}
+	case models.PacketKindModbus:
+		return func(frame []byte) (bool, error) {
+			return binary.BigEndian.Uint16(frame[0:2]) == uint16(seq), nil
+		}
+	}
+	return nil
+}
+
+// decodePayload undoes encodePayload on a response frame: it verifies the
+// frame and returns the Edge data, the Modbus PDU or the unframed raw bytes.
+func decodePayload(format utils.FrameFormat, packet models.TCPPacket, frame []byte) ([]byte, error) {
+	switch packet.Kind {
+	case models.PacketKindEdge:
+		_, _, payload, err := format.UnpackWithType(frame)
+		if err != nil {
+			return nil, err
+		}
+		_, _, data, err := utils.ParseEdgePayload(payload)
+		return data, err
+	case models.PacketKindModbus:
+		if len(frame) < utils.MBAPHeaderSize {
+			return nil, errors.New("MBAP 헤더 길이 부족")
+		}
+		return frame[utils.MBAPHeaderSize:], nil
+	}
+	if packet.UseCRC {
+		return format.Unpack(frame)
+	}
+	return frame, nil
+}
+
+// tolerantSplit splits frames with format but never fails. Buffered bytes that
+// cannot start a frame, because the magic does not match or the header is
+// invalid, are returned as they are, so listeners can record and answer a
+// peer that does not follow the framing instead of disconnecting it.
+func tolerantSplit(format utils.FrameFormat) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		if offset := format.FieldOffset(utils.FieldMagic); offset >= 0 && len(data) > offset {
+			n := min(len(data)-offset, len(format.Magic))
+			if !bytes.Equal(data[offset:offset+n], format.Magic[:n]) {
+				return len(data), data, nil
+			}
+		}
+		advance, token, err := format.Split(data, atEOF)
+		if err != nil {
+			return len(data), data, nil
+		}
+		return advance, token, nil
+	}
+}
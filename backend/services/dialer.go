@@ -0,0 +1,105 @@
+package services
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/fake-edge-server/models"
+)
+
+// dialTimeout bounds connection establishment, including the TLS handshake.
+const dialTimeout = 5 * time.Second
+
+// DialServer opens a connection to the target server using its transport,
+// proxy and TLS settings. UDP targets get a connected socket, so writes become
+// datagrams. Failures are returned as *DialError.
+func DialServer(server models.TCPServer, timeout time.Duration) (net.Conn, error) {
+	var conn net.Conn
+	var err error
+	if server.Proxy.Enabled() {
+		conn, err = dialProxy(server.Proxy, server.Address(), timeout)
+	} else if conn, err = net.DialTimeout(server.Network(), server.Address(), timeout); err != nil {
+		err = targetError(err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !server.TLS.Enabled {
+		return conn, nil
+	}
+
+	cfg, err := server.TLS.Config(server.Host)
+	if err != nil {
+		conn.Close()
+		return nil, targetError(err)
+	}
+	tlsConn := tls.Client(conn, cfg)
+	tlsConn.SetDeadline(time.Now().Add(timeout))
+	if err := tlsConn.Handshake(); err != nil {
+		conn.Close()
+		return nil, targetError(fmt.Errorf("TLS 핸드셰이크 실패: %w", err))
+	}
+	tlsConn.SetDeadline(time.Time{})
+	return tlsConn, nil
+}
+
+// CertificateInfo summarizes a peer certificate for status reporting.
+type CertificateInfo struct {
+	Subject      string    `json:"subject"`
+	Issuer       string    `json:"issuer"`
+	SerialNumber string    `json:"serial_number"`
+	DNSNames     []string  `json:"dns_names,omitempty"`
+	NotBefore    time.Time `json:"not_before"`
+	NotAfter     time.Time `json:"not_after"`
+}
+
+// TLSInfo describes the negotiated TLS session of a connection.
+type TLSInfo struct {
+	Version          string            `json:"version"`
+	CipherSuite      string            `json:"cipher_suite"`
+	ServerName       string            `json:"server_name"`
+	PeerCertificates []CertificateInfo `json:"peer_certificates"`
+}
+
+// ConnectionInfo describes an established connection.
+type ConnectionInfo struct {
+	Transport      string     `json:"transport"`
+	RemoteAddr     string     `json:"remote_addr"`
+	Proxy          string     `json:"proxy,omitempty"`
+	LastResponseAt *time.Time `json:"last_response_at,omitempty"`
+	TLS            *TLSInfo   `json:"tls,omitempty"`
+}
+
+// describeConn collects the remote address and TLS session details of conn.
+func describeConn(conn net.Conn) ConnectionInfo {
+	info := ConnectionInfo{RemoteAddr: conn.RemoteAddr().String()}
+	tlsConn, ok := conn.(*tls.Conn)
+	if !ok {
+		return info
+	}
+
+	state := tlsConn.ConnectionState()
+	info.TLS = &TLSInfo{
+		Version:     tls.VersionName(state.Version),
+		CipherSuite: tls.CipherSuiteName(state.CipherSuite),
+		ServerName:  state.ServerName,
+	}
+	for _, cert := range state.PeerCertificates {
+		info.TLS.PeerCertificates = append(info.TLS.PeerCertificates, certificateInfo(cert))
+	}
+	return info
+}
+
+func certificateInfo(cert *x509.Certificate) CertificateInfo {
+	return CertificateInfo{
+		Subject:      cert.Subject.String(),
+		Issuer:       cert.Issuer.String(),
+		SerialNumber: cert.SerialNumber.String(),
+		DNSNames:     cert.DNSNames,
+		NotBefore:    cert.NotBefore,
+		NotAfter:     cert.NotAfter,
+	}
+}
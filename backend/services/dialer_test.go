@@ -0,0 +1,186 @@
+package services
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/fake-edge-server/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testPKI holds a locally generated CA with server and client certificates.
+type testPKI struct {
+	caPEM      string
+	caPool     *x509.CertPool
+	server     tls.Certificate
+	clientCert string
+	clientKey  string
+}
+
+func newTestPKI(t *testing.T) testPKI {
+	t.Helper()
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	caCert, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+
+	issue := func(serial int64, cn string, usage x509.ExtKeyUsage) (string, string) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		tmpl := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: cn},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+			DNSNames:     []string{"edge.test"},
+			IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, tmpl, caCert, &key.PublicKey, caKey)
+		require.NoError(t, err)
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		require.NoError(t, err)
+		certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+		keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+		return string(certPEM), string(keyPEM)
+	}
+
+	pki := testPKI{caPEM: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}))}
+	pki.caPool = x509.NewCertPool()
+	pki.caPool.AddCert(caCert)
+
+	serverCert, serverKey := issue(2, "edge-server", x509.ExtKeyUsageServerAuth)
+	pki.server, err = tls.X509KeyPair([]byte(serverCert), []byte(serverKey))
+	require.NoError(t, err)
+	pki.clientCert, pki.clientKey = issue(3, "tester", x509.ExtKeyUsageClientAuth)
+	return pki
+}
+
+// startTLSEcho starts a TLS listener that requires client certificates and echoes data.
+func startTLSEcho(t *testing.T, pki testPKI) *net.TCPAddr {
+	t.Helper()
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{pki.server},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pki.caPool,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				buf := make([]byte, 1024)
+				for {
+					n, err := conn.Read(buf)
+					if err != nil {
+						return
+					}
+					conn.Write(buf[:n])
+				}
+			}()
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr)
+}
+
+func TestConnectServerWithMutualTLS(t *testing.T) {
+	pki := newTestPKI(t)
+	addr := startTLSEcho(t, pki)
+
+	mgr := NewTCPConnectionManager()
+	server := models.TCPServer{
+		ID:   1,
+		Host: "127.0.0.1",
+		Port: addr.Port,
+		TLS: models.TLSSettings{
+			Enabled:    true,
+			ServerName: "edge.test",
+			CACert:     pki.caPEM,
+			ClientCert: pki.clientCert,
+			ClientKey:  pki.clientKey,
+			MinVersion: "1.2",
+		},
+	}
+	require.NoError(t, mgr.ConnectServer(server))
+	defer mgr.Disconnect(1)
+	assert.Equal(t, "Alive", mgr.GetStatus(1))
+
+	info, ok := mgr.GetInfo(1)
+	require.True(t, ok)
+	require.NotNil(t, info.TLS)
+	assert.NotEmpty(t, info.TLS.CipherSuite)
+	assert.Equal(t, "edge.test", info.TLS.ServerName)
+	assert.Equal(t, "CN=edge-server", info.TLS.PeerCertificates[0].Subject)
+	assert.Equal(t, "CN=test-ca", info.TLS.PeerCertificates[0].Issuer)
+
+	conn, reader := mgr.session(1)
+	_, err := conn.Write([]byte("ping"))
+	require.NoError(t, err)
+	got, err := reader.ReadFrame(nil, time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, "ping", string(got))
+}
+
+func TestConnectServerTLSVerification(t *testing.T) {
+	pki := newTestPKI(t)
+	addr := startTLSEcho(t, pki)
+	mgr := NewTCPConnectionManager()
+
+	// CA 없이 접속하면 인증서 검증에 실패
+	server := models.TCPServer{ID: 1, Host: "127.0.0.1", Port: addr.Port, TLS: models.TLSSettings{
+		Enabled:    true,
+		ClientCert: pki.clientCert,
+		ClientKey:  pki.clientKey,
+	}}
+	assert.Error(t, mgr.ConnectServer(server))
+	assert.Equal(t, "Dead", mgr.GetStatus(1))
+
+	// 랩 인증서용 검증 생략 옵션
+	server.TLS.InsecureSkipVerify = true
+	assert.NoError(t, mgr.ConnectServer(server))
+	mgr.Disconnect(1)
+}
+
+func TestTCPService_SendRequestOverTLS(t *testing.T) {
+	pki := newTestPKI(t)
+	addr := startTLSEcho(t, pki)
+	db := setupTestDB()
+
+	db.Create(&models.TCPServer{Name: "tls-server", Host: "127.0.0.1", Port: addr.Port, TLS: models.TLSSettings{
+		Enabled:    true,
+		CACert:     pki.caPEM,
+		ClientCert: pki.clientCert,
+		ClientKey:  pki.clientKey,
+	}})
+
+	response, err := NewTCPService(db).SendRequest("tls-server", "hello", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", response)
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"encoding/hex"
+	"net"
+	"time"
+
+	"github.com/fake-edge-server/models"
+	"github.com/fake-edge-server/utils"
+)
+
+// nextMsgID returns the next Edge message ID for the server.
+func (p *PacketSender) nextMsgID(serverID uint) uint64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.msgIDs[serverID]++
+	return p.msgIDs[serverID]
+}
+
+// exchangeEdge sends an Edge frame with an auto-assigned message ID and waits
+// for the response carrying the same ID. Frames with other IDs are broadcast as
+// unsolicited messages and do not end the wait.
+func (p *PacketSender) exchangeEdge(conn net.Conn, reader *FrameReader, format utils.FrameFormat, server models.TCPServer, packet models.TCPPacket, data []byte, history *models.TCPPacketHistory) error {
+	msgID := p.nextMsgID(server.ID)
+	payload := utils.BuildEdgePayload(packet.EdgeID, msgID, data)
+	frame, err := format.BuildWithType(packet.NodeType, packet.CommandType, payload)
+	if err != nil {
+		return err
+	}
+	if _, err := conn.Write(frame); err != nil {
+		return err
+	}
+	history.MsgID = msgID
+
+	deadline := time.Now().Add(responseWait(server))
+	for {
+		frame, err := reader.ReadFrame(format.Split, time.Until(deadline))
+		if err != nil {
+			return err
+		}
+		nodeType, commandType, body, err := format.UnpackWithType(frame)
+		if err != nil {
+			return err
+		}
+		_, respID, respData, err := utils.ParseEdgePayload(body)
+		if err == nil && respID == msgID {
+			history.NodeType = nodeType
+			history.CommandType = commandType
+			history.Response = hex.EncodeToString(respData)
+			return nil
+		}
+
+		p.hub.Broadcast(map[string]interface{}{
+			"type":         "unsolicited",
+			"server_id":    server.ID,
+			"node_type":    nodeType,
+			"command_type": commandType,
+			"msg_id":       respID,
+			"payload":      hex.EncodeToString(body),
+		})
+		p.recordDatagram(server, frame)
+	}
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"math/rand"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fake-edge-server/models"
+	"github.com/fake-edge-server/utils"
+)
+
+// faultInjector misbehaves on purpose on one direction of one connection by
+// applying a FaultProfile to every frame written.
+type faultInjector struct {
+	profile models.FaultProfile
+	format  utils.FrameFormat
+	framed  bool
+
+	mu   sync.Mutex
+	rnd  *rand.Rand
+	held []byte // frame held back to be sent after the next one
+}
+
+// newFaultInjector returns nil when the profile injects nothing so callers can
+// skip fault handling entirely.
+func newFaultInjector(profile models.FaultProfile, format utils.FrameFormat, framed bool) *faultInjector {
+	if !profile.Enabled() {
+		return nil
+	}
+	seed := profile.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &faultInjector{profile: profile, format: format, framed: framed, rnd: rand.New(rand.NewSource(seed))}
+}
+
+// faultPlan is what to do with one frame: wait, write the frames in order and
+// optionally reset the connection afterwards.
+type faultPlan struct {
+	delay  time.Duration
+	frames [][]byte
+	reset  bool
+	faults []string
+}
+
+// Faults returns the injected fault names as stored in history.
+func (p faultPlan) Faults() string {
+	return strings.Join(p.faults, ",")
+}
+
+func (fi *faultInjector) hit(rate float64) bool {
+	return rate > 0 && fi.rnd.Float64() < rate
+}
+
+// plan decides which faults to inject into the wire frame. A nil injector
+// passes the frame through unchanged.
+func (fi *faultInjector) plan(frame []byte) faultPlan {
+	if fi == nil {
+		return faultPlan{frames: [][]byte{frame}}
+	}
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+	p := faultPlan{}
+	profile := fi.profile
+	mark := func(name string) { p.faults = append(p.faults, name) }
+
+	if fi.hit(profile.LatencyRate) {
+		base, jitter := profile.Jitter()
+		p.delay = base
+		if jitter > 0 {
+			p.delay += time.Duration(fi.rnd.Int63n(int64(2*jitter)+1)) - jitter
+		}
+		if p.delay < 0 {
+			p.delay = 0
+		}
+		mark(models.FaultLatency)
+	}
+	if fi.hit(profile.ResetRate) {
+		p.reset = true
+		mark(models.FaultReset)
+		return p
+	}
+	if fi.hit(profile.DropRate) {
+		mark(models.FaultDrop)
+		return p
+	}
+
+	out := append([]byte(nil), frame...)
+	if fi.framed && fi.hit(profile.CRCRate) {
+		if broken, ok := fi.format.BreakChecksum(out); ok {
+			out = broken
+			mark(models.FaultCRC)
+		}
+	}
+	if len(out) > 0 && fi.hit(profile.CorruptRate) {
+		bits := profile.CorruptBits
+		if bits == 0 {
+			bits = 1
+		}
+		for i := 0; i < bits; i++ {
+			bit := fi.rnd.Intn(len(out) * 8)
+			out[bit/8] ^= 1 << (bit % 8)
+		}
+		mark(models.FaultCorrupt)
+	}
+	if len(out) > 1 && fi.hit(profile.TruncateRate) {
+		out = out[:1+fi.rnd.Intn(len(out)-1)]
+		mark(models.FaultTruncate)
+	}
+
+	frames := [][]byte{out}
+	if fi.hit(profile.DuplicateRate) {
+		frames = append(frames, out)
+		mark(models.FaultDuplicate)
+	}
+	if fi.held == nil && fi.hit(profile.ReorderRate) {
+		fi.held = out
+		frames = frames[1:]
+		mark(models.FaultReorder)
+	} else if fi.held != nil {
+		frames = append(frames, fi.held)
+		fi.held = nil
+	}
+	p.frames = frames
+	return p
+}
+
+// flush returns the frame held back for reordering, if any, so that it can
+// still be written when the connection is about to close.
+func (fi *faultInjector) flush() []byte {
+	if fi == nil {
+		return nil
+	}
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+	held := fi.held
+	fi.held = nil
+	return held
+}
+
+// written returns the bytes the plan puts on the wire as they are recorded in
+// history: with framing every frame is unpacked, and frames broken by the
+// injected faults are kept as they were written.
+func (p faultPlan) written(format utils.FrameFormat, framed bool) []byte {
+	var out []byte
+	for _, frame := range p.frames {
+		out = append(out, recordedFrame(format, framed, frame)...)
+	}
+	return out
+}
+
+// recordedFrame unpacks a framed frame for history, or returns it unchanged
+// when it is unframed or does not follow the framing.
+func recordedFrame(format utils.FrameFormat, framed bool, frame []byte) []byte {
+	if !framed {
+		return frame
+	}
+	if payload, err := format.Unpack(frame); err == nil {
+		return payload
+	}
+	return frame
+}
+
+// resetConn closes the connection so the peer sees a TCP RST instead of FIN.
+func resetConn(conn net.Conn) {
+	raw := conn
+	if tlsConn, ok := conn.(interface{ NetConn() net.Conn }); ok {
+		raw = tlsConn.NetConn()
+	}
+	if tcp, ok := raw.(*net.TCPConn); ok {
+		tcp.SetLinger(0)
+	}
+	conn.Close()
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"math/bits"
+	"testing"
+	"time"
+
+	"github.com/fake-edge-server/models"
+	"github.com/fake-edge-server/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFaultInjectorPassThrough(t *testing.T) {
+	var fi *faultInjector
+	plan := fi.plan([]byte{0x01})
+	assert.Equal(t, [][]byte{{0x01}}, plan.frames)
+	assert.Empty(t, plan.Faults())
+	assert.Nil(t, newFaultInjector(models.FaultProfile{LatencyMs: 100}, utils.DefaultFrameFormat, false))
+}
+
+func TestFaultInjectorFaults(t *testing.T) {
+	format := utils.DefaultFrameFormat
+	frame, err := format.Build([]byte{0x01, 0x02, 0x03, 0x04})
+	require.NoError(t, err)
+	inject := func(profile models.FaultProfile) faultPlan {
+		profile.Seed = 1
+		return newFaultInjector(profile, format, true).plan(frame)
+	}
+
+	plan := inject(models.FaultProfile{DropRate: 1})
+	assert.Empty(t, plan.frames)
+	assert.Equal(t, "drop", plan.Faults())
+
+	plan = inject(models.FaultProfile{ResetRate: 1, DropRate: 1})
+	assert.True(t, plan.reset)
+	assert.Equal(t, "reset", plan.Faults())
+
+	plan = inject(models.FaultProfile{DuplicateRate: 1})
+	assert.Equal(t, [][]byte{frame, frame}, plan.frames)
+
+	plan = inject(models.FaultProfile{TruncateRate: 1})
+	require.Len(t, plan.frames, 1)
+	assert.Less(t, len(plan.frames[0]), len(frame))
+	assert.Equal(t, frame[:len(plan.frames[0])], plan.frames[0])
+
+	plan = inject(models.FaultProfile{CRCRate: 1})
+	_, err = format.Unpack(plan.frames[0])
+	assert.ErrorContains(t, err, "CRC 불일치")
+	assert.Equal(t, "crc", plan.Faults())
+
+	plan = inject(models.FaultProfile{CorruptRate: 1, CorruptBits: 3})
+	flipped := 0
+	for i := range frame {
+		flipped += bits.OnesCount8(frame[i] ^ plan.frames[0][i])
+	}
+	assert.LessOrEqual(t, flipped, 3)
+	assert.Positive(t, flipped)
+
+	plan = inject(models.FaultProfile{LatencyRate: 1, LatencyMs: 100, JitterMs: 20})
+	assert.GreaterOrEqual(t, plan.delay, 80*time.Millisecond)
+	assert.LessOrEqual(t, plan.delay, 120*time.Millisecond)
+	assert.Equal(t, [][]byte{frame}, plan.frames)
+
+	// CRC 프레임을 쓰지 않으면 체크섬 결함은 적용하지 않음
+	plan = newFaultInjector(models.FaultProfile{CRCRate: 1}, format, false).plan([]byte{0x01})
+	assert.Equal(t, [][]byte{{0x01}}, plan.frames)
+	assert.Empty(t, plan.Faults())
+}
+
+func TestFaultInjectorReorder(t *testing.T) {
+	fi := newFaultInjector(models.FaultProfile{ReorderRate: 1, Seed: 1}, utils.DefaultFrameFormat, false)
+
+	first := fi.plan([]byte{0x01})
+	assert.Empty(t, first.frames)
+	assert.Equal(t, "reorder", first.Faults())
+
+	second := fi.plan([]byte{0x02})
+	assert.Equal(t, [][]byte{{0x02}, {0x01}}, second.frames)
+	assert.Empty(t, second.Faults())
+	assert.Nil(t, fi.flush())
+
+	// 다음 프레임 전에 연결이 끝나면 보류한 프레임을 꺼내 보냄
+	fi.plan([]byte{0x03})
+	assert.Equal(t, []byte{0x03}, fi.flush())
+	assert.Nil(t, fi.flush())
+}
+
+func TestFaultPlanWritten(t *testing.T) {
+	format := utils.DefaultFrameFormat
+	frame, err := format.Build([]byte{0x01, 0x02})
+	require.NoError(t, err)
+
+	// 기록에는 실제로 보낸 프레임을 풀어서 남기고, 결함으로 깨진 프레임은 그대로 남김
+	plan := newFaultInjector(models.FaultProfile{DuplicateRate: 1, Seed: 1}, format, true).plan(frame)
+	assert.Equal(t, []byte{0x01, 0x02, 0x01, 0x02}, plan.written(format, true))
+	plan = newFaultInjector(models.FaultProfile{CRCRate: 1, Seed: 1}, format, true).plan(frame)
+	assert.Equal(t, plan.frames[0], plan.written(format, true))
+	plan = newFaultInjector(models.FaultProfile{DropRate: 1, Seed: 1}, format, true).plan(frame)
+	assert.Empty(t, plan.written(format, true))
+}
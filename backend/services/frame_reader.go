@@ -0,0 +1,243 @@
+package services
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// rawIdleGap is how long a raw (unframed) response may stay silent before the
+// bytes received so far are treated as one complete response.
+const rawIdleGap = 50 * time.Millisecond
+
+// maxFrameBuffer bounds the bytes a stream reader keeps while no complete frame
+// has arrived. When it is exceeded the buffered bytes are dropped.
+const maxFrameBuffer = 4 << 20
+
+// maxDatagramQueue bounds the datagrams a datagram reader keeps unread. When it
+// is exceeded the oldest datagram is dropped.
+const maxDatagramQueue = 1024
+
+// ErrReadTimeout is returned when no complete frame arrives before the timeout.
+var ErrReadTimeout = errors.New("응답 대기 시간 초과")
+
+// FrameReader continuously reads from a connection into a buffer and hands the
+// buffered bytes out one frame at a time. Bytes that arrive after a frame stay
+// buffered for the next ReadFrame call. In datagram mode every datagram is kept
+// separately and is returned as one frame.
+type FrameReader struct {
+	mu       sync.Mutex
+	buf      []byte
+	queue    [][]byte
+	datagram bool
+	lastRead time.Time
+	err      error
+	notify   chan struct{}
+	done     chan struct{}
+}
+
+// NewFrameReader starts reading a byte stream from r in the background.
+func NewFrameReader(r io.Reader) *FrameReader {
+	fr := newFrameReader(false)
+	go fr.pump(r, 4096)
+	return fr
+}
+
+// NewDatagramReader starts reading datagrams from a packet-oriented connection
+// such as a connected UDP socket.
+func NewDatagramReader(r io.Reader) *FrameReader {
+	fr := newFrameReader(true)
+	go fr.pump(r, 65535)
+	return fr
+}
+
+func newFrameReader(datagram bool) *FrameReader {
+	return &FrameReader{
+		datagram: datagram,
+		notify:   make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// pump stores everything read from r until r fails. Refused datagrams
+// (ICMP port unreachable) do not stop a datagram reader.
+func (fr *FrameReader) pump(r io.Reader, size int) {
+	chunk := make([]byte, size)
+	for {
+		n, err := r.Read(chunk)
+		if fr.datagram && errors.Is(err, syscall.ECONNREFUSED) {
+			continue
+		}
+		fr.mu.Lock()
+		if n > 0 {
+			if fr.datagram {
+				if len(fr.queue) >= maxDatagramQueue {
+					fr.queue = fr.queue[1:]
+				}
+				fr.queue = append(fr.queue, append([]byte(nil), chunk[:n]...))
+			} else {
+				if len(fr.buf)+n > maxFrameBuffer {
+					log.Printf("frame reader: no frame in %d bytes, dropping them", len(fr.buf))
+					fr.buf = nil
+				}
+				fr.buf = append(fr.buf, chunk[:n]...)
+			}
+			fr.lastRead = time.Now()
+		}
+		if err != nil {
+			fr.err = err
+			close(fr.done)
+		}
+		close(fr.notify)
+		fr.notify = make(chan struct{})
+		fr.mu.Unlock()
+		if err != nil {
+			return
+		}
+	}
+}
+
+// LastRead returns when data was last received. It is zero if nothing arrived yet.
+func (fr *FrameReader) LastRead() time.Time {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	return fr.lastRead
+}
+
+// Done is closed once the underlying reader returns an error.
+func (fr *FrameReader) Done() <-chan struct{} {
+	return fr.done
+}
+
+// Err returns the error that stopped the reader, if any.
+func (fr *FrameReader) Err() error {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	return fr.err
+}
+
+// Drain removes and returns the complete frames buffered so far without
+// waiting for more data; an incomplete frame stays buffered. A nil split takes
+// everything buffered as one frame, bytes that do not split into a frame are
+// returned as one frame as well, and in datagram mode every queued datagram is
+// returned whole. Call it before writing a request whose reply cannot be told
+// apart from frames that arrived earlier, such as a late reply to a timed-out
+// request, and report what it returns instead of dropping it.
+func (fr *FrameReader) Drain(split bufio.SplitFunc) [][]byte {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	if fr.datagram {
+		frames := fr.queue
+		fr.queue = nil
+		return frames
+	}
+	var frames [][]byte
+	for len(fr.buf) > 0 {
+		if split == nil {
+			frames = append(frames, fr.buf)
+			fr.buf = nil
+			break
+		}
+		advance, token, err := split(fr.buf, false)
+		if err != nil {
+			frames = append(frames, fr.buf)
+			fr.buf = nil
+			break
+		}
+		if token == nil {
+			break
+		}
+		frames = append(frames, append([]byte(nil), token...))
+		fr.buf = fr.buf[advance:]
+	}
+	return frames
+}
+
+// ReadFrame waits until split reports a complete frame and returns it.
+// A nil split reads raw data: everything received is returned once the
+// stream stays idle for rawIdleGap or is closed.
+func (fr *FrameReader) ReadFrame(split bufio.SplitFunc, timeout time.Duration) ([]byte, error) {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	var idle <-chan time.Time
+	idleExpired := false
+
+	for {
+		fr.mu.Lock()
+		eof := fr.err != nil
+		if fr.datagram {
+			if len(fr.queue) > 0 {
+				packet := fr.queue[0]
+				fr.queue = fr.queue[1:]
+				fr.mu.Unlock()
+				return splitDatagram(packet, split)
+			}
+		} else if split != nil {
+			advance, token, err := split(fr.buf, eof)
+			if err != nil {
+				fr.buf = nil
+				fr.mu.Unlock()
+				return nil, err
+			}
+			if token != nil {
+				frame := append([]byte(nil), token...)
+				fr.buf = fr.buf[advance:]
+				fr.mu.Unlock()
+				return frame, nil
+			}
+		} else if len(fr.buf) > 0 && (eof || idleExpired) {
+			frame := fr.buf
+			fr.buf = nil
+			fr.mu.Unlock()
+			return frame, nil
+		}
+		if eof {
+			err := fr.err
+			fr.mu.Unlock()
+			if split == nil && err == io.EOF {
+				return []byte{}, nil
+			}
+			return nil, err
+		}
+		wait := fr.notify
+		pending := len(fr.buf)
+		fr.mu.Unlock()
+		if split == nil && pending > 0 && idle == nil {
+			idle = time.After(rawIdleGap)
+		}
+
+		select {
+		case <-wait:
+			if split == nil {
+				idle = time.After(rawIdleGap)
+			}
+		case <-idle:
+			idleExpired = true
+		case <-deadline.C:
+			fr.mu.Lock()
+			fr.buf = nil
+			fr.mu.Unlock()
+			return nil, fmt.Errorf("%w: %d바이트 수신", ErrReadTimeout, pending)
+		}
+	}
+}
+
+// splitDatagram applies split to a single datagram, which must hold a whole frame.
+func splitDatagram(packet []byte, split bufio.SplitFunc) ([]byte, error) {
+	if split == nil {
+		return packet, nil
+	}
+	_, token, err := split(packet, true)
+	if err != nil {
+		return nil, err
+	}
+	if token == nil {
+		return nil, errors.New("전체 패킷 미도착")
+	}
+	return token, nil
+}
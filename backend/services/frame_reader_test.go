@@ -0,0 +1,137 @@
+package services
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/fake-edge-server/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFrameReaderReassemblesSegments(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	reader := NewFrameReader(client)
+
+	frame := utils.BuildPacket(make([]byte, 6000))
+	go func() {
+		server.Write(frame[:5])
+		time.Sleep(20 * time.Millisecond)
+		server.Write(frame[5:4100])
+		time.Sleep(20 * time.Millisecond)
+		server.Write(frame[4100:])
+	}()
+
+	got, err := reader.ReadFrame(utils.SplitPacket, time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, frame, got)
+}
+
+func TestFrameReaderKeepsTrailingBytes(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	reader := NewFrameReader(client)
+
+	first := utils.BuildPacket([]byte{1, 2, 3})
+	second := utils.BuildPacket([]byte{4, 5})
+	go server.Write(append(append([]byte{}, first...), second...))
+
+	got, err := reader.ReadFrame(utils.SplitPacket, time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, first, got)
+
+	got, err = reader.ReadFrame(utils.SplitPacket, time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, second, got)
+}
+
+func TestFrameReaderRawWaitsForIdle(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	reader := NewFrameReader(client)
+
+	go func() {
+		server.Write([]byte("hello "))
+		time.Sleep(10 * time.Millisecond)
+		server.Write([]byte("world"))
+	}()
+
+	got, err := reader.ReadFrame(nil, time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello world", string(got))
+}
+
+func TestFrameReaderTimeout(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+	reader := NewFrameReader(client)
+
+	frame := utils.BuildPacket([]byte{1, 2, 3})
+	go server.Write(frame[:len(frame)-1])
+
+	_, err := reader.ReadFrame(utils.SplitPacket, 100*time.Millisecond)
+	assert.True(t, errors.Is(err, ErrReadTimeout))
+}
+
+func TestFrameReaderDrainsLateReply(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+	reader := NewFrameReader(client)
+
+	// 시간 초과 뒤에 도착한 응답은 다음 요청 전에 꺼내 따로 처리함
+	_, err := reader.ReadFrame(nil, 50*time.Millisecond)
+	assert.True(t, errors.Is(err, ErrReadTimeout))
+	server.Write([]byte("late"))
+	assert.Eventually(t, func() bool { return !reader.LastRead().IsZero() }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, [][]byte{[]byte("late")}, reader.Drain(nil))
+	assert.Empty(t, reader.Drain(nil))
+
+	go server.Write([]byte("reply"))
+	got, err := reader.ReadFrame(nil, time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, "reply", string(got))
+}
+
+func TestFrameReaderDrainKeepsPartialFrame(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+	reader := NewFrameReader(client)
+
+	// 완성된 프레임만 꺼내고 뒤따르는 미완성 프레임은 다음 읽기를 위해 남겨 둠
+	first := utils.BuildPacket([]byte{1})
+	second := utils.BuildPacket([]byte{2})
+	third := utils.BuildPacket([]byte{3})
+	wire := append(append(append([]byte(nil), first...), second...), third[:4]...)
+	go server.Write(wire)
+	assert.Eventually(t, func() bool { return !reader.LastRead().IsZero() }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, [][]byte{first, second}, reader.Drain(utils.SplitPacket))
+
+	go server.Write(third[4:])
+	got, err := reader.ReadFrame(utils.SplitPacket, time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, third, got)
+}
+
+func TestFrameReaderBufferLimit(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+	reader := NewFrameReader(client)
+
+	// 프레임이 끝나지 않는 데이터가 한도를 넘으면 쌓인 바이트를 버림
+	chunk := make([]byte, 4096)
+	for sent := 0; sent < maxFrameBuffer+1<<20; sent += len(chunk) {
+		server.Write(chunk)
+	}
+	buffered := func() int {
+		reader.mu.Lock()
+		defer reader.mu.Unlock()
+		return len(reader.buf)
+	}
+	assert.Eventually(t, func() bool { return buffered() == 1<<20 }, time.Second, 5*time.Millisecond)
+}
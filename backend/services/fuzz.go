@@ -0,0 +1,372 @@
+package services
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/fake-edge-server/models"
+	"gorm.io/gorm"
+)
+
+// fuzzProgressInterval is how often a running fuzz job broadcasts progress.
+const fuzzProgressInterval = time.Second
+
+// fuzzRun is a running fuzz job.
+type fuzzRun struct {
+	job      models.FuzzJob
+	server   models.TCPServer
+	mutator  *fuzzMutator
+	timeout  time.Duration
+	seq      uint64
+	executed atomic.Int64
+	crashes  atomic.Int64
+	stop     chan struct{}
+	once     sync.Once
+}
+
+// stopped reports whether the job was asked to stop.
+func (run *fuzzRun) stopped() bool {
+	select {
+	case <-run.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// FuzzRunner runs fuzz jobs that send mutated packets to a server over a
+// connection of their own and save the inputs that crash the device.
+type FuzzRunner struct {
+	mu   sync.Mutex
+	runs map[uint]*fuzzRun
+	db   *gorm.DB
+	hub  *WebSocketHub
+}
+
+// NewFuzzRunner creates a new FuzzRunner.
+func NewFuzzRunner(db *gorm.DB, hub *WebSocketHub) *FuzzRunner {
+	return &FuzzRunner{
+		runs: make(map[uint]*fuzzRun),
+		db:   db,
+		hub:  hub,
+	}
+}
+
+// Start runs the fuzz job in the background. The job must already be saved;
+// its status and counts are written back when it ends. It fails when none of
+// the job's mutations apply to the packet.
+func (r *FuzzRunner) Start(job models.FuzzJob, server models.TCPServer, packet models.TCPPacket) error {
+	format, err := server.Framing.Format()
+	if err != nil {
+		return err
+	}
+	mutator, err := newFuzzMutator(format, packet, job.Seed, job.Mutations)
+	if err != nil {
+		return err
+	}
+	run := &fuzzRun{
+		job:     job,
+		server:  server,
+		mutator: mutator,
+		timeout: fuzzTimeout(job, server),
+		stop:    make(chan struct{}),
+	}
+
+	r.mu.Lock()
+	if _, ok := r.runs[job.ID]; ok {
+		r.mu.Unlock()
+		return fmt.Errorf("퍼징 작업[%d]이 이미 실행 중입니다", job.ID)
+	}
+	r.runs[job.ID] = run
+	r.mu.Unlock()
+
+	go r.run(run)
+	return nil
+}
+
+// Stop ends a running fuzz job after the input in flight.
+func (r *FuzzRunner) Stop(id uint) error {
+	r.mu.Lock()
+	run, ok := r.runs[id]
+	r.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("퍼징 작업[%d]이 실행 중이 아닙니다", id)
+	}
+	run.once.Do(func() { close(run.stop) })
+	return nil
+}
+
+// Progress returns the live counts of a running fuzz job.
+func (r *FuzzRunner) Progress(id uint) (executed, crashes int, ok bool) {
+	r.mu.Lock()
+	run, ok := r.runs[id]
+	r.mu.Unlock()
+	if !ok {
+		return 0, 0, false
+	}
+	return int(run.executed.Load()), int(run.crashes.Load()), true
+}
+
+// IsRunning reports whether the fuzz job is running.
+func (r *FuzzRunner) IsRunning(id uint) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.runs[id]
+	return ok
+}
+
+// fuzzTimeout returns how long to wait for a response to each input.
+func fuzzTimeout(job models.FuzzJob, server models.TCPServer) time.Duration {
+	if job.TimeoutMs > 0 {
+		return time.Duration(job.TimeoutMs) * time.Millisecond
+	}
+	return responseWait(server)
+}
+
+func (r *FuzzRunner) run(run *fuzzRun) {
+	status, reason := r.fuzz(run)
+	job := run.job
+	executed, crashes := int(run.executed.Load()), int(run.crashes.Load())
+	err := r.db.Model(&models.FuzzJob{}).Where("id = ?", job.ID).Updates(map[string]interface{}{
+		"status":      status,
+		"error":       reason,
+		"executed":    executed,
+		"crashes":     crashes,
+		"finished_at": time.Now(),
+	}).Error
+	if err != nil {
+		log.Print(err)
+	}
+
+	r.mu.Lock()
+	delete(r.runs, job.ID)
+	r.mu.Unlock()
+	r.hub.Broadcast(map[string]interface{}{
+		"type":        "fuzz_done",
+		"fuzz_job_id": job.ID,
+		"server_id":   job.TCPServerID,
+		"status":      status,
+		"error":       reason,
+		"executed":    executed,
+		"crashes":     crashes,
+	})
+}
+
+// fuzz sends the mutated inputs and returns the final status. Before fuzzing
+// the unmutated packet must get a response, otherwise every timeout would look
+// like a crash. A disconnect or reset after an input is a crash. A timeout is
+// only a crash when the device then also ignores the unmutated packet, since
+// devices may legitimately drop malformed input.
+func (r *FuzzRunner) fuzz(run *fuzzRun) (string, string) {
+	if !r.alive(run) {
+		return models.FuzzFailed, "기준 패킷에 응답이 없습니다"
+	}
+
+	var conn net.Conn
+	var reader *FrameReader
+	defer func() {
+		if conn != nil {
+			conn.Close()
+		}
+	}()
+	progressed := time.Now()
+	for i := 1; i <= run.job.Iterations; i++ {
+		if run.stopped() {
+			return models.FuzzStopped, ""
+		}
+		if conn == nil {
+			c, err := DialServer(run.server, dialTimeout)
+			if err != nil {
+				return models.FuzzFailed, "서버에 접속할 수 없습니다: " + err.Error()
+			}
+			conn, reader = c, newFuzzReader(run.server, c)
+		}
+
+		run.seq++
+		input := run.mutator.next(run.seq)
+		_, split := run.mutator.baseline(run.seq)
+		// A late reply to the previous input is not this input's response.
+		for _, frame := range reader.Drain(split) {
+			r.unsolicited(run, frame)
+		}
+		crash, _, err := exchangeFuzz(conn, reader, input.wire, split, run.timeout)
+		run.executed.Add(1)
+		if err != nil {
+			// 응답이 깨졌거나 연결이 끊겼으면 다음 입력은 새 연결로 보냄
+			conn.Close()
+			conn = nil
+		}
+		if crash == models.FuzzCrashTimeout && r.alive(run) {
+			crash = ""
+		}
+		if crash != "" {
+			r.saveCase(run, i, input, crash, err)
+			if run.job.StopOnCrash {
+				return models.FuzzStopped, ""
+			}
+		}
+
+		if time.Since(progressed) >= fuzzProgressInterval {
+			progressed = time.Now()
+			r.hub.Broadcast(map[string]interface{}{
+				"type":        "fuzz_progress",
+				"fuzz_job_id": run.job.ID,
+				"server_id":   run.job.TCPServerID,
+				"executed":    run.executed.Load(),
+				"crashes":     run.crashes.Load(),
+			})
+		}
+	}
+	return models.FuzzCompleted, ""
+}
+
+// unsolicited broadcasts a frame that arrived before an input was sent, such
+// as a late reply to the previous input.
+func (r *FuzzRunner) unsolicited(run *fuzzRun, frame []byte) {
+	r.hub.Broadcast(map[string]interface{}{
+		"type":        "unsolicited",
+		"fuzz_job_id": run.job.ID,
+		"server_id":   run.job.TCPServerID,
+		"payload":     hex.EncodeToString(frame),
+	})
+}
+
+// alive sends the unmutated packet on a new connection and reports whether the
+// device responds.
+func (r *FuzzRunner) alive(run *fuzzRun) bool {
+	run.seq++
+	wire, split := run.mutator.baseline(run.seq)
+	return probeServer(run.server, wire, split, run.timeout)
+}
+
+// saveCase stores a crashing input and broadcasts it.
+func (r *FuzzRunner) saveCase(run *fuzzRun, iteration int, input fuzzInput, crash string, err error) {
+	run.crashes.Add(1)
+	fc := models.FuzzCase{
+		FuzzJobID:   run.job.ID,
+		TCPServerID: run.job.TCPServerID,
+		TCPPacketID: run.job.TCPPacketID,
+		Iteration:   iteration,
+		Mutation:    input.mutation,
+		Offset:      input.offset,
+		Detail:      input.detail,
+		Input:       hex.EncodeToString(input.wire),
+		Crash:       crash,
+	}
+	if err != nil {
+		fc.Error = err.Error()
+	}
+	if err := r.db.Create(&fc).Error; err != nil {
+		log.Print(err)
+	}
+	r.db.Model(&models.FuzzJob{}).Where("id = ?", run.job.ID).Updates(map[string]interface{}{
+		"executed": run.executed.Load(),
+		"crashes":  run.crashes.Load(),
+	})
+	r.hub.Broadcast(map[string]interface{}{
+		"type":        "fuzz_crash",
+		"fuzz_job_id": run.job.ID,
+		"server_id":   run.job.TCPServerID,
+		"case":        fc,
+	})
+}
+
+// Replay sends a saved crashing input again on a new connection and reports
+// whether it still crashes the device and whether the device responds to the
+// unmutated packet afterwards.
+func (r *FuzzRunner) Replay(job models.FuzzJob, fc models.FuzzCase, server models.TCPServer, packet models.TCPPacket) (models.FuzzReplayResult, error) {
+	var result models.FuzzReplayResult
+	input, err := hex.DecodeString(fc.Input)
+	if err != nil {
+		return result, fmt.Errorf("입력 HEX 형식이 올바르지 않습니다: %v", err)
+	}
+	format, err := server.Framing.Format()
+	if err != nil {
+		return result, err
+	}
+	payload, err := packetPayload(packet)
+	if err != nil {
+		return result, err
+	}
+	baseline, split, err := encodePayload(format, packet, payload, 1)
+	if err != nil {
+		return result, err
+	}
+	timeout := fuzzTimeout(job, server)
+
+	conn, err := DialServer(server, dialTimeout)
+	if err != nil {
+		return result, err
+	}
+	crash, response, err := exchangeFuzz(conn, newFuzzReader(server, conn), input, split, timeout)
+	conn.Close()
+	result.Alive = probeServer(server, baseline, split, timeout)
+	if crash == models.FuzzCrashTimeout && result.Alive {
+		crash = ""
+	}
+	result.Crash = crash
+	result.Response = hex.EncodeToString(response)
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result, nil
+}
+
+// probeServer sends wire on a new connection and reports whether any response
+// frame arrives within the timeout.
+func probeServer(server models.TCPServer, wire []byte, split bufio.SplitFunc, timeout time.Duration) bool {
+	conn, err := DialServer(server, dialTimeout)
+	if err != nil {
+		return false
+	}
+	defer conn.Close()
+	_, _, err = exchangeFuzz(conn, newFuzzReader(server, conn), wire, split, timeout)
+	return err == nil
+}
+
+func newFuzzReader(server models.TCPServer, conn net.Conn) *FrameReader {
+	if server.IsDatagram() {
+		return NewDatagramReader(conn)
+	}
+	return NewFrameReader(conn)
+}
+
+// exchangeFuzz writes one input and reads one response. It returns the crash
+// kind when the write or read failed in a way that points at the device, or an
+// empty kind with the error when the response was merely malformed.
+func exchangeFuzz(conn net.Conn, reader *FrameReader, wire []byte, split bufio.SplitFunc, timeout time.Duration) (string, []byte, error) {
+	if _, err := conn.Write(wire); err != nil {
+		return crashKind(err), nil, err
+	}
+	response, err := reader.ReadFrame(split, timeout)
+	if err == nil && len(response) == 0 && reader.Err() != nil {
+		err = reader.Err() // raw 모드는 연결이 끊기면 빈 응답을 반환함
+	}
+	if err != nil {
+		return crashKind(err), nil, err
+	}
+	return "", response, nil
+}
+
+// crashKind classifies an I/O error as a crash kind. Other errors, such as a
+// response that does not split into a frame, are not crashes.
+func crashKind(err error) string {
+	switch {
+	case errors.Is(err, ErrReadTimeout):
+		return models.FuzzCrashTimeout
+	case errors.Is(err, syscall.ECONNRESET):
+		return models.FuzzCrashReset
+	case errors.Is(err, io.EOF), errors.Is(err, syscall.EPIPE), errors.Is(err, net.ErrClosed):
+		return models.FuzzCrashDisconnect
+	}
+	return ""
+}
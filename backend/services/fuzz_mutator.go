@@ -0,0 +1,365 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/fake-edge-server/models"
+	"github.com/fake-edge-server/utils"
+)
+
+// dataTypeNames names data types in fuzz case details.
+var dataTypeNames = map[models.DataType]string{
+	models.TypeInt8:    "int8",
+	models.TypeInt16:   "int16",
+	models.TypeInt32:   "int32",
+	models.TypeInt64:   "int64",
+	models.TypeUint8:   "uint8",
+	models.TypeUint16:  "uint16",
+	models.TypeUint32:  "uint32",
+	models.TypeUint64:  "uint64",
+	models.TypeFloat32: "float32",
+	models.TypeFloat64: "float64",
+	models.TypeString:  "string",
+	models.TypeHex:     "hex",
+	models.TypeJSON:    "json",
+}
+
+func isIntegerType(dt models.DataType) bool {
+	return dt >= models.TypeInt8 && dt <= models.TypeUint64
+}
+
+func isSignedType(dt models.DataType) bool {
+	return dt >= models.TypeInt8 && dt <= models.TypeInt64
+}
+
+func isFloatType(dt models.DataType) bool {
+	return dt == models.TypeFloat32 || dt == models.TypeFloat64
+}
+
+func isTextType(dt models.DataType) bool {
+	return dt == models.TypeString || dt == models.TypeHex
+}
+
+// fuzzValue is a named replacement value for a field.
+type fuzzValue struct {
+	label string
+	value []byte
+}
+
+// floatSpecials are the float values that commonly break parsers and math.
+var floatSpecials = []struct {
+	label string
+	value float64
+}{
+	{"NaN", math.NaN()},
+	{"+Inf", math.Inf(1)},
+	{"-Inf", math.Inf(-1)},
+	{"-0", math.Copysign(0, -1)},
+	{"max", math.MaxFloat64},
+	{"smallest", math.SmallestNonzeroFloat64},
+}
+
+// overlongValues replace string and hex fields. The frame length is rebuilt
+// around them, so they stay structurally valid.
+var overlongValues = []fuzzValue{
+	{"256 bytes", bytes.Repeat([]byte("A"), 256)},
+	{"1024 bytes", bytes.Repeat([]byte("A"), 1024)},
+	{"16384 bytes", bytes.Repeat([]byte("A"), 16384)},
+	{"format string", bytes.Repeat([]byte("%s%n%x"), 32)},
+	{"non-utf8", bytes.Repeat([]byte{0xff, 0xfe, 0xc0, 0x80}, 64)},
+	{"embedded nul", append(bytes.Repeat([]byte("A"), 128), append([]byte{0}, bytes.Repeat([]byte("B"), 128)...)...)},
+}
+
+// invalidJSON replaces JSON fields.
+var invalidJSON = []fuzzValue{
+	{"unterminated object", []byte(`{"a":`)},
+	{"trailing comma", []byte(`{"a":1,}`)},
+	{"single quotes", []byte(`{'a':1}`)},
+	{"deep nesting", bytes.Repeat([]byte("["), 4096)},
+	{"huge number", []byte(`{"a":1e999999}`)},
+	{"lone surrogate", []byte(`{"a":"\ud800"}`)},
+	{"bare NaN", []byte(`NaN`)},
+	{"empty", []byte{}},
+}
+
+// fuzzInput is one mutated input ready to be written.
+type fuzzInput struct {
+	mutation string
+	offset   int // mutated field offset, -1 for frame-level mutations
+	detail   string
+	wire     []byte
+}
+
+// fuzzMutator derives hostile but structurally valid inputs from a packet
+// definition. Field mutations follow the field's data type and the packet is
+// framed afterwards, so length and checksum fields match the mutated payload.
+// Frame-level mutations break exactly one of them on purpose.
+type fuzzMutator struct {
+	rnd       *rand.Rand
+	format    utils.FrameFormat
+	packet    models.TCPPacket
+	base      []byte
+	fields    []models.DecodedField
+	framed    bool
+	mutations []string
+}
+
+// newFuzzMutator keeps the requested mutations that apply to the packet, or all
+// applicable ones when none are requested. It fails when none apply.
+func newFuzzMutator(format utils.FrameFormat, packet models.TCPPacket, seed int64, requested []string) (*fuzzMutator, error) {
+	base, err := packetPayload(packet)
+	if err != nil {
+		return nil, err
+	}
+	m := &fuzzMutator{
+		rnd:    rand.New(rand.NewSource(seed)),
+		format: format,
+		packet: packet,
+		base:   base,
+		framed: packet.Kind == models.PacketKindEdge || (packet.Kind != models.PacketKindModbus && packet.UseCRC),
+	}
+	if packet.Kind == models.PacketKindModbus {
+		// Modbus 데이터 정의 대신 PDU 전체를 하나의 HEX 필드로 다룸
+		m.fields = []models.DecodedField{{Offset: 0, Length: len(base), Type: models.TypeHex}}
+	} else {
+		m.fields = packet.Data.Fields()
+	}
+
+	if _, _, err := encodePayload(format, packet, base, 1); err != nil {
+		return nil, err
+	}
+
+	if len(requested) == 0 {
+		requested = models.Mutations
+	}
+	for _, mutation := range requested {
+		if m.applies(mutation) {
+			m.mutations = append(m.mutations, mutation)
+		}
+	}
+	if len(m.mutations) == 0 {
+		return nil, errors.New("패킷에 적용할 수 있는 변형이 없습니다")
+	}
+	return m, nil
+}
+
+func (m *fuzzMutator) applies(mutation string) bool {
+	switch mutation {
+	case models.MutationBoundary:
+		return m.has(isIntegerType)
+	case models.MutationFloat:
+		return m.has(isFloatType)
+	case models.MutationOverlong:
+		return m.has(isTextType)
+	case models.MutationJSON:
+		return m.has(func(dt models.DataType) bool { return dt == models.TypeJSON })
+	case models.MutationBitFlip:
+		return len(m.fields) > 0
+	case models.MutationLength:
+		return true
+	case models.MutationCRC:
+		return m.framed && m.format.FieldOffset(utils.FieldChecksum) >= 0
+	}
+	return false
+}
+
+func (m *fuzzMutator) has(match func(models.DataType) bool) bool {
+	for _, field := range m.fields {
+		if match(field.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+// pick returns a random field whose type matches.
+func (m *fuzzMutator) pick(match func(models.DataType) bool) models.DecodedField {
+	var candidates []models.DecodedField
+	for _, field := range m.fields {
+		if match(field.Type) {
+			candidates = append(candidates, field)
+		}
+	}
+	return candidates[m.rnd.Intn(len(candidates))]
+}
+
+// baseline returns the unmutated packet, used to check the device still responds.
+// newFuzzMutator has checked that it can be encoded.
+func (m *fuzzMutator) baseline(seq uint64) ([]byte, bufio.SplitFunc) {
+	wire, split, _ := encodePayload(m.format, m.packet, m.base, seq)
+	return wire, split
+}
+
+// fits reports whether field can be replaced by value without the payload
+// outgrowing the frame's length field.
+func (m *fuzzMutator) fits(field models.DecodedField, value fuzzValue) bool {
+	if !m.framed {
+		return true
+	}
+	size := len(m.base) - field.Length + len(value.value)
+	if m.packet.Kind == models.PacketKindEdge {
+		size += utils.EdgeTypeSize + utils.EdgePayloadOffset
+	}
+	return uint64(size) <= m.format.MaxPayload()
+}
+
+// replacement picks a random value from values that fits in place of field.
+// When none fits, the frame could not carry the mutated payload, so the field's
+// bits are flipped instead and input is marked as a bit flip.
+func (m *fuzzMutator) replacement(input *fuzzInput, field models.DecodedField, values []fuzzValue) fuzzValue {
+	var fitting []fuzzValue
+	for _, value := range values {
+		if m.fits(field, value) {
+			fitting = append(fitting, value)
+		}
+	}
+	if len(fitting) == 0 {
+		input.mutation = models.MutationBitFlip
+		return m.bitFlip(field)
+	}
+	return fitting[m.rnd.Intn(len(fitting))]
+}
+
+// next returns the next mutated input. seq is the Edge message ID or Modbus
+// transaction ID of the input.
+func (m *fuzzMutator) next(seq uint64) fuzzInput {
+	mutation := m.mutations[m.rnd.Intn(len(m.mutations))]
+	input := fuzzInput{mutation: mutation, offset: -1}
+
+	var field models.DecodedField
+	var value fuzzValue
+	switch mutation {
+	case models.MutationBoundary:
+		field = m.pick(isIntegerType)
+		value = m.boundary(field)
+	case models.MutationFloat:
+		field = m.pick(isFloatType)
+		value = m.floatSpecial(field)
+	case models.MutationOverlong:
+		field = m.pick(isTextType)
+		value = m.replacement(&input, field, overlongValues)
+	case models.MutationJSON:
+		field = m.pick(func(dt models.DataType) bool { return dt == models.TypeJSON })
+		value = m.replacement(&input, field, invalidJSON)
+	case models.MutationBitFlip:
+		field = m.pick(func(models.DataType) bool { return true })
+		value = m.bitFlip(field)
+	case models.MutationLength:
+		input.detail, input.wire = m.lengthMismatch(seq)
+		return input
+	case models.MutationCRC:
+		wire, _ := m.baseline(seq)
+		input.wire, _ = m.format.BreakChecksum(wire)
+		return input
+	}
+
+	payload := make([]byte, 0, len(m.base)+len(value.value))
+	payload = append(payload, m.base[:field.Offset]...)
+	payload = append(payload, value.value...)
+	payload = append(payload, m.base[field.Offset+field.Length:]...)
+	input.offset = field.Offset
+	input.detail = dataTypeNames[field.Type] + " " + value.label
+	// The payload fits: it is the base payload or a fitting replacement.
+	input.wire, _, _ = encodePayload(m.format, m.packet, payload, seq)
+	return input
+}
+
+// boundary returns an edge value of the field's integer type, written little
+// endian over the field's length.
+func (m *fuzzMutator) boundary(field models.DecodedField) fuzzValue {
+	n := field.Length
+	fill := func(b byte, top byte) []byte {
+		out := bytes.Repeat([]byte{b}, n)
+		out[n-1] = top
+		return out
+	}
+	one := make([]byte, n)
+	one[0] = 1
+	values := []fuzzValue{{"zero", make([]byte, n)}, {"one", one}}
+	if isSignedType(field.Type) {
+		values = append(values,
+			fuzzValue{"min", fill(0x00, 0x80)},
+			fuzzValue{"max", fill(0xff, 0x7f)},
+			fuzzValue{"-1", fill(0xff, 0xff)},
+		)
+	} else {
+		values = append(values,
+			fuzzValue{"max", fill(0xff, 0xff)},
+			fuzzValue{"max-1", append([]byte{0xfe}, bytes.Repeat([]byte{0xff}, n-1)...)},
+			fuzzValue{"high bit", fill(0x00, 0x80)},
+		)
+	}
+	return values[m.rnd.Intn(len(values))]
+}
+
+// floatSpecial returns a special float value encoded for the field's length.
+func (m *fuzzMutator) floatSpecial(field models.DecodedField) fuzzValue {
+	special := floatSpecials[m.rnd.Intn(len(floatSpecials))]
+	var buf []byte
+	if field.Length == 4 {
+		buf = binary.LittleEndian.AppendUint32(nil, math.Float32bits(float32(special.value)))
+	} else {
+		buf = binary.LittleEndian.AppendUint64(nil, math.Float64bits(special.value))
+	}
+	// 체인되지 않은 항목은 타입 크기보다 짧으므로 상위 바이트를 남김
+	value := make([]byte, field.Length)
+	copy(value, buf[max(0, len(buf)-field.Length):])
+	return fuzzValue{special.label, value}
+}
+
+// bitFlip flips one to eight random bits of the field.
+func (m *fuzzMutator) bitFlip(field models.DecodedField) fuzzValue {
+	value := append([]byte(nil), m.base[field.Offset:field.Offset+field.Length]...)
+	flips := 1 + m.rnd.Intn(min(8, len(value)*8))
+	for i := 0; i < flips; i++ {
+		bit := m.rnd.Intn(len(value) * 8)
+		value[bit/8] ^= 1 << (bit % 8)
+	}
+	return fuzzValue{fmt.Sprintf("%d bits", flips), value}
+}
+
+// lengthMismatch makes the declared length disagree with the data: the frame
+// header length for framed packets, the MBAP length for Modbus packets and the
+// data length itself for unframed raw packets.
+func (m *fuzzMutator) lengthMismatch(seq uint64) (string, []byte) {
+	wire, _ := m.baseline(seq)
+	switch {
+	case m.framed:
+		actual := uint32(len(wire) - m.format.HeaderSize())
+		limit := uint32(math.MaxUint32)
+		if m.format.LengthSize == 2 {
+			limit = math.MaxUint16
+		}
+		length := m.mismatched(actual, limit)
+		out, _ := m.format.WithLength(wire, length)
+		return fmt.Sprintf("length %d (actual %d)", length, actual), out
+	case m.packet.Kind == models.PacketKindModbus:
+		actual := uint32(binary.BigEndian.Uint16(wire[4:6]))
+		length := m.mismatched(actual, math.MaxUint16)
+		out := append([]byte(nil), wire...)
+		binary.BigEndian.PutUint16(out[4:6], uint16(length))
+		return fmt.Sprintf("length %d (actual %d)", length, actual), out
+	}
+	if len(wire) > 1 && m.rnd.Intn(2) == 0 {
+		n := 1 + m.rnd.Intn(len(wire)-1)
+		return fmt.Sprintf("truncated to %d bytes", n), wire[:n]
+	}
+	extra := make([]byte, 1+m.rnd.Intn(64))
+	m.rnd.Read(extra)
+	return fmt.Sprintf("extended by %d bytes", len(extra)), append(append([]byte(nil), wire...), extra...)
+}
+
+// mismatched picks a length other than actual: zero, one off or the maximum.
+func (m *fuzzMutator) mismatched(actual, limit uint32) uint32 {
+	candidates := []uint32{actual + 1, limit}
+	if actual > 0 {
+		candidates = append(candidates, 0, actual-1)
+	}
+	return candidates[m.rnd.Intn(len(candidates))]
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/fake-edge-server/models"
+	"github.com/fake-edge-server/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fuzzTestPacket() models.TCPPacket {
+	return models.TCPPacket{
+		Name:   "fuzz",
+		UseCRC: true,
+		Data: models.PacketData{
+			{Offset: 0, Value: 0x10, Type: models.TypeUint8},
+			{Offset: 1, Value: 0x01, Type: models.TypeInt16, IsChained: true},
+			{Offset: 2, Value: 0x00, Type: models.TypeInt16, IsChained: true},
+			{Offset: 3, Type: models.TypeFloat32, IsChained: true},
+			{Offset: 4, Type: models.TypeFloat32, IsChained: true},
+			{Offset: 5, Type: models.TypeFloat32, IsChained: true},
+			{Offset: 6, Value: 0x3f, Type: models.TypeFloat32, IsChained: true},
+			{Offset: 7, Value: 'a', Type: models.TypeString},
+			{Offset: 8, Value: '1', Type: models.TypeJSON},
+		},
+	}
+}
+
+func TestFuzzMutatorKeepsFramesValid(t *testing.T) {
+	format := utils.DefaultFrameFormat
+	m, err := newFuzzMutator(format, fuzzTestPacket(), 7, nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, models.Mutations, m.mutations)
+
+	seen := map[string]bool{}
+	for seq := uint64(1); seq <= 500; seq++ {
+		input := m.next(seq)
+		seen[input.mutation] = true
+		payload, err := format.Unpack(input.wire)
+		switch input.mutation {
+		case models.MutationCRC:
+			assert.ErrorContains(t, err, "CRC 불일치")
+		case models.MutationLength:
+			assert.Error(t, err, input.detail)
+			assert.Equal(t, -1, input.offset)
+		default:
+			// 필드 변형 후에도 길이와 체크섬은 다시 계산됨
+			require.NoError(t, err, input.detail)
+			assert.GreaterOrEqual(t, input.offset, 0)
+			if input.offset > 0 {
+				assert.Equal(t, byte(0x10), payload[0], input.detail)
+			}
+		}
+		if input.mutation == models.MutationFloat {
+			assert.Equal(t, 3, input.offset)
+			assert.Len(t, payload, 9)
+		}
+	}
+	assert.Len(t, seen, len(models.Mutations))
+}
+
+func TestFuzzMutatorKeepsPayloadWithinLengthField(t *testing.T) {
+	format := utils.DefaultFrameFormat
+	format.LengthSize = 2
+	packet := models.TCPPacket{UseCRC: true, Data: models.PacketData{
+		{Offset: 0, Value: 'a', Type: models.TypeString},
+		{Offset: 65499, Value: 0x01, Type: models.TypeUint8},
+	}}
+	m, err := newFuzzMutator(format, packet, 1, []string{models.MutationOverlong})
+	require.NoError(t, err)
+
+	// 2바이트 길이 필드에 담을 수 없는 긴 값 대신 비트 반전으로 변형
+	for seq := uint64(1); seq <= 20; seq++ {
+		input := m.next(seq)
+		assert.Equal(t, models.MutationBitFlip, input.mutation)
+		payload, err := format.Unpack(input.wire)
+		require.NoError(t, err)
+		assert.Len(t, payload, 65500)
+	}
+
+	packet.Data[1].Offset = 65535
+	_, err = newFuzzMutator(format, packet, 1, nil)
+	assert.ErrorContains(t, err, "길이 필드 범위")
+}
+
+func TestFuzzMutatorIsReproducible(t *testing.T) {
+	a, err := newFuzzMutator(utils.DefaultFrameFormat, fuzzTestPacket(), 42, nil)
+	require.NoError(t, err)
+	b, err := newFuzzMutator(utils.DefaultFrameFormat, fuzzTestPacket(), 42, nil)
+	require.NoError(t, err)
+	for seq := uint64(1); seq <= 50; seq++ {
+		assert.Equal(t, a.next(seq), b.next(seq))
+	}
+}
+
+func TestFuzzMutatorBoundaryValues(t *testing.T) {
+	packet := models.TCPPacket{Data: models.PacketData{
+		{Offset: 0, Type: models.TypeInt16, IsChained: true},
+		{Offset: 1, Type: models.TypeInt16, IsChained: true},
+	}}
+	m, err := newFuzzMutator(utils.DefaultFrameFormat, packet, 1, []string{models.MutationBoundary})
+	require.NoError(t, err)
+
+	values := map[string][]byte{}
+	for seq := uint64(1); seq <= 100; seq++ {
+		input := m.next(seq)
+		values[input.detail] = input.wire
+	}
+	assert.Equal(t, []byte{0x00, 0x80}, values["int16 min"])
+	assert.Equal(t, []byte{0xff, 0x7f}, values["int16 max"])
+	assert.Equal(t, []byte{0xff, 0xff}, values["int16 -1"])
+	assert.Equal(t, []byte{0x01, 0x00}, values["int16 one"])
+}
+
+func TestFuzzMutatorRejectsInapplicableMutations(t *testing.T) {
+	packet := models.TCPPacket{Data: models.PacketData{{Offset: 0, Type: models.TypeUint8}}}
+	_, err := newFuzzMutator(utils.DefaultFrameFormat, packet, 1, []string{models.MutationCRC, models.MutationFloat})
+	assert.ErrorContains(t, err, "적용할 수 있는 변형이 없습니다")
+
+	// 체크섬이 없는 raw 패킷이라도 길이 변형은 데이터 길이를 바꿔 적용됨
+	m, err := newFuzzMutator(utils.DefaultFrameFormat, packet, 1, []string{models.MutationLength})
+	require.NoError(t, err)
+	assert.NotEqual(t, 1, len(m.next(1).wire))
+}
+
+func TestFuzzMutatorModbusLength(t *testing.T) {
+	packet := models.TCPPacket{
+		Kind:   models.PacketKindModbus,
+		Modbus: models.ModbusParams{UnitID: 1, Function: 3, Address: 0, Quantity: 2},
+	}
+	m, err := newFuzzMutator(utils.DefaultFrameFormat, packet, 3, []string{models.MutationLength, models.MutationBitFlip})
+	require.NoError(t, err)
+	for seq := uint64(1); seq <= 20; seq++ {
+		input := m.next(seq)
+		if input.mutation == models.MutationLength {
+			assert.Contains(t, input.detail, "(actual 6)")
+			assert.Len(t, input.wire, 12)
+		} else {
+			assert.Equal(t, uint16(seq), uint16(input.wire[0])<<8|uint16(input.wire[1]))
+		}
+	}
+}
@@ -0,0 +1,486 @@
+package services
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"math/rand/v2"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fake-edge-server/models"
+	"github.com/fake-edge-server/utils"
+	"gorm.io/gorm"
+)
+
+// loadProgressInterval is how often a running load test broadcasts progress.
+const loadProgressInterval = time.Second
+
+// loadRetryDelay is how long a virtual client waits before reconnecting.
+const loadRetryDelay = 500 * time.Millisecond
+
+// loadReservoirSize bounds the latencies a run keeps for its percentiles. Once
+// more responses arrive, a uniform random sample of this size is kept.
+const loadReservoirSize = 10000
+
+// loadStats collects the results of one load test run.
+type loadStats struct {
+	mu         sync.Mutex
+	started    time.Time
+	active     int
+	sent       int64
+	received   int64
+	late       int64 // replies that could not be matched to a request
+	errors     map[string]int64
+	latencies  []time.Duration // reservoir sample of the response latencies
+	maxLatency time.Duration
+}
+
+func (s *loadStats) connected(delta int) {
+	s.mu.Lock()
+	s.active += delta
+	s.mu.Unlock()
+}
+
+func (s *loadStats) sentOne() {
+	s.mu.Lock()
+	s.sent++
+	s.mu.Unlock()
+}
+
+func (s *loadStats) fail(kind string) {
+	s.mu.Lock()
+	s.errors[kind]++
+	s.mu.Unlock()
+}
+
+func (s *loadStats) uncorrelated(n int) {
+	s.mu.Lock()
+	s.late += int64(n)
+	s.mu.Unlock()
+}
+
+func (s *loadStats) ok(latency time.Duration) {
+	s.mu.Lock()
+	s.received++
+	s.maxLatency = max(s.maxLatency, latency)
+	if len(s.latencies) < loadReservoirSize {
+		s.latencies = append(s.latencies, latency)
+	} else if i := rand.Int64N(s.received); i < loadReservoirSize {
+		s.latencies[i] = latency
+	}
+	s.mu.Unlock()
+}
+
+// report summarizes the results collected so far.
+func (s *loadStats) report() models.LoadTestReport {
+	s.mu.Lock()
+	elapsed := time.Since(s.started)
+	r := models.LoadTestReport{
+		ElapsedMs:    elapsed.Milliseconds(),
+		Active:       s.active,
+		Sent:         s.sent,
+		Received:     s.received,
+		Uncorrelated: s.late,
+		Errors:       make(map[string]int64, len(s.errors)),
+	}
+	for kind, n := range s.errors {
+		r.Errors[kind] = n
+	}
+	sorted := append([]time.Duration(nil), s.latencies...)
+	maxLatency := s.maxLatency
+	s.mu.Unlock()
+
+	if elapsed > 0 {
+		r.Throughput = float64(r.Received) / elapsed.Seconds()
+	}
+	if len(sorted) > 0 {
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		r.Latency = models.LatencyStats{
+			P50: percentile(sorted, 0.50),
+			P90: percentile(sorted, 0.90),
+			P99: percentile(sorted, 0.99),
+			Max: toMs(maxLatency),
+		}
+	}
+	return r
+}
+
+// percentile returns the nearest-rank percentile of sorted latencies in ms.
+func percentile(sorted []time.Duration, p float64) float64 {
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return toMs(sorted[rank])
+}
+
+func toMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// loadRun is a running load test.
+type loadRun struct {
+	test    models.LoadTest
+	server  models.TCPServer
+	packets []models.TCPPacket
+	format  utils.FrameFormat
+	stats   *loadStats
+	done    chan struct{} // closed when the duration ends or the test is stopped
+	stop    chan struct{}
+	once    sync.Once
+	stopped bool
+}
+
+// LoadTestRunner runs load tests that open many connections of their own to a
+// server, independent of the connection managed for sending packets.
+type LoadTestRunner struct {
+	mu   sync.Mutex
+	runs map[uint]*loadRun
+	db   *gorm.DB
+	hub  *WebSocketHub
+}
+
+// NewLoadTestRunner creates a new LoadTestRunner.
+func NewLoadTestRunner(db *gorm.DB, hub *WebSocketHub) *LoadTestRunner {
+	return &LoadTestRunner{
+		runs: make(map[uint]*loadRun),
+		db:   db,
+		hub:  hub,
+	}
+}
+
+// Start runs the load test in the background. The test must already be saved;
+// its status, timing and final report are written back when it ends.
+func (r *LoadTestRunner) Start(test models.LoadTest, server models.TCPServer, packets []models.TCPPacket) error {
+	format, err := server.Framing.Format()
+	if err != nil {
+		return err
+	}
+	run := &loadRun{
+		test:    test,
+		server:  server,
+		packets: packets,
+		format:  format,
+		stats:   &loadStats{started: time.Now(), errors: make(map[string]int64)},
+		done:    make(chan struct{}),
+		stop:    make(chan struct{}),
+	}
+
+	r.mu.Lock()
+	if _, ok := r.runs[test.ID]; ok {
+		r.mu.Unlock()
+		return fmt.Errorf("부하 시험[%d]이 이미 실행 중입니다", test.ID)
+	}
+	r.runs[test.ID] = run
+	r.mu.Unlock()
+
+	go r.run(run)
+	return nil
+}
+
+// Stop ends a running load test early.
+func (r *LoadTestRunner) Stop(id uint) error {
+	r.mu.Lock()
+	run, ok := r.runs[id]
+	r.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("부하 시험[%d]이 실행 중이 아닙니다", id)
+	}
+	run.once.Do(func() { close(run.stop) })
+	return nil
+}
+
+// Report returns the live report of a running load test.
+func (r *LoadTestRunner) Report(id uint) (models.LoadTestReport, bool) {
+	r.mu.Lock()
+	run, ok := r.runs[id]
+	r.mu.Unlock()
+	if !ok {
+		return models.LoadTestReport{}, false
+	}
+	return run.stats.report(), true
+}
+
+// IsRunning reports whether the load test is running.
+func (r *LoadTestRunner) IsRunning(id uint) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.runs[id]
+	return ok
+}
+
+func (r *LoadTestRunner) run(run *loadRun) {
+	test := run.test
+	timer := time.NewTimer(time.Duration(test.DurationMs) * time.Millisecond)
+	go func() {
+		select {
+		case <-timer.C:
+		case <-run.stop:
+			run.stopped = true
+			timer.Stop()
+		}
+		close(run.done)
+	}()
+
+	var wg sync.WaitGroup
+	rampUp := time.Duration(test.RampUpMs) * time.Millisecond
+	for i := 0; i < test.Connections; i++ {
+		delay := rampUp * time.Duration(i) / time.Duration(test.Connections)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.client(run, delay)
+		}()
+	}
+
+	ticker := time.NewTicker(loadProgressInterval)
+	defer ticker.Stop()
+	for running := true; running; {
+		select {
+		case <-ticker.C:
+			r.hub.Broadcast(map[string]interface{}{
+				"type":         "load_test_progress",
+				"load_test_id": test.ID,
+				"server_id":    test.TCPServerID,
+				"report":       run.stats.report(),
+			})
+		case <-run.done:
+			running = false
+		}
+	}
+	wg.Wait()
+
+	report := run.stats.report()
+	status := models.LoadTestCompleted
+	if run.stopped {
+		status = models.LoadTestStopped
+	}
+	finished := time.Now()
+	err := r.db.Model(&models.LoadTest{}).Where("id = ?", test.ID).Updates(map[string]interface{}{
+		"status":      status,
+		"finished_at": finished,
+		"report":      report,
+	}).Error
+	if err != nil {
+		log.Print(err)
+	}
+
+	r.mu.Lock()
+	delete(r.runs, test.ID)
+	r.mu.Unlock()
+	r.hub.Broadcast(map[string]interface{}{
+		"type":         "load_test_done",
+		"load_test_id": test.ID,
+		"server_id":    test.TCPServerID,
+		"status":       status,
+		"report":       report,
+	})
+}
+
+// client is one virtual client. It connects after its ramp-up delay and sends
+// the packets in turn until the test ends, reconnecting after connection
+// errors. With a rate each client sends at its share of the rate; otherwise it
+// sends the next packet as soon as the previous response arrives.
+func (r *LoadTestRunner) client(run *loadRun, delay time.Duration) {
+	select {
+	case <-time.After(delay):
+	case <-run.done:
+		return
+	}
+
+	var interval time.Duration
+	if run.test.Rate > 0 {
+		interval = time.Duration(float64(time.Second) * float64(run.test.Connections) / run.test.Rate)
+	}
+	var seq uint64
+	for {
+		conn, err := DialServer(run.server, dialTimeout)
+		if err != nil {
+			if run.ended() {
+				return
+			}
+			run.stats.fail(models.LoadErrorConnect)
+			select {
+			case <-time.After(loadRetryDelay):
+				continue
+			case <-run.done:
+				return
+			}
+		}
+		run.stats.connected(1)
+		r.session(run, conn, interval, &seq)
+		run.stats.connected(-1)
+		if run.ended() {
+			return
+		}
+	}
+}
+
+// session exchanges packets over one connection until it fails or the test ends.
+func (r *LoadTestRunner) session(run *loadRun, conn net.Conn, interval time.Duration, seq *uint64) {
+	closed := make(chan struct{})
+	defer close(closed)
+	go func() {
+		select {
+		case <-run.done:
+		case <-closed:
+		}
+		conn.Close()
+	}()
+
+	var reader *FrameReader
+	if run.server.IsDatagram() {
+		reader = NewDatagramReader(conn)
+	} else {
+		reader = NewFrameReader(conn)
+	}
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		if tick != nil {
+			select {
+			case <-tick:
+			case <-run.done:
+				return
+			}
+		} else if run.ended() {
+			return
+		}
+
+		*seq++
+		packet := run.packets[int(*seq-1)%len(run.packets)]
+		wire, split, match, err := encodeLoadRequest(run.format, packet, *seq)
+		if err != nil {
+			run.stats.fail(models.LoadErrorWrite)
+			return
+		}
+		// Raw replies, framed or not, carry no ID, so frames left from a reply
+		// that arrived after an earlier timeout are counted as uncorrelated
+		// rather than taken as the reply to this request.
+		if !hasReplyID(packet) {
+			run.stats.uncorrelated(len(reader.Drain(split)))
+		}
+		start := time.Now()
+		if _, err := conn.Write(wire); err != nil {
+			if !run.ended() {
+				run.stats.fail(models.LoadErrorWrite)
+			}
+			return
+		}
+		run.stats.sentOne()
+
+		latency, skipped, err := awaitLoadReply(reader, split, match, responseWait(run.server), start)
+		run.stats.uncorrelated(skipped)
+		switch {
+		case run.ended():
+			return
+		case errors.Is(err, ErrReadTimeout):
+			run.stats.fail(models.LoadErrorTimeout)
+			continue
+		case errors.Is(err, errLoadFrame):
+			run.stats.fail(models.LoadErrorFrame)
+			continue
+		case err != nil:
+			run.stats.fail(models.LoadErrorClosed)
+			return
+		}
+		run.stats.ok(latency)
+	}
+}
+
+// errLoadFrame marks a response frame that could not be parsed.
+var errLoadFrame = errors.New("응답 프레임 해석 실패")
+
+// awaitLoadReply reads frames until the reply to the request written at start
+// arrives and returns its latency. Frames that match reports are not the
+// reply, such as late replies to requests that timed out, are skipped and
+// counted in skipped.
+func awaitLoadReply(reader *FrameReader, split bufio.SplitFunc, match func([]byte) (bool, error), wait time.Duration, start time.Time) (latency time.Duration, skipped int, err error) {
+	deadline := start.Add(wait)
+	for {
+		frame, err := reader.ReadFrame(split, time.Until(deadline))
+		if err != nil {
+			return 0, skipped, err
+		}
+		latency := time.Since(start)
+		if match == nil {
+			return latency, skipped, nil
+		}
+		ok, err := match(frame)
+		if err != nil {
+			return 0, skipped, fmt.Errorf("%w: %v", errLoadFrame, err)
+		}
+		if ok {
+			return latency, skipped, nil
+		}
+		skipped++
+	}
+}
+
+// hasReplyID reports whether replies to the packet carry the ID of the request
+// they answer: the Edge message ID or the Modbus transaction ID.
+func hasReplyID(packet models.TCPPacket) bool {
+	return packet.Kind == models.PacketKindEdge || packet.Kind == models.PacketKindModbus
+}
+
+// ended reports whether the test duration is over or the test was stopped.
+func (run *loadRun) ended() bool {
+	select {
+	case <-run.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// encodeLoadRequest builds the wire bytes for a packet the same way packets are
+// sent one at a time, using seq as the Edge message ID or Modbus transaction ID.
+// It returns the split function for the response and, except for unframed raw
+// packets, a match function that reports whether a response frame is the reply
+// to this request and fails if the frame is malformed. Edge and Modbus replies
+// are matched by message ID and transaction ID. Framed raw replies have no ID,
+// so any well-formed frame matches; frames buffered before the request is
+// written are drained first, as for unframed raw packets.
+//
+// Unframed raw replies are taken as they arrive rather than after the raw idle
+// gap used for single sends, so the gap is not added to every latency. A reply
+// is expected to arrive in one read.
+func encodeLoadRequest(format utils.FrameFormat, packet models.TCPPacket, seq uint64) ([]byte, bufio.SplitFunc, func([]byte) (bool, error), error) {
+	payload, err := packetPayload(packet)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	wire, split, err := encodePayload(format, packet, payload, seq)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	switch {
+	case hasReplyID(packet):
+		return wire, split, replyMatch(format, packet, seq), nil
+	case packet.UseCRC:
+		match := func(frame []byte) (bool, error) {
+			_, err := format.Unpack(frame)
+			return err == nil, err
+		}
+		return wire, split, match, nil
+	}
+	return wire, splitAvailable, nil, nil
+}
+
+// splitAvailable returns everything buffered as one frame.
+func splitAvailable(data []byte, atEOF bool) (int, []byte, error) {
+	if len(data) == 0 {
+		return 0, nil, nil
+	}
+	return len(data), data, nil
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/fake-edge-server/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadStatsReport(t *testing.T) {
+	stats := &loadStats{started: time.Now().Add(-2 * time.Second), errors: map[string]int64{}}
+	for i := 1; i <= 100; i++ {
+		stats.sentOne()
+		stats.ok(time.Duration(i) * time.Millisecond)
+	}
+	stats.fail("timeout")
+	stats.uncorrelated(2)
+
+	report := stats.report()
+	assert.Equal(t, int64(100), report.Sent)
+	assert.Equal(t, int64(100), report.Received)
+	assert.Equal(t, int64(1), report.Errors["timeout"])
+	assert.Equal(t, int64(2), report.Uncorrelated)
+	assert.Equal(t, 50.0, report.Latency.P50)
+	assert.Equal(t, 90.0, report.Latency.P90)
+	assert.Equal(t, 99.0, report.Latency.P99)
+	assert.Equal(t, 100.0, report.Latency.Max)
+	assert.InDelta(t, 50, report.Throughput, 1)
+}
+
+func TestLoadStatsReservoir(t *testing.T) {
+	stats := &loadStats{started: time.Now(), errors: map[string]int64{}}
+	// 응답 수가 표본 크기를 넘어도 메모리는 고정되고 분위수는 근사값
+	n := 3 * loadReservoirSize
+	for i := 1; i <= n; i++ {
+		stats.ok(time.Duration(i) * time.Microsecond)
+	}
+	assert.Len(t, stats.latencies, loadReservoirSize)
+
+	report := stats.report()
+	assert.Equal(t, int64(n), report.Received)
+	assert.InDelta(t, toMs(time.Duration(n/2)*time.Microsecond), report.Latency.P50, 0.05*toMs(time.Duration(n)*time.Microsecond))
+	assert.Equal(t, toMs(time.Duration(n)*time.Microsecond), report.Latency.Max)
+}
+
+func TestAwaitLoadReplySkipsLateReplies(t *testing.T) {
+	format, err := models.FrameProfile{}.Format()
+	require.NoError(t, err)
+	client, device := net.Pipe()
+	defer client.Close()
+	defer device.Close()
+	reader := NewFrameReader(client)
+
+	// 시간 초과된 요청(1)의 늦은 응답은 건너뛰고 현재 요청(2)의 응답을 기다림
+	packet := models.TCPPacket{Kind: models.PacketKindEdge, Data: models.PacketData{{Offset: 0, Value: 7, Type: models.TypeUint8}}}
+	stale, _, _, err := encodeLoadRequest(format, packet, 1)
+	require.NoError(t, err)
+	reply, split, match, err := encodeLoadRequest(format, packet, 2)
+	require.NoError(t, err)
+	go func() {
+		device.Write(stale)
+		time.Sleep(100 * time.Millisecond)
+		device.Write(reply)
+	}()
+	latency, skipped, err := awaitLoadReply(reader, split, match, time.Second, time.Now())
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, latency, 100*time.Millisecond)
+	assert.Equal(t, 1, skipped)
+
+	// 프레임 없는 raw 응답은 유휴 간격을 기다리지 않음
+	_, split, match, err = encodeLoadRequest(format, models.TCPPacket{Data: packet.Data}, 3)
+	require.NoError(t, err)
+	assert.Nil(t, match)
+	go device.Write([]byte{7})
+	latency, _, err = awaitLoadReply(reader, split, match, time.Second, time.Now())
+	require.NoError(t, err)
+	assert.Less(t, latency, rawIdleGap)
+}
+
+func TestHasReplyID(t *testing.T) {
+	// CRC 프레임을 쓰는 raw 패킷도 응답에 ID가 없으므로 남은 응답은 짝짓지 않음
+	assert.False(t, hasReplyID(models.TCPPacket{UseCRC: true}))
+	assert.False(t, hasReplyID(models.TCPPacket{}))
+	assert.True(t, hasReplyID(models.TCPPacket{Kind: models.PacketKindEdge}))
+	assert.True(t, hasReplyID(models.TCPPacket{Kind: models.PacketKindModbus}))
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"log"
+	"time"
+
+	"github.com/fake-edge-server/models"
+)
+
+// startPushes starts the enabled pushes of a running endpoint. They stop when
+// the endpoint stops or the pushes are reloaded. The stop channel is set even
+// when the pushes cannot be loaded, so a later reload can retry.
+func (m *MockServerManager) startPushes(l *mockListener) {
+	stop := make(chan struct{})
+	l.pushStop = stop
+	var pushes []models.MockPush
+	if err := m.db.Where("mock_endpoint_id = ? AND enabled = ?", l.endpoint.ID, true).Find(&pushes).Error; err != nil {
+		log.Print(err)
+		return
+	}
+	for _, push := range pushes {
+		go m.runPush(l, push, stop)
+	}
+}
+
+// ReloadPushes restarts the pushes of a running endpoint after they changed.
+// It does nothing when the endpoint is not running.
+func (m *MockServerManager) ReloadPushes(id uint) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	l, ok := m.listeners[id]
+	if !ok {
+		return
+	}
+	if l.pushStop != nil {
+		close(l.pushStop)
+		l.pushStop = nil
+	}
+	m.startPushes(l)
+}
+
+// runPush sends the push's packet to every connected client on its interval or
+// cron schedule. The packet is reloaded on each run so edits apply.
+func (m *MockServerManager) runPush(l *mockListener, push models.MockPush, stop chan struct{}) {
+	sched, err := push.Schedule()
+	if err != nil {
+		log.Printf("Mock[%d] push %d: %v", l.endpoint.ID, push.ID, err)
+		return
+	}
+	next := func() time.Duration {
+		if sched == nil {
+			return time.Duration(push.IntervalMs) * time.Millisecond
+		}
+		at := sched.Next(time.Now())
+		if at.IsZero() {
+			return -1
+		}
+		return time.Until(at)
+	}
+
+	for {
+		wait := next()
+		if wait < 0 {
+			return
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-stop:
+			timer.Stop()
+			return
+		case <-l.done:
+			timer.Stop()
+			return
+		}
+
+		var packet models.TCPPacket
+		if err := m.db.First(&packet, push.PacketID).Error; err != nil {
+			log.Printf("Mock[%d] push %d: packet %d: %v", l.endpoint.ID, push.ID, push.PacketID, err)
+			continue
+		}
+		if _, err := m.Push(l.endpoint.ID, "", packet); err != nil {
+			log.Printf("Mock[%d] push %d: %v", l.endpoint.ID, push.ID, err)
+		}
+	}
+}
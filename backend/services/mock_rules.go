@@ -0,0 +1,112 @@
+package services
+
+import (
+	"encoding/hex"
+	"log"
+	"time"
+
+	"github.com/fake-edge-server/models"
+)
+
+// respond answers an inbound frame with the first matching rule of the
+// endpoint for the session's state, falling back to its default rule. Rules are
+// read on every frame so edits through the API apply to running endpoints.
+// A matched rule updates the session's variables and state before replying.
+func (m *MockServerManager) respond(l *mockListener, s *mockSession, payload []byte) {
+	s.mu.Lock()
+	state := s.state
+	s.mu.Unlock()
+	rule, ok := m.matchRule(l.endpoint.ID, state, payload)
+	if !ok {
+		return
+	}
+
+	var packet models.TCPPacket
+	var response []byte
+	switch {
+	case rule.NoResponse:
+	case rule.ResponseHex != "":
+		response, _ = hex.DecodeString(rule.ResponseHex)
+	default:
+		if err := m.db.First(&packet, rule.ResponsePacketID).Error; err != nil {
+			log.Printf("Mock[%d] rule %d: response packet %d: %v", l.endpoint.ID, rule.ID, rule.ResponsePacketID, err)
+			return
+		}
+		response = packetDataToBytes(packet.Data)
+	}
+
+	s.mu.Lock()
+	if err := rule.Capture(payload, s.vars); err != nil {
+		log.Printf("Mock[%d] rule %d: capture: %v", l.endpoint.ID, rule.ID, err)
+	}
+	data := rule.Apply(payload, response, s.vars)
+	changed := rule.NextState != "" && rule.NextState != s.state
+	if changed {
+		s.state = rule.NextState
+	}
+	s.mu.Unlock()
+	if changed {
+		m.hub.Broadcast(map[string]interface{}{
+			"type":             "mock_state",
+			"mock_endpoint_id": l.endpoint.ID,
+			"peer":             s.peer,
+			"from":             state,
+			"state":            rule.NextState,
+			"rule":             rule.Name,
+		})
+	}
+	if rule.NoResponse {
+		return
+	}
+
+	if delay := rule.Delay(); delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-l.done:
+			return
+		}
+	}
+	if err := m.write(l, s, data, packet, models.DirectionOutbound); err != nil {
+		log.Printf("Mock[%d] %s: %v", l.endpoint.ID, s.peer, err)
+	}
+}
+
+// matchRule returns the rule that applies to payload in the given state.
+func (m *MockServerManager) matchRule(endpointID uint, state string, payload []byte) (models.MockRule, bool) {
+	var rules []models.MockRule
+	if err := m.db.Where("mock_endpoint_id = ?", endpointID).Order("priority, id").Find(&rules).Error; err != nil {
+		log.Print(err)
+		return models.MockRule{}, false
+	}
+
+	fields := make(map[uint]models.PacketData)
+	var fallback *models.MockRule
+	for i, rule := range rules {
+		if rule.State != "" && rule.State != state {
+			continue
+		}
+		if rule.IsDefault {
+			if fallback == nil {
+				fallback = &rules[i]
+			}
+			continue
+		}
+		var defs models.PacketData
+		if rule.MatchType == models.MatchField {
+			var ok bool
+			if defs, ok = fields[rule.FieldPacketID]; !ok {
+				var packet models.TCPPacket
+				m.db.First(&packet, rule.FieldPacketID)
+				defs = packet.Data
+				fields[rule.FieldPacketID] = defs
+			}
+		}
+		if rule.Matches(payload, defs) {
+			return rule, true
+		}
+	}
+	if fallback != nil {
+		return *fallback, true
+	}
+	return models.MockRule{}, false
+}
@@ -0,0 +1,397 @@
+package services
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/fake-edge-server/models"
+	"github.com/fake-edge-server/utils"
+	"gorm.io/gorm"
+)
+
+// mockIdleTimeout bounds how long a partially received frame may wait for the
+// rest of its bytes. Idle connections without pending bytes stay open.
+const mockIdleTimeout = time.Minute
+
+// MockConnection describes a client connected to a mock endpoint.
+type MockConnection struct {
+	Peer        string            `json:"peer"`
+	ConnectedAt time.Time         `json:"connected_at"`
+	State       string            `json:"state"`
+	Vars        map[string]string `json:"vars"` // variable values as hex
+}
+
+// mockSession is a client connection accepted by a mock endpoint. It carries
+// the connection's state machine state and variables.
+type mockSession struct {
+	peer        string
+	connectedAt time.Time
+	conn        net.Conn
+	writeMu     sync.Mutex
+	faults      *faultInjector
+
+	mu    sync.Mutex
+	state string
+	vars  map[string][]byte
+}
+
+// snapshot returns the exported view of the session.
+func (s *mockSession) snapshot() MockConnection {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := MockConnection{Peer: s.peer, ConnectedAt: s.connectedAt, State: s.state, Vars: make(map[string]string, len(s.vars))}
+	for name, value := range s.vars {
+		c.Vars[name] = hex.EncodeToString(value)
+	}
+	return c
+}
+
+// mockListener is a running mock endpoint.
+type mockListener struct {
+	endpoint models.MockEndpoint
+	format   utils.FrameFormat
+	ln       net.Listener
+	mu       sync.Mutex
+	sessions map[string]*mockSession
+	wg       sync.WaitGroup
+	done     chan struct{} // closed by Stop before it closes the sessions
+	pushStop chan struct{}
+}
+
+// stopped reports whether the endpoint was stopped. Check it under l.mu before
+// registering a session, so that none is added after Stop closed the others.
+func (l *mockListener) stopped() bool {
+	select {
+	case <-l.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// MockServerManager runs listening mock endpoints that act as edge devices.
+type MockServerManager struct {
+	mu        sync.Mutex
+	listeners map[uint]*mockListener
+	db        *gorm.DB
+	hub       *WebSocketHub
+}
+
+// NewMockServerManager creates a new MockServerManager.
+func NewMockServerManager(db *gorm.DB, hub *WebSocketHub) *MockServerManager {
+	return &MockServerManager{
+		listeners: make(map[uint]*mockListener),
+		db:        db,
+		hub:       hub,
+	}
+}
+
+// Start begins listening on the endpoint's bind address.
+func (m *MockServerManager) Start(endpoint models.MockEndpoint) error {
+	format, err := endpoint.Framing.Format()
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.listeners[endpoint.ID]; ok {
+		return fmt.Errorf("목 엔드포인트[%d]가 이미 실행 중입니다", endpoint.ID)
+	}
+	ln, err := net.Listen("tcp", net.JoinHostPort(endpoint.BindAddr, strconv.Itoa(endpoint.Port)))
+	if err != nil {
+		return err
+	}
+	l := &mockListener{
+		endpoint: endpoint,
+		format:   format,
+		ln:       ln,
+		sessions: make(map[string]*mockSession),
+		done:     make(chan struct{}),
+	}
+	m.listeners[endpoint.ID] = l
+	go m.accept(l)
+	m.startPushes(l)
+	return nil
+}
+
+// Stop closes the listener and all client connections of the endpoint.
+func (m *MockServerManager) Stop(id uint) error {
+	m.mu.Lock()
+	l, ok := m.listeners[id]
+	delete(m.listeners, id)
+	m.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("목 엔드포인트[%d]가 실행 중이 아닙니다", id)
+	}
+
+	close(l.done)
+	l.ln.Close()
+	l.mu.Lock()
+	for _, s := range l.sessions {
+		m.flushHeld(l, s)
+		s.conn.Close()
+	}
+	l.mu.Unlock()
+	l.wg.Wait()
+	return nil
+}
+
+// IsRunning reports whether the endpoint is listening.
+func (m *MockServerManager) IsRunning(id uint) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.listeners[id]
+	return ok
+}
+
+// ListenAddr returns the address the endpoint actually listens on.
+func (m *MockServerManager) ListenAddr(id uint) (string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	l, ok := m.listeners[id]
+	if !ok {
+		return "", false
+	}
+	return l.ln.Addr().String(), true
+}
+
+// Connections returns the connected clients of the endpoint ordered by connection time.
+func (m *MockServerManager) Connections(id uint) []MockConnection {
+	conns := []MockConnection{}
+	for _, s := range m.sessions(id) {
+		conns = append(conns, s.snapshot())
+	}
+	return conns
+}
+
+// sessions returns the sessions of the endpoint ordered by connection time.
+func (m *MockServerManager) sessions(id uint) []*mockSession {
+	l := m.listener(id)
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	sessions := make([]*mockSession, 0, len(l.sessions))
+	for _, s := range l.sessions {
+		sessions = append(sessions, s)
+	}
+	l.mu.Unlock()
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].connectedAt.Before(sessions[j].connectedAt)
+	})
+	return sessions
+}
+
+func (m *MockServerManager) listener(id uint) *mockListener {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.listeners[id]
+}
+
+func (m *MockServerManager) accept(l *mockListener) {
+	for {
+		conn, err := l.ln.Accept()
+		if err != nil {
+			return
+		}
+		s := &mockSession{
+			peer:        conn.RemoteAddr().String(),
+			connectedAt: time.Now(),
+			conn:        conn,
+			state:       l.endpoint.InitialState,
+			vars:        make(map[string][]byte),
+			faults:      newFaultInjector(l.endpoint.Faults, l.format, l.endpoint.UseCRC),
+		}
+		l.mu.Lock()
+		if l.stopped() {
+			l.mu.Unlock()
+			conn.Close()
+			return
+		}
+		l.sessions[s.peer] = s
+		l.wg.Add(1)
+		l.mu.Unlock()
+		go m.serve(l, s)
+	}
+}
+
+// serve reads frames from a client until it disconnects and logs each one.
+// With UseCRC, bytes that do not follow the framing are recorded and answered
+// as they are rather than ending the session.
+func (m *MockServerManager) serve(l *mockListener, s *mockSession) {
+	defer l.wg.Done()
+	id := l.endpoint.ID
+	m.hub.Broadcast(map[string]interface{}{"type": "mock_connection", "mock_endpoint_id": id, "peer": s.peer, "connected": true})
+	defer func() {
+		m.flushHeld(l, s)
+		s.conn.Close()
+		l.mu.Lock()
+		delete(l.sessions, s.peer)
+		l.mu.Unlock()
+		m.hub.Broadcast(map[string]interface{}{"type": "mock_connection", "mock_endpoint_id": id, "peer": s.peer, "connected": false})
+	}()
+
+	var split bufio.SplitFunc
+	if l.endpoint.UseCRC {
+		split = tolerantSplit(l.format)
+	}
+	reader := NewFrameReader(s.conn)
+	for {
+		frame, err := reader.ReadFrame(split, mockIdleTimeout)
+		if errors.Is(err, ErrReadTimeout) && !l.stopped() {
+			continue
+		}
+		if err != nil || len(frame) == 0 {
+			return
+		}
+		payload := frame
+		if l.endpoint.UseCRC {
+			if payload, err = l.format.Unpack(frame); err != nil {
+				log.Printf("Mock[%d] %s: recording %d bytes that do not follow the framing as raw: %v", id, s.peer, len(frame), err)
+				payload = frame
+			}
+		}
+		m.record(l, s, models.DirectionInbound, payload, models.TCPPacket{}, "")
+		m.respond(l, s, payload)
+	}
+}
+
+// record stores a frame exchanged with a client and broadcasts it. packet is
+// the packet definition the frame was built from, if any, and faults lists the
+// faults injected while sending it.
+func (m *MockServerManager) record(l *mockListener, s *mockSession, direction string, data []byte, packet models.TCPPacket, faults string) {
+	history := models.TCPPacketHistory{
+		TCPPacketID:    packet.ID,
+		PacketName:     packet.Name,
+		PacketDesc:     packet.Desc,
+		MockEndpointID: l.endpoint.ID,
+		Direction:      direction,
+		Peer:           s.peer,
+		Faults:         faults,
+	}
+	if direction == models.DirectionInbound {
+		history.Request = hex.EncodeToString(data)
+	} else {
+		history.Response = hex.EncodeToString(data)
+	}
+	if err := m.db.Create(&history).Error; err != nil {
+		log.Print(err)
+	}
+	m.hub.Broadcast(map[string]interface{}{
+		"type":             "mock_frame",
+		"mock_endpoint_id": history.MockEndpointID,
+		"direction":        history.Direction,
+		"peer":             history.Peer,
+		"packet_id":        history.TCPPacketID,
+		"packet_name":      history.PacketName,
+		"request":          history.Request,
+		"response":         history.Response,
+		"faults":           history.Faults,
+	})
+}
+
+// Send writes data to the client with the given peer address, or to every
+// connected client when peer is empty. Data is framed when the endpoint uses
+// CRC framing. It returns the number of clients written to.
+func (m *MockServerManager) Send(id uint, peer string, data []byte) (int, error) {
+	return m.sendTo(id, peer, data, models.TCPPacket{}, models.DirectionOutbound)
+}
+
+// Push sends the packet unsolicited to one client, or to every connected
+// client when peer is empty, and records it with the push direction. Only raw
+// packets can be pushed, since their data is sent as defined.
+func (m *MockServerManager) Push(id uint, peer string, packet models.TCPPacket) (int, error) {
+	if err := packet.CheckPushable(); err != nil {
+		return 0, err
+	}
+	return m.sendTo(id, peer, packetDataToBytes(packet.Data), packet, models.DirectionPush)
+}
+
+func (m *MockServerManager) sendTo(id uint, peer string, data []byte, packet models.TCPPacket, direction string) (int, error) {
+	l := m.listener(id)
+	if l == nil {
+		return 0, fmt.Errorf("목 엔드포인트[%d]가 실행 중이 아닙니다", id)
+	}
+	var targets []*mockSession
+	for _, s := range m.sessions(id) {
+		if peer == "" || s.peer == peer {
+			targets = append(targets, s)
+		}
+	}
+	if peer != "" && len(targets) == 0 {
+		return 0, fmt.Errorf("연결된 클라이언트가 없습니다: %s", peer)
+	}
+
+	sent := 0
+	var lastErr error
+	for _, s := range targets {
+		if err := m.write(l, s, data, packet, direction); err != nil {
+			lastErr = err
+			continue
+		}
+		sent++
+	}
+	return sent, lastErr
+}
+
+// write sends one frame to the client, injecting the endpoint's faults, and
+// records it.
+func (m *MockServerManager) write(l *mockListener, s *mockSession, data []byte, packet models.TCPPacket, direction string) error {
+	out := data
+	if l.endpoint.UseCRC {
+		var err error
+		if out, err = l.format.Build(data); err != nil {
+			return err
+		}
+	}
+	plan := s.faults.plan(out)
+	if plan.delay > 0 {
+		select {
+		case <-time.After(plan.delay):
+		case <-l.done:
+			return net.ErrClosed
+		}
+	}
+	s.writeMu.Lock()
+	var err error
+	for _, frame := range plan.frames {
+		if _, err = s.conn.Write(frame); err != nil {
+			break
+		}
+	}
+	if plan.reset {
+		resetConn(s.conn)
+	}
+	s.writeMu.Unlock()
+	if err != nil {
+		return err
+	}
+	m.record(l, s, direction, plan.written(l.format, l.endpoint.UseCRC), packet, plan.Faults())
+	return nil
+}
+
+// flushHeld writes the frame the session's faults held back for reordering
+// before the connection closes, and records it.
+func (m *MockServerManager) flushHeld(l *mockListener, s *mockSession) {
+	held := s.faults.flush()
+	if held == nil {
+		return
+	}
+	s.writeMu.Lock()
+	_, err := s.conn.Write(held)
+	s.writeMu.Unlock()
+	if err != nil {
+		log.Printf("Mock[%d] %s: held frame lost: %v", l.endpoint.ID, s.peer, err)
+		return
+	}
+	m.record(l, s, models.DirectionOutbound, recordedFrame(l.format, l.endpoint.UseCRC, held), models.TCPPacket{}, models.FaultReorder)
+}
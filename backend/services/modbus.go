@@ -0,0 +1,77 @@
+package services
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/fake-edge-server/models"
+	"github.com/fake-edge-server/utils"
+)
+
+// nextTransactionID returns the next Modbus transaction ID for the server.
+func (p *PacketSender) nextTransactionID(serverID uint) uint16 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.txIDs[serverID]++
+	return p.txIDs[serverID]
+}
+
+// exchangeModbus sends a Modbus TCP request with an auto-assigned transaction ID
+// and decodes the matching response, including exception responses, into the history.
+// A response with the transaction ID but another unit ID or function code is an error.
+func (p *PacketSender) exchangeModbus(conn net.Conn, reader *FrameReader, server models.TCPServer, packet models.TCPPacket, pdu []byte, history *models.TCPPacketHistory) error {
+	if err := utils.CheckModbusPDU(pdu); err != nil {
+		return err
+	}
+	txID := p.nextTransactionID(server.ID)
+	adu := utils.BuildMBAP(txID, packet.Modbus.UnitID, pdu)
+	if _, err := conn.Write(adu); err != nil {
+		return err
+	}
+	history.Request = hex.EncodeToString(adu)
+	history.MsgID = uint64(txID)
+
+	deadline := time.Now().Add(responseWait(server))
+	for {
+		frame, err := reader.ReadFrame(utils.SplitMBAP, time.Until(deadline))
+		if err != nil {
+			return err
+		}
+		if binary.BigEndian.Uint16(frame[0:2]) != txID {
+			p.hub.Broadcast(map[string]interface{}{
+				"type":      "unsolicited",
+				"server_id": server.ID,
+				"msg_id":    binary.BigEndian.Uint16(frame[0:2]),
+				"payload":   hex.EncodeToString(frame),
+			})
+			p.recordDatagram(server, frame)
+			continue
+		}
+
+		if len(frame) <= utils.MBAPHeaderSize {
+			return errors.New("Modbus 응답 길이 부족")
+		}
+		// Exception responses carry the request function code with bit 0x80 set.
+		if unitID, function := frame[6], frame[7]&^0x80; unitID != packet.Modbus.UnitID || function != pdu[0] {
+			return fmt.Errorf("Modbus 응답 불일치: 유닛 ID %d, 함수 코드 0x%02X (요청: 유닛 ID %d, 함수 코드 0x%02X)",
+				unitID, function, packet.Modbus.UnitID, pdu[0])
+		}
+
+		resp, err := utils.ParseModbusResponse(frame, packet.Modbus.Quantity)
+		if err != nil {
+			return err
+		}
+		decoded, err := json.Marshal(resp)
+		if err != nil {
+			return err
+		}
+		history.Response = hex.EncodeToString(frame)
+		history.Decoded = string(decoded)
+		return nil
+	}
+}
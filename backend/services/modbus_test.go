@@ -0,0 +1,19 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/fake-edge-server/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExchangeModbusRejectsEmptyPDU(t *testing.T) {
+	sender := NewPacketSender(setupTestDB(), NewTCPConnectionManager(), NewWebSocketHub())
+	packet := models.TCPPacket{Kind: models.PacketKindModbus}
+
+	// 함수 코드가 없는 PDU는 보내지 않고 오류로 처리
+	var history models.TCPPacketHistory
+	err := sender.exchangeModbus(nil, nil, models.TCPServer{ID: 1}, packet, nil, &history)
+	assert.ErrorContains(t, err, "Modbus PDU가 비어 있습니다")
+	assert.Empty(t, history.Request)
+}
@@ -1,10 +1,12 @@
 package services
 
 import (
+	"bufio"
 	"encoding/hex"
+	"errors"
 	"fmt"
-	"io"
 	"log"
+	"net"
 	"sync"
 	"time"
 
@@ -13,137 +15,252 @@ import (
 	"gorm.io/gorm"
 )
 
-// PacketSender manages background packet sending jobs.
+// responseTimeout bounds how long a send waits for a complete response frame.
+const responseTimeout = 5 * time.Second
+
+// PacketSender sends packets to TCP servers and manages background repeat
+// jobs (see send_job.go).
 type PacketSender struct {
 	mu          sync.Mutex
-	jobs        map[string]chan struct{}
+	jobs        map[string]*sendJob
+	msgIDs      map[uint]uint64
+	txIDs       map[uint]uint16
 	connManager *TCPConnectionManager
 	hub         *WebSocketHub
 	db          *gorm.DB
+
+	// recMu guards recordings; see recorder.go.
+	recMu      sync.Mutex
+	recordings map[uint]*activeRecording
 }
 
 // NewPacketSender creates a new PacketSender.
 func NewPacketSender(db *gorm.DB, cm *TCPConnectionManager, hub *WebSocketHub) *PacketSender {
 	return &PacketSender{
-		jobs:        make(map[string]chan struct{}),
+		jobs:        make(map[string]*sendJob),
+		msgIDs:      make(map[uint]uint64),
+		txIDs:       make(map[uint]uint16),
 		connManager: cm,
 		hub:         hub,
 		db:          db,
 	}
 }
 
-func jobKey(serverID, packetID uint) string {
-	return fmt.Sprintf("%d:%d", serverID, packetID)
+// SendOnce sends the packet a single time and stores the history.
+func (p *PacketSender) SendOnce(server models.TCPServer, packet models.TCPPacket) (*models.TCPPacketHistory, error) {
+	data, err := packetPayload(packet)
+	if err != nil {
+		return nil, err
+	}
+	history, _, err := p.sendOnce(server, packet, data)
+	return history, err
 }
 
-// Start begins sending the packet repeatedly at the given interval.
-func (p *PacketSender) Start(server models.TCPServer, packet models.TCPPacket, interval time.Duration) {
-	key := jobKey(server.ID, packet.ID)
-	p.mu.Lock()
-	if _, exists := p.jobs[key]; exists {
-		p.mu.Unlock()
-		return
+// sendOnce sends data as the packet and stores the history. It also returns
+// the round-trip time of the exchange.
+func (p *PacketSender) sendOnce(server models.TCPServer, packet models.TCPPacket, data []byte) (*models.TCPPacketHistory, time.Duration, error) {
+	conn, reader, err := p.connect(server)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	format, err := server.Framing.Format()
+	if err != nil {
+		return nil, 0, err
 	}
-	stop := make(chan struct{})
-	p.jobs[key] = stop
-	p.mu.Unlock()
-
-	data := packetDataToBytes(packet.Data)
-	go func() {
-		ticker := time.NewTicker(interval)
-		defer ticker.Stop()
-		for {
-			select {
-			case <-ticker.C:
-				if _, err := p.sendOnce(server, packet, data); err != nil {
-					log.Print(err)
-				}
-			case <-stop:
-				return
-			}
+
+	history := models.TCPPacketHistory{
+		TCPServerID: server.ID,
+		TCPPacketID: packet.ID,
+		PacketName:  packet.Name,
+		PacketDesc:  packet.Desc,
+		Kind:        packet.Kind,
+	}
+	if packet.PreSendScript != "" {
+		data, history.ScriptLog, err = runPreSend(packet, data)
+		if err != nil {
+			err = fmt.Errorf("전송 전 스크립트 실패: %v", err)
+			log.Print(err)
+			return nil, 0, err
 		}
-	}()
-}
+	}
+	history.Request = hex.EncodeToString(data)
 
-// Stop terminates the background sending job.
-func (p *PacketSender) Stop(serverID, packetID uint) {
-	key := jobKey(serverID, packetID)
-	p.mu.Lock()
-	if ch, ok := p.jobs[key]; ok {
-		close(ch)
-		delete(p.jobs, key)
+	started := time.Now()
+	switch packet.Kind {
+	case models.PacketKindEdge:
+		err = p.exchangeEdge(conn, reader, format, server, packet, data, &history)
+	case models.PacketKindModbus:
+		err = p.exchangeModbus(conn, reader, server, packet, data, &history)
+	default:
+		err = p.exchangeRaw(conn, reader, format, server, packet, data, &history)
+	}
+	// Measure before the post-receive script so its run time is not counted.
+	latency := time.Since(started)
+	if err != nil {
+		log.Print(err)
+		return nil, 0, err
+	}
+	if packet.PostReceiveScript != "" {
+		postReceive(packet, data, &history)
 	}
-	p.mu.Unlock()
+
+	if err := p.record(&history); err != nil {
+		return nil, 0, err
+	}
+	p.capture(&history, started, latency)
+	log.Printf("Success to send Server[%d] packet %d", packet.TCPServerID, packet.ID)
+	return &history, latency, nil
 }
 
-// SendOnce sends the packet a single time and stores the history.
-func (p *PacketSender) SendOnce(server models.TCPServer, packet models.TCPPacket) (*models.TCPPacketHistory, error) {
-	data := packetDataToBytes(packet.Data)
-	return p.sendOnce(server, packet, data)
+// postReceive runs the packet's post-receive script on the exchange and stores
+// its verdict in the history. Modbus responses are passed without the MBAP
+// header, like the PDU the request was built from. A script failure is
+// recorded as an error verdict rather than failing the send.
+func postReceive(packet models.TCPPacket, request []byte, history *models.TCPPacketHistory) {
+	response, _ := hex.DecodeString(history.Response)
+	if packet.Kind == models.PacketKindModbus && len(response) >= utils.MBAPHeaderSize {
+		response = response[utils.MBAPHeaderSize:]
+	}
+	result, out, err := runPostReceive(packet, request, response)
+	history.ScriptLog += out
+	if err != nil {
+		history.Verdict = models.VerdictError
+		history.ScriptLog += err.Error() + "\n"
+		return
+	}
+	history.Verdict = result.verdict
+	if result.message != "" {
+		history.ScriptLog += result.message + "\n"
+	}
+	if result.decoded != "" {
+		history.Decoded = result.decoded
+	}
 }
 
-func (p *PacketSender) sendOnce(server models.TCPServer, packet models.TCPPacket, data []byte) (*models.TCPPacketHistory, error) {
-	conn := p.connManager.GetConn(server.ID)
+// connect returns the managed connection for the server, dialing it when needed.
+func (p *PacketSender) connect(server models.TCPServer) (net.Conn, *FrameReader, error) {
+	conn, reader := p.connManager.session(server.ID)
+	if conn != nil {
+		return conn, reader, nil
+	}
+	// Do not dial while the manager is already reconnecting with backoff.
+	if p.connManager.Reconnecting(server.ID) != nil {
+		return nil, nil, fmt.Errorf("서버[%d] 재연결 중입니다", server.ID)
+	}
+	if err := p.connManager.ConnectServer(server); err != nil {
+		return nil, nil, err
+	}
+	conn, reader = p.connManager.session(server.ID)
 	if conn == nil {
-		err := p.connManager.Connect(server.ID, server.Host, server.Port)
-		if err != nil {
-			return nil, err
-		}
-		conn = p.connManager.GetConn(server.ID)
+		return nil, nil, fmt.Errorf("서버[%d] 연결이 종료되었습니다", server.ID)
 	}
+	return conn, reader, nil
+}
 
+// exchangeRaw writes the packet bytes, framed when UseCRC is set, and reads one response.
+// Frames that arrived before writing, such as a reply that came after an
+// earlier timeout or a frame pushed by the device, are broadcast as
+// unsolicited first, since raw replies carry no ID to tell them apart.
+// For UDP targets a missing response within the wait window is not an error;
+// the sent datagram is recorded with an empty response.
+func (p *PacketSender) exchangeRaw(conn net.Conn, reader *FrameReader, format utils.FrameFormat, server models.TCPServer, packet models.TCPPacket, data []byte, history *models.TCPPacketHistory) error {
 	sendData := data
+	var split bufio.SplitFunc
 	if packet.UseCRC {
-		sendData = utils.BuildPacket(data)
+		var err error
+		if sendData, err = format.Build(data); err != nil {
+			return err
+		}
+		split = format.Split
+	}
+	for _, frame := range reader.Drain(split) {
+		p.unsolicited(server, frame)
 	}
 	if _, err := conn.Write(sendData); err != nil {
-		return nil, err
+		return err
 	}
 
-	timeout := 5 * time.Second
-	conn.SetReadDeadline(time.Now().Add(timeout))
-	buf := make([]byte, 4096)
-	n, err := conn.Read(buf)
-	conn.SetReadDeadline(time.Time{})
-	if err != nil && err != io.EOF {
-		log.Print(err)
-		return nil, err
+	response, err := reader.ReadFrame(split, responseWait(server))
+	if err != nil {
+		if server.IsDatagram() && errors.Is(err, ErrReadTimeout) {
+			return nil
+		}
+		return err
 	}
-
-	responseData := buf[:n]
-	var response []byte
 	if packet.UseCRC {
-		response, err = utils.UnpackPacket(responseData)
+		response, err = format.Unpack(response)
 		if err != nil {
-			return nil, err
+			return err
+		}
+	}
+	history.Response = hex.EncodeToString(response)
+	// Datagrams that arrived with the response are not part of it.
+	if server.IsDatagram() {
+		for _, datagram := range reader.Drain(nil) {
+			p.unsolicited(server, datagram)
 		}
-	} else {
-		response = responseData
 	}
-	reqHex := hex.EncodeToString(data)
-	respHex := hex.EncodeToString(response)
+	return nil
+}
+
+// unsolicited broadcasts a frame that is not the response to a request and
+// stores it when it is a datagram.
+func (p *PacketSender) unsolicited(server models.TCPServer, frame []byte) {
+	p.hub.Broadcast(map[string]interface{}{
+		"type":      "unsolicited",
+		"server_id": server.ID,
+		"payload":   hex.EncodeToString(frame),
+	})
+	p.recordDatagram(server, frame)
+}
+
+// recordDatagram stores a datagram from a UDP server that is not the response
+// to a send, so the history holds every datagram received. Frames from stream
+// servers are only broadcast.
+func (p *PacketSender) recordDatagram(server models.TCPServer, datagram []byte) {
+	if !server.IsDatagram() {
+		return
+	}
 	history := models.TCPPacketHistory{
 		TCPServerID: server.ID,
-		TCPPacketID: packet.ID,
-		PacketName:  packet.Name,
-		PacketDesc:  packet.Desc,
-		Request:     reqHex,
-		Response:    respHex,
+		Direction:   models.DirectionUnsolicited,
+		Response:    hex.EncodeToString(datagram),
 	}
 	if err := p.db.Create(&history).Error; err != nil {
-		return nil, err
+		log.Print(err)
+	}
+}
+
+// responseWait returns how long to wait for a response from the server.
+func responseWait(server models.TCPServer) time.Duration {
+	if server.IsDatagram() {
+		return server.ResponseWindow()
+	}
+	return responseTimeout
+}
+
+// record stores the history and broadcasts it to WebSocket clients.
+func (p *PacketSender) record(history *models.TCPPacketHistory) error {
+	if err := p.db.Create(history).Error; err != nil {
+		return err
 	}
 	p.hub.Broadcast(map[string]interface{}{
-		"type":        "response",
-		"server_id":   server.ID,
-		"packet_id":   packet.ID,
-		"packet_name": packet.Name,
-		"packet_desc": packet.Desc,
-		"request":     reqHex,
-		"response":    respHex,
+		"type":         "response",
+		"server_id":    history.TCPServerID,
+		"packet_id":    history.TCPPacketID,
+		"packet_name":  history.PacketName,
+		"packet_desc":  history.PacketDesc,
+		"kind":         history.Kind,
+		"node_type":    history.NodeType,
+		"command_type": history.CommandType,
+		"msg_id":       history.MsgID,
+		"request":      history.Request,
+		"response":     history.Response,
+		"decoded":      history.Decoded,
+		"verdict":      history.Verdict,
 	})
-	log.Printf("Success to send Server[%d] packet %d", packet.TCPServerID, packet.ID)
-	return &history, nil
+	return nil
 }
 
 // packetDataToBytes converts packet data to a byte slice.
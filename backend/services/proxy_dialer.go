@@ -0,0 +1,231 @@
+package services
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/fake-edge-server/models"
+)
+
+// Dial stages reported by DialError.
+const (
+	DialStageProxy  = "proxy"
+	DialStageTarget = "target"
+)
+
+// DialError tells whether establishing a connection failed at the proxy or at
+// the target server behind it.
+type DialError struct {
+	Stage string
+	Err   error
+}
+
+func (e *DialError) Error() string {
+	if e.Stage == DialStageProxy {
+		return fmt.Sprintf("프록시 연결 실패: %v", e.Err)
+	}
+	return fmt.Sprintf("대상 서버 연결 실패: %v", e.Err)
+}
+
+func (e *DialError) Unwrap() error {
+	return e.Err
+}
+
+func proxyError(err error) error {
+	return &DialError{Stage: DialStageProxy, Err: err}
+}
+
+func targetError(err error) error {
+	return &DialError{Stage: DialStageTarget, Err: err}
+}
+
+// socks5Replies maps SOCKS5 reply codes to messages. Codes 3-6 mean the proxy
+// itself worked but could not reach the target.
+var socks5Replies = map[byte]string{
+	0x01: "general SOCKS server failure",
+	0x02: "connection not allowed by ruleset",
+	0x03: "network unreachable",
+	0x04: "host unreachable",
+	0x05: "connection refused",
+	0x06: "TTL expired",
+	0x07: "command not supported",
+	0x08: "address type not supported",
+}
+
+// dialProxy connects to addr through the proxy and returns the tunnelled connection.
+func dialProxy(proxy models.ProxySettings, addr string, timeout time.Duration) (net.Conn, error) {
+	conn, err := net.DialTimeout("tcp", proxy.Address, timeout)
+	if err != nil {
+		return nil, proxyError(err)
+	}
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	switch proxy.Type {
+	case models.ProxySOCKS5:
+		err = socks5Connect(conn, proxy, addr)
+	case models.ProxyHTTP:
+		conn, err = httpConnect(conn, proxy, addr)
+	default:
+		err = proxyError(fmt.Errorf("지원되지 않는 프록시 종류: %s", proxy.Type))
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{})
+	return conn, nil
+}
+
+// socks5Connect performs the SOCKS5 greeting, optional username/password
+// authentication (RFC 1929) and CONNECT request on conn.
+func socks5Connect(conn net.Conn, proxy models.ProxySettings, addr string) error {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return targetError(err)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return targetError(err)
+	}
+
+	method := byte(0x00)
+	if proxy.Username != "" {
+		method = 0x02
+	}
+	if _, err := conn.Write([]byte{0x05, 0x01, method}); err != nil {
+		return proxyError(err)
+	}
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return proxyError(err)
+	}
+	if reply[0] != 0x05 {
+		return proxyError(fmt.Errorf("SOCKS5 응답 버전 오류: %d", reply[0]))
+	}
+	if reply[1] != method {
+		return proxyError(errors.New("SOCKS5 프록시가 인증 방식을 허용하지 않습니다"))
+	}
+
+	if method == 0x02 {
+		auth := []byte{0x01, byte(len(proxy.Username))}
+		auth = append(auth, proxy.Username...)
+		auth = append(auth, byte(len(proxy.Password)))
+		auth = append(auth, proxy.Password...)
+		if _, err := conn.Write(auth); err != nil {
+			return proxyError(err)
+		}
+		if _, err := io.ReadFull(conn, reply); err != nil {
+			return proxyError(err)
+		}
+		if reply[1] != 0x00 {
+			return proxyError(errors.New("SOCKS5 인증 실패"))
+		}
+	}
+
+	req := []byte{0x05, 0x01, 0x00}
+	if ip := net.ParseIP(host); ip == nil {
+		if len(host) > 255 {
+			return targetError(errors.New("호스트 이름이 너무 깁니다"))
+		}
+		req = append(req, 0x03, byte(len(host)))
+		req = append(req, host...)
+	} else if ip4 := ip.To4(); ip4 != nil {
+		req = append(req, 0x01)
+		req = append(req, ip4...)
+	} else {
+		req = append(req, 0x04)
+		req = append(req, ip.To16()...)
+	}
+	req = binary.BigEndian.AppendUint16(req, uint16(port))
+	if _, err := conn.Write(req); err != nil {
+		return proxyError(err)
+	}
+
+	head := make([]byte, 4)
+	if _, err := io.ReadFull(conn, head); err != nil {
+		return proxyError(err)
+	}
+	if rep := head[1]; rep != 0x00 {
+		msg, ok := socks5Replies[rep]
+		if !ok {
+			msg = fmt.Sprintf("알 수 없는 응답 코드 %d", rep)
+		}
+		if rep >= 0x03 && rep <= 0x06 {
+			return targetError(errors.New(msg))
+		}
+		return proxyError(errors.New(msg))
+	}
+
+	// 바인드 주소는 사용하지 않지만 스트림에서 제거해야 한다
+	var skip int
+	switch head[3] {
+	case 0x01:
+		skip = net.IPv4len + 2
+	case 0x04:
+		skip = net.IPv6len + 2
+	case 0x03:
+		size := make([]byte, 1)
+		if _, err := io.ReadFull(conn, size); err != nil {
+			return proxyError(err)
+		}
+		skip = int(size[0]) + 2
+	default:
+		return proxyError(fmt.Errorf("SOCKS5 주소 타입 오류: %d", head[3]))
+	}
+	if _, err := io.ReadFull(conn, make([]byte, skip)); err != nil {
+		return proxyError(err)
+	}
+	return nil
+}
+
+// httpConnect opens an HTTP CONNECT tunnel to addr. Bytes the proxy sent after
+// its response header are kept in front of the returned connection.
+func httpConnect(conn net.Conn, proxy models.ProxySettings, addr string) (net.Conn, error) {
+	req := "CONNECT " + addr + " HTTP/1.1\r\nHost: " + addr + "\r\n"
+	if proxy.Username != "" {
+		cred := base64.StdEncoding.EncodeToString([]byte(proxy.Username + ":" + proxy.Password))
+		req += "Proxy-Authorization: Basic " + cred + "\r\n"
+	}
+	req += "\r\n"
+	if _, err := io.WriteString(conn, req); err != nil {
+		return conn, proxyError(err)
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, &http.Request{Method: http.MethodConnect})
+	if err != nil {
+		return conn, proxyError(err)
+	}
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+	case resp.StatusCode == http.StatusBadGateway, resp.StatusCode == http.StatusServiceUnavailable,
+		resp.StatusCode == http.StatusGatewayTimeout:
+		return conn, targetError(fmt.Errorf("HTTP 프록시 응답: %s", resp.Status))
+	default:
+		return conn, proxyError(fmt.Errorf("HTTP 프록시 응답: %s", resp.Status))
+	}
+
+	if br.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, r: br}, nil
+	}
+	return conn, nil
+}
+
+// bufferedConn reads from r before falling back to the underlying connection.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
@@ -0,0 +1,221 @@
+package services
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/fake-edge-server/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveTCP accepts connections on a loopback listener and runs handle for each.
+func serveTCP(t *testing.T, handle func(net.Conn)) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				handle(conn)
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func echo(conn net.Conn) {
+	io.Copy(conn, conn)
+}
+
+// tunnel dials target and pipes data between client and target.
+func tunnel(client net.Conn, target net.Conn) {
+	defer target.Close()
+	go io.Copy(target, client)
+	io.Copy(client, target)
+}
+
+// startSOCKS5 starts a minimal SOCKS5 proxy that requires username/password
+// authentication when user is set.
+func startSOCKS5(t *testing.T, user, pass string) string {
+	return serveTCP(t, func(conn net.Conn) {
+		head := make([]byte, 2)
+		if _, err := io.ReadFull(conn, head); err != nil {
+			return
+		}
+		methods := make([]byte, head[1])
+		io.ReadFull(conn, methods)
+		if user == "" {
+			conn.Write([]byte{0x05, 0x00})
+		} else {
+			conn.Write([]byte{0x05, 0x02})
+			ver := make([]byte, 2)
+			io.ReadFull(conn, ver)
+			u := make([]byte, ver[1])
+			io.ReadFull(conn, u)
+			plen := make([]byte, 1)
+			io.ReadFull(conn, plen)
+			p := make([]byte, plen[0])
+			io.ReadFull(conn, p)
+			if string(u) != user || string(p) != pass {
+				conn.Write([]byte{0x01, 0x01})
+				return
+			}
+			conn.Write([]byte{0x01, 0x00})
+		}
+
+		req := make([]byte, 4)
+		io.ReadFull(conn, req)
+		var host string
+		switch req[3] {
+		case 0x01:
+			ip := make([]byte, net.IPv4len)
+			io.ReadFull(conn, ip)
+			host = net.IP(ip).String()
+		case 0x04:
+			ip := make([]byte, net.IPv6len)
+			io.ReadFull(conn, ip)
+			host = net.IP(ip).String()
+		case 0x03:
+			size := make([]byte, 1)
+			io.ReadFull(conn, size)
+			name := make([]byte, size[0])
+			io.ReadFull(conn, name)
+			host = string(name)
+		}
+		port := make([]byte, 2)
+		io.ReadFull(conn, port)
+
+		target, err := net.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port)))))
+		if err != nil {
+			conn.Write([]byte{0x05, 0x05, 0x00, 0x01, 0, 0, 0, 0, 0, 0})
+			return
+		}
+		conn.Write([]byte{0x05, 0x00, 0x00, 0x01, 127, 0, 0, 1, 0, 0})
+		tunnel(conn, target)
+	})
+}
+
+// startHTTPProxy starts a minimal HTTP CONNECT proxy.
+func startHTTPProxy(t *testing.T, user, pass string) string {
+	return serveTCP(t, func(conn net.Conn) {
+		br := bufio.NewReader(conn)
+		req, err := http.ReadRequest(br)
+		if err != nil || req.Method != http.MethodConnect {
+			return
+		}
+		cred := "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+pass))
+		if user != "" && req.Header.Get("Proxy-Authorization") != cred {
+			io.WriteString(conn, "HTTP/1.1 407 Proxy Authentication Required\r\n\r\n")
+			return
+		}
+		target, err := net.Dial("tcp", req.Host)
+		if err != nil {
+			io.WriteString(conn, "HTTP/1.1 502 Bad Gateway\r\n\r\n")
+			return
+		}
+		io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+		tunnel(conn, target)
+	})
+}
+
+// closedAddr returns a loopback address with nothing listening on it.
+func closedAddr(t *testing.T) (string, int) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().(*net.TCPAddr)
+	ln.Close()
+	return addr.IP.String(), addr.Port
+}
+
+func dialStage(err error) string {
+	var dialErr *DialError
+	if errors.As(err, &dialErr) {
+		return dialErr.Stage
+	}
+	return ""
+}
+
+func TestConnectServerThroughSOCKS5(t *testing.T) {
+	target := serveTCP(t, echo)
+	host, portStr, _ := net.SplitHostPort(target)
+	port, _ := strconv.Atoi(portStr)
+	proxyAddr := startSOCKS5(t, "lab", "secret")
+
+	mgr := NewTCPConnectionManager()
+	server := models.TCPServer{ID: 1, Host: host, Port: port, Proxy: models.ProxySettings{
+		Type: models.ProxySOCKS5, Address: proxyAddr, Username: "lab", Password: "secret",
+	}}
+	require.NoError(t, mgr.ConnectServer(server))
+	defer mgr.Disconnect(1)
+
+	info, _ := mgr.GetInfo(1)
+	assert.Equal(t, target, info.RemoteAddr)
+	assert.Equal(t, "socks5://"+proxyAddr, info.Proxy)
+
+	conn, reader := mgr.session(1)
+	_, err := conn.Write([]byte("ping"))
+	require.NoError(t, err)
+	got, err := reader.ReadFrame(nil, time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, "ping", string(got))
+
+	// 잘못된 인증 정보는 프록시 단계 실패
+	server.Proxy.Password = "wrong"
+	err = mgr.ConnectServer(server)
+	assert.Equal(t, DialStageProxy, dialStage(err))
+}
+
+func TestDialServerDistinguishesProxyAndTargetFailures(t *testing.T) {
+	deadHost, deadPort := closedAddr(t)
+	_, proxyPort := closedAddr(t)
+
+	// 대상 서버가 닫혀 있으면 대상 단계 실패
+	for _, proxy := range []models.ProxySettings{
+		{Type: models.ProxySOCKS5, Address: startSOCKS5(t, "", "")},
+		{Type: models.ProxyHTTP, Address: startHTTPProxy(t, "", "")},
+	} {
+		_, err := DialServer(models.TCPServer{Host: deadHost, Port: deadPort, Proxy: proxy}, time.Second)
+		assert.Equal(t, DialStageTarget, dialStage(err), proxy.Type)
+	}
+
+	// 프록시가 닫혀 있으면 프록시 단계 실패
+	_, err := DialServer(models.TCPServer{Host: deadHost, Port: deadPort, Proxy: models.ProxySettings{
+		Type: models.ProxyHTTP, Address: net.JoinHostPort("127.0.0.1", strconv.Itoa(proxyPort)),
+	}}, time.Second)
+	assert.Equal(t, DialStageProxy, dialStage(err))
+	assert.Contains(t, err.Error(), "프록시 연결 실패")
+
+	// 프록시 없이 직접 접속 실패
+	_, err = DialServer(models.TCPServer{Host: deadHost, Port: deadPort}, time.Second)
+	assert.Equal(t, DialStageTarget, dialStage(err))
+}
+
+func TestTCPService_SendRequestThroughHTTPProxy(t *testing.T) {
+	target := serveTCP(t, echo)
+	host, portStr, _ := net.SplitHostPort(target)
+	port, _ := strconv.Atoi(portStr)
+	db := setupTestDB()
+
+	db.Create(&models.TCPServer{Name: "jump", Host: host, Port: port, Proxy: models.ProxySettings{
+		Type: models.ProxyHTTP, Address: startHTTPProxy(t, "lab", "secret"), Username: "lab", Password: "secret",
+	}})
+
+	response, err := NewTCPService(db).SendRequest("jump", "hello", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", response)
+}
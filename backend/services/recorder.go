@@ -0,0 +1,187 @@
+package services
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/fake-edge-server/models"
+	"gorm.io/gorm"
+)
+
+// ErrRecordingActive is returned when a server already has an active recording.
+var ErrRecordingActive = errors.New("이미 기록 중입니다")
+
+// activeRecording is a recording that is capturing exchanges, with the
+// sequence number of its last entry.
+type activeRecording struct {
+	rec models.Recording
+	seq int
+}
+
+// StartRecording begins capturing every request/response pair sent to the server.
+func (p *PacketSender) StartRecording(serverID uint, name string) (*models.Recording, error) {
+	p.recMu.Lock()
+	defer p.recMu.Unlock()
+	recordings := p.activeRecordings()
+	if _, ok := recordings[serverID]; ok {
+		return nil, ErrRecordingActive
+	}
+	rec := models.Recording{TCPServerID: serverID, Name: name, StartedAt: time.Now()}
+	if err := p.db.Create(&rec).Error; err != nil {
+		return nil, err
+	}
+	recordings[serverID] = &activeRecording{rec: rec}
+	return &rec, nil
+}
+
+// StopRecording ends the server's active recording and returns it.
+func (p *PacketSender) StopRecording(serverID uint) (*models.Recording, error) {
+	p.recMu.Lock()
+	defer p.recMu.Unlock()
+	recordings := p.activeRecordings()
+	active, ok := recordings[serverID]
+	if !ok {
+		return nil, fmt.Errorf("서버[%d]에 진행 중인 기록이 없습니다", serverID)
+	}
+	rec := active.rec
+	now := time.Now()
+	rec.StoppedAt = &now
+	if err := p.db.Save(&rec).Error; err != nil {
+		return nil, err
+	}
+	delete(recordings, serverID)
+	return &rec, nil
+}
+
+// activeRecordings returns the recordings that have not been stopped, keyed by
+// server. Recordings live in the database so they survive restarts; they are
+// loaded on first use and then tracked in memory. The caller must hold recMu.
+func (p *PacketSender) activeRecordings() map[uint]*activeRecording {
+	if p.recordings != nil {
+		return p.recordings
+	}
+	p.recordings = make(map[uint]*activeRecording)
+	var recs []models.Recording
+	if err := p.db.Where("stopped_at IS NULL").Find(&recs).Error; err != nil {
+		log.Printf("Failed to load active recordings: %v", err)
+		return p.recordings
+	}
+	for _, rec := range recs {
+		var seq int
+		p.db.Model(&models.RecordingEntry{}).Where("recording_id = ?", rec.ID).
+			Select("COALESCE(MAX(seq), 0)").Scan(&seq)
+		p.recordings[rec.TCPServerID] = &activeRecording{rec: rec, seq: seq}
+	}
+	return p.recordings
+}
+
+// capture appends a completed exchange to the server's active recording. Only
+// the sequence number is taken under the lock; the entry is inserted after it.
+func (p *PacketSender) capture(history *models.TCPPacketHistory, started time.Time, latency time.Duration) {
+	p.recMu.Lock()
+	active, ok := p.activeRecordings()[history.TCPServerID]
+	if !ok {
+		p.recMu.Unlock()
+		return
+	}
+	active.seq++
+	entry := models.RecordingEntry{
+		RecordingID: active.rec.ID,
+		Seq:         active.seq,
+		TCPPacketID: history.TCPPacketID,
+		Kind:        history.Kind,
+		Request:     history.Request,
+		Response:    history.Response,
+		OffsetMs:    started.Sub(active.rec.StartedAt).Milliseconds(),
+		LatencyMs:   latency.Milliseconds(),
+	}
+	p.recMu.Unlock()
+	if err := p.db.Create(&entry).Error; err != nil {
+		log.Print(err)
+	}
+}
+
+// CreateReplayMock generates a mock endpoint that answers the recorded requests
+// with the recorded responses. Each distinct request becomes an exact-match rule
+// replying with the first response recorded for it after the recorded latency
+// divided by the speed factor. Other requests get the configured fallback.
+// Only raw exchanges can be replayed: Edge and Modbus requests carry an ID that
+// changes on every send and is not part of the recorded request.
+func CreateReplayMock(db *gorm.DB, recordingID uint, req models.ReplayRequest) (*models.MockEndpoint, error) {
+	var rec models.Recording
+	if err := db.Preload("Entries", func(tx *gorm.DB) *gorm.DB { return tx.Order("seq") }).First(&rec, recordingID).Error; err != nil {
+		return nil, err
+	}
+	for _, entry := range rec.Entries {
+		if entry.Kind != "" && entry.Kind != models.PacketKindRaw {
+			return nil, fmt.Errorf("%s 패킷이 포함된 기록은 재생할 수 없습니다 (#%d)", entry.Kind, entry.Seq)
+		}
+	}
+	var server models.TCPServer
+	db.First(&server, rec.TCPServerID)
+
+	speed := req.Speed
+	if speed == 0 {
+		speed = 1
+	}
+	if speed < 0 {
+		return nil, errors.New("speed는 0보다 커야 합니다")
+	}
+	if req.FallbackHex != "" && req.FallbackPacketID != 0 {
+		return nil, errors.New("fallback_hex와 fallback_packet_id는 함께 사용할 수 없습니다")
+	}
+	if _, err := hex.DecodeString(req.FallbackHex); err != nil {
+		return nil, errors.New("fallback_hex는 HEX 문자열이어야 합니다")
+	}
+
+	endpoint := models.MockEndpoint{
+		Name:     req.Name,
+		BindAddr: req.BindAddr,
+		Port:     req.Port,
+		UseCRC:   req.UseCRC,
+		Framing:  server.Framing,
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&endpoint).Error; err != nil {
+			return err
+		}
+		seen := make(map[string]bool)
+		for _, entry := range rec.Entries {
+			if seen[entry.Request] {
+				continue
+			}
+			seen[entry.Request] = true
+			rule := models.MockRule{
+				MockEndpointID: endpoint.ID,
+				Name:           fmt.Sprintf("%s #%d", rec.Name, entry.Seq),
+				Priority:       entry.Seq,
+				MatchType:      models.MatchExact,
+				Pattern:        entry.Request,
+				ResponseHex:    entry.Response,
+				NoResponse:     entry.Response == "",
+				DelayMs:        int(float64(entry.LatencyMs) / speed),
+			}
+			if err := tx.Create(&rule).Error; err != nil {
+				return err
+			}
+		}
+		if req.FallbackHex == "" && req.FallbackPacketID == 0 {
+			return nil
+		}
+		fallback := models.MockRule{
+			MockEndpointID:   endpoint.ID,
+			Name:             "fallback",
+			IsDefault:        true,
+			ResponseHex:      req.FallbackHex,
+			ResponsePacketID: req.FallbackPacketID,
+		}
+		return tx.Create(&fallback).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &endpoint, nil
+}
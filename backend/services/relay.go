@@ -0,0 +1,426 @@
+package services
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fake-edge-server/models"
+	"github.com/fake-edge-server/utils"
+	"gorm.io/gorm"
+)
+
+// RelayConnection describes a client connected through a relay.
+type RelayConnection struct {
+	Peer        string    `json:"peer"`
+	Upstream    string    `json:"upstream"` // address of the target server
+	ConnectedAt time.Time `json:"connected_at"`
+}
+
+// relaySession is a client connection paired with its upstream connection.
+type relaySession struct {
+	RelayConnection
+	client   net.Conn
+	upstream net.Conn
+	packets  []models.TCPPacket // the server's packet definitions used to decode recorded frames
+}
+
+// close closes both sides of the session.
+func (s *relaySession) close() {
+	s.client.Close()
+	s.upstream.Close()
+}
+
+// relayListener is a running relay.
+type relayListener struct {
+	relay    models.Relay
+	server   models.TCPServer
+	format   utils.FrameFormat
+	ln       net.Listener
+	mu       sync.Mutex
+	sessions map[string]*relaySession
+	wg       sync.WaitGroup
+	done     chan struct{} // closed by Stop before it closes the sessions
+}
+
+// stopped reports whether the relay was stopped. Check it under l.mu before
+// registering a session, so that none is added after Stop closed the others.
+func (l *relayListener) stopped() bool {
+	select {
+	case <-l.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// RelayManager runs transparent TCP relays that sit between a client and a
+// registered server and record the traffic in both directions.
+type RelayManager struct {
+	mu        sync.Mutex
+	listeners map[uint]*relayListener
+	db        *gorm.DB
+	hub       *WebSocketHub
+}
+
+// NewRelayManager creates a new RelayManager.
+func NewRelayManager(db *gorm.DB, hub *WebSocketHub) *RelayManager {
+	return &RelayManager{
+		listeners: make(map[uint]*relayListener),
+		db:        db,
+		hub:       hub,
+	}
+}
+
+// Start begins listening on the relay's bind address. Each accepted client is
+// connected to the server with the same dialer used for sending packets, so
+// TLS and proxy settings of the server apply.
+func (m *RelayManager) Start(relay models.Relay, server models.TCPServer) error {
+	if server.IsDatagram() {
+		return errors.New("UDP 서버는 중계할 수 없습니다")
+	}
+	format, err := server.Framing.Format()
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.listeners[relay.ID]; ok {
+		return fmt.Errorf("중계[%d]가 이미 실행 중입니다", relay.ID)
+	}
+	ln, err := net.Listen("tcp", net.JoinHostPort(relay.BindAddr, strconv.Itoa(relay.Port)))
+	if err != nil {
+		return err
+	}
+	l := &relayListener{
+		relay:    relay,
+		server:   server,
+		format:   format,
+		ln:       ln,
+		sessions: make(map[string]*relaySession),
+		done:     make(chan struct{}),
+	}
+	m.listeners[relay.ID] = l
+	go m.accept(l)
+	return nil
+}
+
+// Stop closes the listener and every relayed connection.
+func (m *RelayManager) Stop(id uint) error {
+	m.mu.Lock()
+	l, ok := m.listeners[id]
+	delete(m.listeners, id)
+	m.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("중계[%d]가 실행 중이 아닙니다", id)
+	}
+
+	close(l.done)
+	l.ln.Close()
+	l.mu.Lock()
+	for _, s := range l.sessions {
+		s.close()
+	}
+	l.mu.Unlock()
+	l.wg.Wait()
+	return nil
+}
+
+// IsRunning reports whether the relay is listening.
+func (m *RelayManager) IsRunning(id uint) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.listeners[id]
+	return ok
+}
+
+// ListenAddr returns the actual listening address of a running relay.
+func (m *RelayManager) ListenAddr(id uint) (string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	l, ok := m.listeners[id]
+	if !ok {
+		return "", false
+	}
+	return l.ln.Addr().String(), true
+}
+
+// Connections returns the clients currently relayed.
+func (m *RelayManager) Connections(id uint) []RelayConnection {
+	m.mu.Lock()
+	l := m.listeners[id]
+	m.mu.Unlock()
+	conns := []RelayConnection{}
+	if l == nil {
+		return conns
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, s := range l.sessions {
+		conns = append(conns, s.RelayConnection)
+	}
+	return conns
+}
+
+func (m *RelayManager) accept(l *relayListener) {
+	for {
+		client, err := l.ln.Accept()
+		if err != nil {
+			return
+		}
+		l.mu.Lock()
+		if l.stopped() {
+			l.mu.Unlock()
+			client.Close()
+			return
+		}
+		l.wg.Add(1)
+		l.mu.Unlock()
+		go m.serve(l, client)
+	}
+}
+
+// serve connects the client to the server and forwards frames both ways until
+// either side closes. A client whose server cannot be reached, or whose relay
+// was stopped while dialing, is disconnected.
+func (m *RelayManager) serve(l *relayListener, client net.Conn) {
+	defer l.wg.Done()
+	id := l.relay.ID
+	peer := client.RemoteAddr().String()
+
+	upstream, err := DialServer(l.server, dialTimeout)
+	if err != nil {
+		log.Printf("Relay[%d] %s: %v", id, peer, err)
+		m.hub.Broadcast(map[string]interface{}{"type": "relay_error", "relay_id": id, "peer": peer, "error": err.Error()})
+		client.Close()
+		return
+	}
+	s := &relaySession{
+		RelayConnection: RelayConnection{Peer: peer, Upstream: upstream.RemoteAddr().String(), ConnectedAt: time.Now()},
+		client:          client,
+		upstream:        upstream,
+	}
+	if err := m.db.Where("tcp_server_id = ?", l.server.ID).Order("id").Find(&s.packets).Error; err != nil {
+		log.Printf("Relay[%d] %s: %v", id, peer, err)
+	}
+	l.mu.Lock()
+	if l.stopped() {
+		l.mu.Unlock()
+		s.close()
+		return
+	}
+	l.sessions[peer] = s
+	l.mu.Unlock()
+	m.hub.Broadcast(map[string]interface{}{"type": "relay_connection", "relay_id": id, "peer": peer, "connected": true})
+	defer func() {
+		s.close()
+		l.mu.Lock()
+		delete(l.sessions, peer)
+		l.mu.Unlock()
+		m.hub.Broadcast(map[string]interface{}{"type": "relay_connection", "relay_id": id, "peer": peer, "connected": false})
+	}()
+
+	done := make(chan struct{}, 2)
+	for _, direction := range []string{models.DirectionClientToServer, models.DirectionServerToClient} {
+		src, dst := client, upstream
+		if direction == models.DirectionServerToClient {
+			src, dst = upstream, client
+		}
+		var faults *faultInjector
+		if l.relay.Faults.Applies(direction) {
+			faults = newFaultInjector(l.relay.Faults, l.format, l.relay.UseCRC)
+		}
+		go func() {
+			m.pipe(l, s, src, dst, direction, faults)
+			done <- struct{}{}
+		}()
+	}
+	<-done
+	s.close()
+	<-done
+}
+
+// maxRawRecord bounds how many raw bytes are recorded as one frame.
+const maxRawRecord = 64 * 1024
+
+// pipe forwards src to dst and records the traffic. Frames are forwarded
+// unchanged unless faults are injected. With UseCRC frames are split with the
+// server's framing and forwarded whole, and bytes that do not follow the
+// framing are forwarded and recorded as they are; otherwise see pipeRaw.
+func (m *RelayManager) pipe(l *relayListener, s *relaySession, src, dst net.Conn, direction string, faults *faultInjector) {
+	if !l.relay.UseCRC {
+		m.pipeRaw(l, s, src, dst, direction, faults)
+		return
+	}
+	defer m.flushHeld(l, s, dst, direction, faults)
+	reader := NewFrameReader(src)
+	split := tolerantSplit(l.format)
+	for {
+		frame, err := reader.ReadFrame(split, mockIdleTimeout)
+		if errors.Is(err, ErrReadTimeout) {
+			continue
+		}
+		if err != nil || len(frame) == 0 {
+			return
+		}
+		if _, err := l.format.Unpack(frame); err != nil {
+			log.Printf("Relay[%d] %s: recording %d bytes that do not follow the framing as raw: %v", l.relay.ID, s.Peer, len(frame), err)
+		}
+		applied, ok := m.forward(l, dst, frame, faults)
+		if applied != nil {
+			m.record(l, s, direction, applied.written(l.format, true), applied.Faults())
+		}
+		if !ok {
+			return
+		}
+	}
+}
+
+// pipeRaw forwards every read from src to dst as soon as it arrives, so the
+// relay adds no latency to unframed protocols. Only for recording, the bytes
+// are grouped into one frame until src stays idle for rawIdleGap or
+// maxRawRecord bytes are pending. Faults apply to each read.
+func (m *RelayManager) pipeRaw(l *relayListener, s *relaySession, src, dst net.Conn, direction string, faults *faultInjector) {
+	var pending []byte
+	var applied []string
+	flush := func() {
+		if len(pending) > 0 || len(applied) > 0 {
+			m.record(l, s, direction, pending, strings.Join(applied, ","))
+		}
+		pending, applied = nil, nil
+	}
+	defer func() {
+		flush()
+		m.flushHeld(l, s, dst, direction, faults)
+	}()
+
+	chunk := make([]byte, 4096)
+	for {
+		if len(pending) > 0 {
+			src.SetReadDeadline(time.Now().Add(rawIdleGap))
+		} else {
+			src.SetReadDeadline(time.Time{})
+		}
+		n, err := src.Read(chunk)
+		if n > 0 {
+			data := append([]byte(nil), chunk[:n]...)
+			plan, ok := m.forward(l, dst, data, faults)
+			if plan != nil {
+				pending = append(pending, plan.written(l.format, false)...)
+				if f := plan.Faults(); f != "" {
+					applied = append(applied, f)
+				}
+			}
+			if !ok {
+				return
+			}
+			if len(pending) >= maxRawRecord {
+				flush()
+			}
+		}
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			flush()
+			continue
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// forward writes one frame or raw read to dst with the planned faults. It
+// returns the plan when the data was handed to dst, or was dropped by it, and
+// false when the pipe must end because dst failed or was reset or the relay
+// was stopped during an injected delay.
+func (m *RelayManager) forward(l *relayListener, dst net.Conn, data []byte, faults *faultInjector) (*faultPlan, bool) {
+	plan := faults.plan(data)
+	if plan.delay > 0 {
+		select {
+		case <-time.After(plan.delay):
+		case <-l.done:
+			return nil, false
+		}
+	}
+	for _, out := range plan.frames {
+		if _, err := dst.Write(out); err != nil {
+			return nil, false
+		}
+	}
+	if plan.reset {
+		resetConn(dst)
+		return &plan, false
+	}
+	return &plan, true
+}
+
+// flushHeld writes the frame the direction's faults held back for reordering
+// before the pipe ends, and records it.
+func (m *RelayManager) flushHeld(l *relayListener, s *relaySession, dst net.Conn, direction string, faults *faultInjector) {
+	held := faults.flush()
+	if held == nil {
+		return
+	}
+	if _, err := dst.Write(held); err != nil {
+		log.Printf("Relay[%d] %s: held frame lost: %v", l.relay.ID, s.Peer, err)
+		return
+	}
+	m.record(l, s, direction, recordedFrame(l.format, l.relay.UseCRC, held), models.FaultReorder)
+}
+
+// record stores a relayed frame, decoded with the first packet definition of
+// the server that identifies it, with the faults injected into it and streams
+// it to WebSocket clients.
+func (m *RelayManager) record(l *relayListener, s *relaySession, direction string, payload []byte, faults string) {
+	history := models.TCPPacketHistory{
+		TCPServerID: l.server.ID,
+		RelayID:     l.relay.ID,
+		Direction:   direction,
+		Peer:        s.Peer,
+		Faults:      faults,
+	}
+	if direction == models.DirectionClientToServer {
+		history.Request = hex.EncodeToString(payload)
+	} else {
+		history.Response = hex.EncodeToString(payload)
+	}
+
+	for _, packet := range s.packets {
+		if !packet.Identifies(payload) {
+			continue
+		}
+		history.TCPPacketID = packet.ID
+		history.PacketName = packet.Name
+		history.PacketDesc = packet.Desc
+		history.Kind = packet.Kind
+		if decoded, err := json.Marshal(packet.Data.DecodeFields(payload)); err == nil {
+			history.Decoded = string(decoded)
+		}
+		break
+	}
+
+	if err := m.db.Create(&history).Error; err != nil {
+		log.Print(err)
+	}
+	m.hub.Broadcast(map[string]interface{}{
+		"type":        "relay_frame",
+		"relay_id":    history.RelayID,
+		"server_id":   history.TCPServerID,
+		"direction":   history.Direction,
+		"peer":        history.Peer,
+		"packet_id":   history.TCPPacketID,
+		"packet_name": history.PacketName,
+		"request":     history.Request,
+		"response":    history.Response,
+		"decoded":     history.Decoded,
+		"faults":      history.Faults,
+	})
+}
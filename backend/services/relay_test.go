@@ -0,0 +1,63 @@
+package services
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/fake-edge-server/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startStalledProxy starts an HTTP CONNECT proxy that holds each CONNECT
+// request until release is closed, then echoes through the tunnel.
+func startStalledProxy(t *testing.T, dialing chan<- struct{}, release <-chan struct{}) string {
+	return serveTCP(t, func(conn net.Conn) {
+		if _, err := http.ReadRequest(bufio.NewReader(conn)); err != nil {
+			return
+		}
+		dialing <- struct{}{}
+		<-release
+		io.WriteString(conn, "HTTP/1.1 200 OK\r\n\r\n")
+		echo(conn)
+	})
+}
+
+func TestRelayStopWhileDialing(t *testing.T) {
+	dialing := make(chan struct{}, 1)
+	release := make(chan struct{})
+	server := models.TCPServer{ID: 1, Host: "10.0.0.5", Port: 502,
+		Proxy: models.ProxySettings{Type: models.ProxyHTTP, Address: startStalledProxy(t, dialing, release)}}
+
+	relays := NewRelayManager(setupTestDB(), NewWebSocketHub())
+	require.NoError(t, relays.Start(models.Relay{ID: 1, BindAddr: "127.0.0.1"}, server))
+	addr, _ := relays.ListenAddr(1)
+	client, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer client.Close()
+	select {
+	case <-dialing:
+	case <-time.After(time.Second):
+		t.Fatal("중계가 서버에 접속하지 않음")
+	}
+
+	// 서버 접속 중에 중지하면 접속이 끝난 연결도 닫히고 Stop이 돌아옴
+	stopped := make(chan error, 1)
+	go func() { stopped <- relays.Stop(1) }()
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	select {
+	case err := <-stopped:
+		assert.NoError(t, err)
+	case <-time.After(3 * time.Second):
+		t.Fatal("Stop이 접속 중이던 연결을 기다리며 멈춤")
+	}
+
+	client.SetReadDeadline(time.Now().Add(time.Second))
+	_, err = client.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, io.EOF)
+}
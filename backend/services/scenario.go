@@ -0,0 +1,470 @@
+package services
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fake-edge-server/models"
+	"github.com/fake-edge-server/utils"
+	"gorm.io/gorm"
+)
+
+// maxScenarioSteps bounds how many steps one run may execute, so a branch that
+// always jumps backwards cannot run forever.
+const maxScenarioSteps = 10000
+
+// scenarioRun is a running scenario.
+type scenarioRun struct {
+	run      models.ScenarioRun
+	scenario models.Scenario
+	server   models.TCPServer
+	packets  map[uint]models.TCPPacket
+	format   utils.FrameFormat
+	vars     map[string][]byte
+
+	mu  sync.Mutex
+	log models.ScenarioLog
+
+	stop chan struct{}
+	once sync.Once
+	done chan struct{}
+}
+
+// stopped reports whether the run was asked to stop.
+func (sr *scenarioRun) stopped() bool {
+	select {
+	case <-sr.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// ScenarioRunner executes scenarios step by step over a connection of their
+// own, independent of the connection managed for sending packets.
+type ScenarioRunner struct {
+	mu   sync.Mutex
+	runs map[uint]*scenarioRun
+	db   *gorm.DB
+	hub  *WebSocketHub
+}
+
+// NewScenarioRunner creates a new ScenarioRunner.
+func NewScenarioRunner(db *gorm.DB, hub *WebSocketHub) *ScenarioRunner {
+	return &ScenarioRunner{
+		runs: make(map[uint]*scenarioRun),
+		db:   db,
+		hub:  hub,
+	}
+}
+
+// LoadScenarioPackets loads the packets sent by the scenario's send steps,
+// keyed by ID.
+func LoadScenarioPackets(db *gorm.DB, steps models.ScenarioSteps) (map[uint]models.TCPPacket, error) {
+	packets := make(map[uint]models.TCPPacket)
+	for i, step := range steps {
+		if step.Type != models.StepSend {
+			continue
+		}
+		if _, ok := packets[step.PacketID]; ok {
+			continue
+		}
+		var packet models.TCPPacket
+		if err := db.First(&packet, step.PacketID).Error; err != nil {
+			return nil, fmt.Errorf("%d번 단계: 패킷[%d]을 찾을 수 없습니다", i+1, step.PacketID)
+		}
+		if err := packet.ValidateKind(); err != nil {
+			return nil, fmt.Errorf("%d번 단계: %v", i+1, err)
+		}
+		packets[packet.ID] = packet
+	}
+	return packets, nil
+}
+
+// Launch saves a new run of the scenario against server and starts it. vars
+// override the scenario's initial variables.
+func (r *ScenarioRunner) Launch(scenario models.Scenario, server models.TCPServer, vars models.ScenarioVars) (models.ScenarioRun, error) {
+	packets, err := LoadScenarioPackets(r.db, scenario.Steps)
+	if err != nil {
+		return models.ScenarioRun{}, err
+	}
+
+	merged := make(models.ScenarioVars, len(scenario.Vars)+len(vars))
+	for name, value := range scenario.Vars {
+		merged[name] = value
+	}
+	for name, value := range vars {
+		merged[name] = value
+	}
+
+	now := time.Now()
+	run := models.ScenarioRun{
+		ScenarioID:  scenario.ID,
+		TCPServerID: server.ID,
+		Status:      models.ScenarioRunning,
+		Vars:        merged,
+		StartedAt:   &now,
+	}
+	if err := r.db.Create(&run).Error; err != nil {
+		return models.ScenarioRun{}, err
+	}
+	if err := r.Start(run, scenario, server, packets); err != nil {
+		r.db.Delete(&run)
+		return models.ScenarioRun{}, err
+	}
+	return run, nil
+}
+
+// Start executes the scenario in the background, starting from the run's
+// variables. The run must already be saved; its status, final variables and
+// step log are written back when it ends. packets must hold every packet the
+// scenario sends.
+func (r *ScenarioRunner) Start(run models.ScenarioRun, scenario models.Scenario, server models.TCPServer, packets map[uint]models.TCPPacket) error {
+	format, err := server.Framing.Format()
+	if err != nil {
+		return err
+	}
+	sr := &scenarioRun{
+		run:      run,
+		scenario: scenario,
+		server:   server,
+		packets:  packets,
+		format:   format,
+		vars:     run.Vars.Bytes(),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	r.mu.Lock()
+	r.runs[run.ID] = sr
+	r.mu.Unlock()
+
+	go r.execute(sr)
+	return nil
+}
+
+// Stop ends a running scenario after the current step.
+func (r *ScenarioRunner) Stop(runID uint) error {
+	r.mu.Lock()
+	sr, ok := r.runs[runID]
+	r.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("시나리오 실행[%d]이 진행 중이 아닙니다", runID)
+	}
+	sr.once.Do(func() { close(sr.stop) })
+	return nil
+}
+
+// Log returns the step log of a running scenario so far.
+func (r *ScenarioRunner) Log(runID uint) (models.ScenarioLog, bool) {
+	r.mu.Lock()
+	sr, ok := r.runs[runID]
+	r.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	return append(models.ScenarioLog{}, sr.log...), true
+}
+
+// Wait blocks until the run ends. It returns at once when the run is not in
+// progress.
+func (r *ScenarioRunner) Wait(runID uint) {
+	r.mu.Lock()
+	sr, ok := r.runs[runID]
+	r.mu.Unlock()
+	if ok {
+		<-sr.done
+	}
+}
+
+// IsRunning reports whether any run of the scenario is in progress.
+func (r *ScenarioRunner) IsRunning(scenarioID uint) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, sr := range r.runs {
+		if sr.scenario.ID == scenarioID {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ScenarioRunner) execute(sr *scenarioRun) {
+	status, reason := r.steps(sr)
+
+	sr.mu.Lock()
+	logs := append(models.ScenarioLog{}, sr.log...)
+	sr.mu.Unlock()
+	vars := models.VarsFromBytes(sr.vars)
+	err := r.db.Model(&models.ScenarioRun{}).Where("id = ?", sr.run.ID).Updates(map[string]interface{}{
+		"status":      status,
+		"error":       reason,
+		"vars":        vars,
+		"log":         logs,
+		"finished_at": time.Now(),
+	}).Error
+	if err != nil {
+		log.Print(err)
+	}
+
+	r.mu.Lock()
+	delete(r.runs, sr.run.ID)
+	r.mu.Unlock()
+	close(sr.done)
+	r.hub.Broadcast(map[string]interface{}{
+		"type":        "scenario_done",
+		"scenario_id": sr.scenario.ID,
+		"run_id":      sr.run.ID,
+		"server_id":   sr.server.ID,
+		"status":      status,
+		"error":       reason,
+		"vars":        vars,
+	})
+}
+
+// steps executes the scenario and returns the final status and the reason of
+// a failure. Execution follows the step order except where loop and branch
+// steps jump to another step.
+func (r *ScenarioRunner) steps(sr *scenarioRun) (string, string) {
+	conn, err := DialServer(sr.server, dialTimeout)
+	if err != nil {
+		return models.ScenarioFailed, "서버에 접속할 수 없습니다: " + err.Error()
+	}
+	closed := make(chan struct{})
+	defer close(closed)
+	go func() {
+		// 중지하면 응답 대기 중인 단계도 바로 끝나도록 연결을 닫음
+		select {
+		case <-sr.stop:
+		case <-closed:
+		}
+		conn.Close()
+	}()
+	reader := newFuzzReader(sr.server, conn)
+
+	ex := &scenarioExec{sr: sr, conn: conn, reader: reader, loops: make(map[int]int)}
+	steps := sr.scenario.Steps
+	for pc, executed := 0, 0; pc < len(steps); executed++ {
+		if sr.stopped() {
+			return models.ScenarioStopped, ""
+		}
+		if executed >= maxScenarioSteps {
+			return models.ScenarioFailed, fmt.Sprintf("실행 단계 수가 %d개를 넘었습니다", maxScenarioSteps)
+		}
+
+		step := steps[pc]
+		result := models.ScenarioStepResult{Index: pc, Name: step.Name, Type: step.Type, Status: models.StepPassed, At: time.Now()}
+		next, err := ex.step(pc, step, &result)
+		if sr.stopped() {
+			return models.ScenarioStopped, ""
+		}
+		if err != nil {
+			result.Status = models.StepFailed
+			result.Message = err.Error()
+		}
+		result.DurationMs = time.Since(result.At).Milliseconds()
+		r.report(sr, result)
+		if err != nil {
+			return models.ScenarioFailed, fmt.Sprintf("%d번 단계 실패: %v", pc+1, err)
+		}
+		pc = next
+	}
+	return models.ScenarioPassed, ""
+}
+
+// report appends a step result to the log and broadcasts it.
+func (r *ScenarioRunner) report(sr *scenarioRun, result models.ScenarioStepResult) {
+	sr.mu.Lock()
+	sr.log = append(sr.log, result)
+	sr.mu.Unlock()
+	r.hub.Broadcast(map[string]interface{}{
+		"type":        "scenario_step",
+		"scenario_id": sr.scenario.ID,
+		"run_id":      sr.run.ID,
+		"server_id":   sr.server.ID,
+		"step":        result,
+	})
+}
+
+// scenarioExec is the state of one run while it executes steps.
+type scenarioExec struct {
+	sr       *scenarioRun
+	conn     net.Conn
+	reader   *FrameReader
+	last     *models.TCPPacket // packet sent last, which decides how responses are framed
+	seq      uint64
+	response []byte // payload of the last response
+	loops    map[int]int
+}
+
+// awaitResponse reads the response to the packet sent last. Edge and Modbus
+// frames whose message or transaction ID is not the one the send step assigned,
+// such as pushes or late replies, are skipped and counted, as in exchangeEdge
+// and exchangeModbus.
+func (ex *scenarioExec) awaitResponse(timeout time.Duration) ([]byte, int, error) {
+	split := responseSplit(ex.sr.format, *ex.last)
+	match := replyMatch(ex.sr.format, *ex.last, ex.seq)
+	deadline := time.Now().Add(timeout)
+	skipped := 0
+	for {
+		frame, err := ex.reader.ReadFrame(split, time.Until(deadline))
+		if err != nil || match == nil {
+			return frame, skipped, err
+		}
+		ok, err := match(frame)
+		if err != nil {
+			return nil, skipped, err
+		}
+		if ok {
+			return frame, skipped, nil
+		}
+		skipped++
+	}
+}
+
+// step executes one step and returns the index of the next step.
+func (ex *scenarioExec) step(pc int, step models.ScenarioStep, result *models.ScenarioStepResult) (int, error) {
+	sr := ex.sr
+	steps := sr.scenario.Steps
+	switch step.Type {
+	case models.StepSend:
+		packet, ok := sr.packets[step.PacketID]
+		if !ok {
+			return 0, fmt.Errorf("패킷[%d]을 찾을 수 없습니다", step.PacketID)
+		}
+		payload, err := packetPayload(packet)
+		if err != nil {
+			return 0, err
+		}
+		payload = step.UseVars.Apply(payload, sr.vars)
+		ex.seq++
+		wire, _, err := encodePayload(sr.format, packet, payload, ex.seq)
+		if err != nil {
+			return 0, err
+		}
+		result.Request = hex.EncodeToString(payload)
+		if _, err := ex.conn.Write(wire); err != nil {
+			return 0, err
+		}
+		ex.last = &packet
+		result.Message = packet.Name
+
+	case models.StepExpect:
+		if ex.last == nil {
+			return 0, errors.New("응답을 기다리기 전에 패킷을 보내야 합니다")
+		}
+		timeout := responseWait(sr.server)
+		if step.TimeoutMs > 0 {
+			timeout = time.Duration(step.TimeoutMs) * time.Millisecond
+		}
+		frame, skipped, err := ex.awaitResponse(timeout)
+		if err != nil {
+			return 0, err
+		}
+		payload, err := decodePayload(sr.format, *ex.last, frame)
+		if err != nil {
+			return 0, err
+		}
+		ex.response = payload
+		result.Response = hex.EncodeToString(payload)
+		var checks []string
+		for _, check := range step.Assertions {
+			actual, ok, err := ex.eval(check)
+			if err != nil {
+				return 0, err
+			}
+			desc := fmt.Sprintf("[%d] %s %s %s", check.Offset, actual, check.Op, check.Value)
+			if !ok {
+				return 0, fmt.Errorf("검증 실패: %s", desc)
+			}
+			checks = append(checks, desc)
+		}
+		if skipped > 0 {
+			checks = append(checks, fmt.Sprintf("다른 프레임 %d개 무시", skipped))
+		}
+		result.Message = strings.Join(checks, ", ")
+
+	case models.StepWait:
+		select {
+		case <-time.After(time.Duration(step.DelayMs) * time.Millisecond):
+		case <-sr.stop:
+		}
+
+	case models.StepExtract:
+		if ex.response == nil {
+			return 0, errors.New("값을 추출할 응답이 없습니다")
+		}
+		if err := step.Extract.Capture(ex.response, sr.vars); err != nil {
+			return 0, err
+		}
+		result.Vars = make(models.ScenarioVars, len(step.Extract))
+		for _, v := range step.Extract {
+			result.Vars[v.Name] = hex.EncodeToString(sr.vars[v.Name])
+		}
+
+	case models.StepLoop:
+		if step.Condition != nil {
+			actual, ok, err := ex.eval(*step.Condition)
+			if err != nil {
+				return 0, err
+			}
+			if ok {
+				result.Message = "조건 만족: " + actual
+				delete(ex.loops, pc)
+				return pc + 1, nil
+			}
+		}
+		ex.loops[pc]++
+		if ex.loops[pc] < step.Count {
+			result.Message = fmt.Sprintf("%d/%d회 → %s", ex.loops[pc], step.Count, step.Target)
+			return steps.Index(step.Target), nil
+		}
+		delete(ex.loops, pc)
+		if step.Condition != nil {
+			return 0, fmt.Errorf("%d회 반복하는 동안 조건을 만족하지 않았습니다", step.Count)
+		}
+		result.Message = fmt.Sprintf("%d회 반복 완료", step.Count)
+
+	case models.StepBranch:
+		actual, ok, err := ex.eval(*step.Condition)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			result.Message = fmt.Sprintf("%s → %s", actual, step.Target)
+			return steps.Index(step.Target), nil
+		}
+		if step.Else != "" {
+			result.Message = fmt.Sprintf("%s → %s", actual, step.Else)
+			return steps.Index(step.Else), nil
+		}
+		result.Message = actual
+
+	default:
+		return 0, fmt.Errorf("지원되지 않는 단계 종류: %s", step.Type)
+	}
+	return pc + 1, nil
+}
+
+// eval evaluates a check against its variable or, without one, the last response.
+func (ex *scenarioExec) eval(check models.ScenarioCheck) (string, bool, error) {
+	subject := ex.response
+	if check.Var != "" {
+		value, ok := ex.sr.vars[check.Var]
+		if !ok {
+			return "", false, fmt.Errorf("정의되지 않은 변수: %s", check.Var)
+		}
+		subject = value
+	} else if subject == nil {
+		return "", false, errors.New("검사할 응답이 없습니다")
+	}
+	return check.Eval(subject, ex.sr.vars)
+}
@@ -0,0 +1,270 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/fake-edge-server/models"
+	"github.com/fake-edge-server/utils"
+	"gorm.io/gorm"
+)
+
+// scheduleJob runs a schedule's target against one server, filling in the
+// history or scenario run it produced.
+type scheduleJob func(server models.TCPServer, run *models.ScheduleRun) error
+
+// scheduleLoop is the running loop of one schedule. stop asks it to exit and
+// done is closed once it has.
+type scheduleLoop struct {
+	stop chan struct{}
+	done chan struct{}
+}
+
+// Scheduler runs enabled schedules at the times given by their cron
+// expressions. Each schedule waits for its previous run to finish, so a run
+// that overlaps the next due time skips it rather than piling up.
+type Scheduler struct {
+	mu        sync.Mutex
+	loops     map[uint]*scheduleLoop
+	db        *gorm.DB
+	hub       *WebSocketHub
+	sender    *PacketSender
+	scenarios *ScenarioRunner
+}
+
+// NewScheduler creates a new Scheduler.
+func NewScheduler(db *gorm.DB, hub *WebSocketHub, sender *PacketSender, scenarios *ScenarioRunner) *Scheduler {
+	return &Scheduler{
+		loops:     make(map[uint]*scheduleLoop),
+		db:        db,
+		hub:       hub,
+		sender:    sender,
+		scenarios: scenarios,
+	}
+}
+
+// Start starts every enabled schedule stored in the database.
+func (s *Scheduler) Start() {
+	var schedules []models.Schedule
+	if err := s.db.Where("enabled = ?", true).Find(&schedules).Error; err != nil {
+		log.Print(err)
+		return
+	}
+	for _, schedule := range schedules {
+		s.Reload(schedule.ID)
+	}
+}
+
+// Reload restarts a schedule after it changed. A disabled or deleted schedule
+// is stopped and its next run time cleared. When the schedule is running,
+// Reload waits for the run to finish so that runs never overlap and the old
+// loop cannot overwrite the next run time stored here.
+func (s *Scheduler) Reload(id uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if l, ok := s.loops[id]; ok {
+		close(l.stop)
+		<-l.done
+		delete(s.loops, id)
+	}
+
+	var schedule models.Schedule
+	if err := s.db.First(&schedule, id).Error; err != nil {
+		return
+	}
+	if !schedule.Enabled {
+		s.setNextRun(id, nil)
+		return
+	}
+	cron, err := schedule.CronSchedule()
+	if err != nil {
+		log.Printf("Schedule[%d]: %v", id, err)
+		return
+	}
+	// Store the first due time before returning so callers see it right away.
+	at := cron.Next(time.Now())
+	if at.IsZero() {
+		s.setNextRun(id, nil)
+		return
+	}
+	s.setNextRun(id, &at)
+	l := &scheduleLoop{stop: make(chan struct{}), done: make(chan struct{})}
+	s.loops[id] = l
+	go s.loop(schedule, cron, at, l)
+}
+
+// Trigger runs the schedule once in the background, outside its cron times.
+func (s *Scheduler) Trigger(schedule models.Schedule) {
+	go s.execute(schedule, true)
+}
+
+// loop waits for each due time at, runs the schedule and moves on to the
+// next time after the run has finished.
+func (s *Scheduler) loop(schedule models.Schedule, cron *utils.CronSchedule, at time.Time, l *scheduleLoop) {
+	defer close(l.done)
+	for {
+		timer := time.NewTimer(time.Until(at))
+		select {
+		case <-timer.C:
+		case <-l.stop:
+			timer.Stop()
+			return
+		}
+		s.execute(schedule, false)
+
+		select {
+		case <-l.stop:
+			return
+		default:
+		}
+		at = cron.Next(time.Now())
+		if at.IsZero() {
+			s.setNextRun(schedule.ID, nil)
+			return
+		}
+		s.setNextRun(schedule.ID, &at)
+	}
+}
+
+func (s *Scheduler) setNextRun(id uint, at *time.Time) {
+	if err := s.db.Model(&models.Schedule{}).Where("id = ?", id).Update("next_run_at", at).Error; err != nil {
+		log.Print(err)
+	}
+}
+
+// execute runs the schedule's target against each of its servers, records a
+// run per server and stores the overall outcome on the schedule.
+func (s *Scheduler) execute(schedule models.Schedule, manual bool) {
+	started := time.Now()
+	status, reason := models.ScheduleSucceeded, ""
+
+	servers, job, err := s.resolve(schedule)
+	if err != nil {
+		status, reason = models.ScheduleFailed, err.Error()
+		run := models.ScheduleRun{ScheduleID: schedule.ID, TCPServerID: schedule.TCPServerID, Manual: manual, StartedAt: started}
+		s.finish(&run, err)
+	}
+	for _, server := range servers {
+		run := models.ScheduleRun{
+			ScheduleID:  schedule.ID,
+			TCPServerID: server.ID,
+			Status:      models.ScheduleRunning,
+			Manual:      manual,
+			StartedAt:   time.Now(),
+		}
+		if err := s.db.Create(&run).Error; err != nil {
+			log.Print(err)
+			continue
+		}
+		err := job(server, &run)
+		s.finish(&run, err)
+		if err != nil && status == models.ScheduleSucceeded {
+			status, reason = models.ScheduleFailed, fmt.Sprintf("서버[%d]: %v", server.ID, err)
+		}
+	}
+
+	err = s.db.Model(&models.Schedule{}).Where("id = ?", schedule.ID).Updates(map[string]interface{}{
+		"last_run_at": started,
+		"last_status": status,
+		"last_error":  reason,
+	}).Error
+	if err != nil {
+		log.Print(err)
+	}
+}
+
+// finish stores the outcome of one run and broadcasts it.
+func (s *Scheduler) finish(run *models.ScheduleRun, err error) {
+	now := time.Now()
+	run.Status = models.ScheduleSucceeded
+	if err != nil {
+		run.Status = models.ScheduleFailed
+		run.Error = err.Error()
+	}
+	run.FinishedAt = &now
+	if err := s.db.Save(run).Error; err != nil {
+		log.Print(err)
+	}
+	s.hub.Broadcast(map[string]interface{}{
+		"type":        "schedule_run",
+		"schedule_id": run.ScheduleID,
+		"run":         run,
+	})
+}
+
+// resolve loads the schedule's target and the servers to run it against.
+func (s *Scheduler) resolve(schedule models.Schedule) ([]models.TCPServer, scheduleJob, error) {
+	var job scheduleJob
+	defaultServer := schedule.TCPServerID
+	switch schedule.Target {
+	case models.ScheduleTargetPacket:
+		var packet models.TCPPacket
+		if err := s.db.First(&packet, schedule.PacketID).Error; err != nil {
+			return nil, nil, fmt.Errorf("패킷[%d]을 찾을 수 없습니다", schedule.PacketID)
+		}
+		if defaultServer == 0 {
+			defaultServer = packet.TCPServerID
+		}
+		job = func(server models.TCPServer, run *models.ScheduleRun) error {
+			history, err := s.sender.SendOnce(server, packet)
+			if err != nil {
+				return err
+			}
+			run.HistoryID = history.ID
+			if history.Verdict == models.VerdictFail || history.Verdict == models.VerdictError {
+				return fmt.Errorf("스크립트 판정: %s", history.Verdict)
+			}
+			return nil
+		}
+	case models.ScheduleTargetScenario:
+		var scenario models.Scenario
+		if err := s.db.First(&scenario, schedule.ScenarioID).Error; err != nil {
+			return nil, nil, fmt.Errorf("시나리오[%d]를 찾을 수 없습니다", schedule.ScenarioID)
+		}
+		if defaultServer == 0 {
+			defaultServer = scenario.TCPServerID
+		}
+		job = func(server models.TCPServer, run *models.ScheduleRun) error {
+			sr, err := s.scenarios.Launch(scenario, server, nil)
+			if err != nil {
+				return err
+			}
+			run.ScenarioRunID = sr.ID
+			s.scenarios.Wait(sr.ID)
+			if err := s.db.First(&sr, sr.ID).Error; err != nil {
+				return err
+			}
+			if sr.Status != models.ScenarioPassed {
+				if sr.Error != "" {
+					return fmt.Errorf("시나리오 %s: %s", sr.Status, sr.Error)
+				}
+				return fmt.Errorf("시나리오 %s", sr.Status)
+			}
+			return nil
+		}
+	default:
+		return nil, nil, fmt.Errorf("지원되지 않는 예약 대상: %s", schedule.Target)
+	}
+
+	var servers []models.TCPServer
+	if schedule.AllServers {
+		if err := s.db.Find(&servers).Error; err != nil {
+			return nil, nil, err
+		}
+		if len(servers) == 0 {
+			return nil, nil, errors.New("등록된 TCP 서버가 없습니다")
+		}
+		return servers, job, nil
+	}
+	if defaultServer == 0 {
+		return nil, nil, errors.New("실행할 TCP 서버가 지정되지 않았습니다")
+	}
+	var server models.TCPServer
+	if err := s.db.First(&server, defaultServer).Error; err != nil {
+		return nil, nil, fmt.Errorf("TCP 서버[%d]를 찾을 수 없습니다", defaultServer)
+	}
+	return []models.TCPServer{server}, job, nil
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/fake-edge-server/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedulerReloadWaitsForRunningLoop(t *testing.T) {
+	db := setupTestDB()
+	hub := NewWebSocketHub()
+	scheduler := NewScheduler(db, hub, NewPacketSender(db, NewTCPConnectionManager(), hub), NewScenarioRunner(db, hub))
+
+	// 요청마다 300ms 뒤에 응답하는 장비
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				buf := make([]byte, 256)
+				for {
+					if _, err := conn.Read(buf); err != nil {
+						return
+					}
+					time.Sleep(300 * time.Millisecond)
+					conn.Write([]byte{0x81})
+				}
+			}()
+		}
+	}()
+
+	server := models.TCPServer{Name: "slow", Host: "127.0.0.1", Port: ln.Addr().(*net.TCPAddr).Port}
+	require.NoError(t, db.Create(&server).Error)
+	packet := models.TCPPacket{TCPServerID: server.ID, Name: "ping", Data: models.PacketData{{Offset: 0, Value: 0x01}}}
+	require.NoError(t, db.Create(&packet).Error)
+	schedule := models.Schedule{Name: "yearly", Cron: "0 0 1 1 *", Target: models.ScheduleTargetPacket, PacketID: packet.ID, Enabled: true}
+	require.NoError(t, db.Create(&schedule).Error)
+	cron, err := schedule.CronSchedule()
+	require.NoError(t, err)
+
+	// 바로 실행되는 루프를 띄우고 실행 중에 다시 읽음
+	l := &scheduleLoop{stop: make(chan struct{}), done: make(chan struct{})}
+	scheduler.mu.Lock()
+	scheduler.loops[schedule.ID] = l
+	scheduler.mu.Unlock()
+	go scheduler.loop(schedule, cron, time.Now(), l)
+	require.Eventually(t, func() bool {
+		var count int64
+		db.Model(&models.ScheduleRun{}).Where("status = ?", models.ScheduleRunning).Count(&count)
+		return count == 1
+	}, time.Second, 5*time.Millisecond)
+
+	scheduler.Reload(schedule.ID)
+	defer func() {
+		db.Model(&schedule).Update("enabled", false)
+		scheduler.Reload(schedule.ID)
+	}()
+
+	// 이전 루프의 실행이 끝난 뒤에 돌아오고, 다음 실행 시각은 새 루프의 값이 남음
+	var runs []models.ScheduleRun
+	require.NoError(t, db.Find(&runs).Error)
+	require.Len(t, runs, 1)
+	assert.NotEqual(t, models.ScheduleRunning, runs[0].Status)
+	select {
+	case <-l.done:
+	default:
+		t.Fatal("이전 루프가 끝나지 않았습니다")
+	}
+	var stored models.Schedule
+	require.NoError(t, db.First(&stored, schedule.ID).Error)
+	require.NotNil(t, stored.NextRunAt)
+	assert.Equal(t, cron.Next(time.Now()), stored.NextRunAt.In(cron.Location()))
+}
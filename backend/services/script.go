@@ -0,0 +1,317 @@
+package services
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fake-edge-server/models"
+	"github.com/fake-edge-server/utils"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkjson"
+	"go.starlark.net/syntax"
+)
+
+// Packet scripts are Starlark programs stored on a packet. Byte data is passed
+// to them as lists of ints. A pre-send script defines pre_send(data) and
+// returns the data to send (a list of ints or bytes; None keeps data). A
+// post-receive script defines post_receive(request, response) and returns a
+// verdict: None, a bool, "pass"/"fail", or a dict with verdict, message and
+// decoded keys. decoded is stored as JSON in the history.
+//
+// Scripts only see the predeclared names below. There is no load(), so they
+// cannot reach the filesystem or network, and every run is cancelled after the
+// packet's script timeout.
+const (
+	preSendFunc     = "pre_send"
+	postReceiveFunc = "post_receive"
+
+	defaultScriptTimeout = time.Second
+	maxScriptTimeoutMs   = 10000
+)
+
+var scriptFileOptions = &syntax.FileOptions{Set: true, While: true, TopLevelControl: true}
+
+// scriptBuiltins are the names predeclared for every packet script.
+var scriptBuiltins = starlark.StringDict{
+	"json":   starlarkjson.Module,
+	"hex":    starlark.NewBuiltin("hex", scriptHex),
+	"unhex":  starlark.NewBuiltin("unhex", scriptUnhex),
+	"crc32":  starlark.NewBuiltin("crc32", scriptChecksum(utils.FastCRC32)),
+	"crc32c": starlark.NewBuiltin("crc32c", scriptChecksum(utils.FastCRC32C)),
+	"xor":    starlark.NewBuiltin("xor", scriptXor),
+	"sum":    starlark.NewBuiltin("sum", scriptSum),
+}
+
+// ValidateScripts compiles the packet's scripts and checks that each defines
+// its hook function, without running them.
+func ValidateScripts(packet models.TCPPacket) error {
+	if packet.ScriptTimeoutMs < 0 || packet.ScriptTimeoutMs > maxScriptTimeoutMs {
+		return fmt.Errorf("script_timeout_ms는 0~%d 사이여야 합니다", maxScriptTimeoutMs)
+	}
+	if packet.PreSendScript != "" {
+		if _, err := compileScript(preSendFunc, packet.PreSendScript); err != nil {
+			return fmt.Errorf("전송 전 스크립트 오류: %v", err)
+		}
+	}
+	if packet.PostReceiveScript != "" {
+		if _, err := compileScript(postReceiveFunc, packet.PostReceiveScript); err != nil {
+			return fmt.Errorf("수신 후 스크립트 오류: %v", err)
+		}
+	}
+	return nil
+}
+
+// compileScript parses and resolves src and checks that it defines fn.
+func compileScript(fn, src string) (*starlark.Program, error) {
+	f, prog, err := starlark.SourceProgramOptions(scriptFileOptions, fn+".star", src, scriptBuiltins.Has)
+	if err != nil {
+		return nil, err
+	}
+	for _, stmt := range f.Stmts {
+		if def, ok := stmt.(*syntax.DefStmt); ok && def.Name.Name == fn {
+			return prog, nil
+		}
+	}
+	return nil, fmt.Errorf("%s 함수가 정의되어 있지 않습니다", fn)
+}
+
+// scriptVerdict is the outcome of a post-receive script.
+type scriptVerdict struct {
+	verdict string
+	message string
+	decoded string
+}
+
+// runPreSend runs the packet's pre-send script on data and returns the bytes
+// to send and what the script printed. For Modbus packets the returned bytes
+// are the PDU and must hold a function code.
+func runPreSend(packet models.TCPPacket, data []byte) ([]byte, string, error) {
+	var out strings.Builder
+	result, err := runScript(packet, preSendFunc, packet.PreSendScript, &out, scriptList(data))
+	if err != nil {
+		return nil, out.String(), err
+	}
+	if result == starlark.None {
+		return data, out.String(), nil
+	}
+	sent, err := scriptData(result)
+	if err == nil && packet.Kind == models.PacketKindModbus {
+		err = utils.CheckModbusPDU(sent)
+	}
+	if err != nil {
+		return nil, out.String(), fmt.Errorf("%s 반환값: %v", preSendFunc, err)
+	}
+	return sent, out.String(), nil
+}
+
+// runPostReceive runs the packet's post-receive script on a request and its
+// response and returns the verdict and what the script printed.
+func runPostReceive(packet models.TCPPacket, request, response []byte) (scriptVerdict, string, error) {
+	var out strings.Builder
+	result, err := runScript(packet, postReceiveFunc, packet.PostReceiveScript, &out, scriptList(request), scriptList(response))
+	if err != nil {
+		return scriptVerdict{}, out.String(), err
+	}
+	verdict, err := toVerdict(result)
+	return verdict, out.String(), err
+}
+
+// toVerdict interprets the value returned by post_receive.
+func toVerdict(v starlark.Value) (scriptVerdict, error) {
+	switch v := v.(type) {
+	case starlark.NoneType:
+		return scriptVerdict{verdict: models.VerdictPass}, nil
+	case starlark.Bool:
+		if v {
+			return scriptVerdict{verdict: models.VerdictPass}, nil
+		}
+		return scriptVerdict{verdict: models.VerdictFail}, nil
+	case starlark.String:
+		if v != models.VerdictPass && v != models.VerdictFail {
+			return scriptVerdict{}, fmt.Errorf("알 수 없는 판정: %s", v)
+		}
+		return scriptVerdict{verdict: string(v)}, nil
+	case *starlark.Dict:
+		result := scriptVerdict{verdict: models.VerdictPass}
+		if value, found, _ := v.Get(starlark.String("verdict")); found {
+			verdict, err := toVerdict(value)
+			if err != nil {
+				return scriptVerdict{}, err
+			}
+			result.verdict = verdict.verdict
+		}
+		if value, found, _ := v.Get(starlark.String("message")); found {
+			s, ok := starlark.AsString(value)
+			if !ok {
+				s = value.String()
+			}
+			result.message = s
+		}
+		if value, found, _ := v.Get(starlark.String("decoded")); found {
+			encoded, err := starlark.Call(new(starlark.Thread), starlarkjson.Module.Members["encode"], starlark.Tuple{value}, nil)
+			if err != nil {
+				return scriptVerdict{}, err
+			}
+			result.decoded = string(encoded.(starlark.String))
+		}
+		return result, nil
+	}
+	return scriptVerdict{}, fmt.Errorf("%s는 None, bool, 문자열 또는 dict를 반환해야 합니다 (%s)", postReceiveFunc, v.Type())
+}
+
+// runScript executes src and calls fn with args on a fresh thread, cancelling
+// it when the packet's script timeout elapses.
+func runScript(packet models.TCPPacket, fn, src string, out *strings.Builder, args ...starlark.Value) (starlark.Value, error) {
+	prog, err := compileScript(fn, src)
+	if err != nil {
+		return nil, err
+	}
+	timeout := defaultScriptTimeout
+	if packet.ScriptTimeoutMs > 0 {
+		timeout = time.Duration(packet.ScriptTimeoutMs) * time.Millisecond
+	}
+
+	thread := &starlark.Thread{
+		Name:  fmt.Sprintf("packet-%d-%s", packet.ID, fn),
+		Print: func(_ *starlark.Thread, msg string) { out.WriteString(msg + "\n") },
+		Load: func(*starlark.Thread, string) (starlark.StringDict, error) {
+			return nil, errors.New("load는 사용할 수 없습니다")
+		},
+	}
+	timer := time.AfterFunc(timeout, func() { thread.Cancel("timeout") })
+	result, err := func() (starlark.Value, error) {
+		globals, err := prog.Init(thread, scriptBuiltins)
+		if err != nil {
+			return nil, err
+		}
+		return starlark.Call(thread, globals[fn], args, nil)
+	}()
+	if !timer.Stop() {
+		return nil, fmt.Errorf("스크립트 실행 시간(%s)을 초과했습니다", timeout)
+	}
+	if err != nil {
+		var evalErr *starlark.EvalError
+		if errors.As(err, &evalErr) {
+			return nil, errors.New(evalErr.Backtrace())
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+// scriptData converts bytes or a list/tuple of ints (0-255) into a byte slice.
+func scriptData(v starlark.Value) ([]byte, error) {
+	if b, ok := v.(starlark.Bytes); ok {
+		return []byte(b), nil
+	}
+	iterable, ok := v.(starlark.Indexable)
+	if !ok {
+		return nil, fmt.Errorf("bytes 또는 정수 목록이어야 합니다 (%s)", v.Type())
+	}
+	data := make([]byte, iterable.Len())
+	for i := range data {
+		var n int
+		if err := starlark.AsInt(iterable.Index(i), &n); err != nil || n < 0 || n > 0xff {
+			return nil, fmt.Errorf("%d번째 값 %s는 0~255 사이의 정수여야 합니다", i, iterable.Index(i))
+		}
+		data[i] = byte(n)
+	}
+	return data, nil
+}
+
+// scriptList converts a byte slice into a list of ints that scripts can
+// index, slice, concatenate and modify.
+func scriptList(data []byte) *starlark.List {
+	elems := make([]starlark.Value, len(data))
+	for i, b := range data {
+		elems[i] = starlark.MakeInt(int(b))
+	}
+	return starlark.NewList(elems)
+}
+
+// dataArgs unpacks the builtin's positional arguments as byte data.
+func dataArgs(b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple, n int) ([][]byte, error) {
+	values := make([]starlark.Value, n)
+	pointers := make([]interface{}, n)
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, n, pointers...); err != nil {
+		return nil, err
+	}
+	out := make([][]byte, n)
+	for i, v := range values {
+		data, err := scriptData(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", b.Name(), err)
+		}
+		out[i] = data
+	}
+	return out, nil
+}
+
+// scriptHex returns the lower-case hex encoding of data.
+func scriptHex(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	data, err := dataArgs(b, args, kwargs, 1)
+	if err != nil {
+		return nil, err
+	}
+	return starlark.String(hex.EncodeToString(data[0])), nil
+}
+
+// scriptUnhex decodes a hex string into a list of ints.
+func scriptUnhex(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var s string
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &s); err != nil {
+		return nil, err
+	}
+	data, err := hex.DecodeString(strings.ReplaceAll(s, " ", ""))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", b.Name(), err)
+	}
+	return scriptList(data), nil
+}
+
+// scriptChecksum wraps a 32-bit checksum as a builtin returning an int.
+func scriptChecksum(sum func([]byte) uint32) func(*starlark.Thread, *starlark.Builtin, starlark.Tuple, []starlark.Tuple) (starlark.Value, error) {
+	return func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		data, err := dataArgs(b, args, kwargs, 1)
+		if err != nil {
+			return nil, err
+		}
+		return starlark.MakeUint64(uint64(sum(data[0]))), nil
+	}
+}
+
+// scriptSum returns the sum of the bytes, for additive checksums.
+func scriptSum(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	data, err := dataArgs(b, args, kwargs, 1)
+	if err != nil {
+		return nil, err
+	}
+	var total int
+	for _, v := range data[0] {
+		total += int(v)
+	}
+	return starlark.MakeInt(total), nil
+}
+
+// scriptXor XORs data with a repeating key.
+func scriptXor(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	data, err := dataArgs(b, args, kwargs, 2)
+	if err != nil {
+		return nil, err
+	}
+	key := data[1]
+	if len(key) == 0 {
+		return nil, fmt.Errorf("%s: 빈 키", b.Name())
+	}
+	out := make([]byte, len(data[0]))
+	for i := range out {
+		out[i] = data[0][i] ^ key[i%len(key)]
+	}
+	return scriptList(out), nil
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/fake-edge-server/models"
+	"github.com/fake-edge-server/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreSendScriptRewritesData(t *testing.T) {
+	packet := models.TCPPacket{PreSendScript: `
+def pre_send(data):
+    sum = crc32(data)
+    print("crc", sum)
+    return xor(data, [0x0f]) + [sum & 0xff]
+`}
+	require.NoError(t, ValidateScripts(packet))
+
+	data, out, err := runPreSend(packet, []byte{0x01, 0x02})
+	require.NoError(t, err)
+	sum := utils.FastCRC32([]byte{0x01, 0x02})
+	assert.Equal(t, []byte{0x0e, 0x0d, byte(sum)}, data)
+	assert.Contains(t, out, "crc ")
+
+	packet.PreSendScript = "def pre_send(data):\n    return None\n"
+	data, _, err = runPreSend(packet, []byte{0x01})
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x01}, data)
+
+	packet.PreSendScript = "def pre_send(data):\n    data[0] = 0x7f\n    return data\n"
+	data, _, err = runPreSend(packet, []byte{0x01, 0x02})
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x7f, 0x02}, data)
+
+	packet.PreSendScript = "def pre_send(data):\n    return [256]\n"
+	_, _, err = runPreSend(packet, []byte{0x01})
+	assert.ErrorContains(t, err, "0~255 사이의 정수")
+	packet.PreSendScript = "def pre_send(data):\n    return 1\n"
+	_, _, err = runPreSend(packet, []byte{0x01})
+	assert.ErrorContains(t, err, "정수 목록이어야 합니다")
+
+	// Modbus 패킷은 반환값이 PDU이므로 함수 코드가 있어야 함
+	packet.Kind = models.PacketKindModbus
+	packet.PreSendScript = "def pre_send(data):\n    return []\n"
+	_, _, err = runPreSend(packet, []byte{0x03, 0x00, 0x00, 0x00, 0x01})
+	assert.ErrorContains(t, err, "Modbus PDU가 비어 있습니다")
+	packet.PreSendScript = "def pre_send(data):\n    return [0] * 254\n"
+	_, _, err = runPreSend(packet, []byte{0x03})
+	assert.ErrorContains(t, err, "253바이트 이하")
+}
+
+func TestPostReceiveScriptVerdicts(t *testing.T) {
+	packet := models.TCPPacket{PostReceiveScript: `
+def post_receive(request, response):
+    if response[0] != request[0]:
+        return {"verdict": "fail", "message": "command " + hex(response[:1])}
+    return {"decoded": {"value": response[1], "raw": hex(response)}}
+`}
+	result, _, err := runPostReceive(packet, []byte{0x01}, []byte{0x01, 0x2a})
+	require.NoError(t, err)
+	assert.Equal(t, models.VerdictPass, result.verdict)
+	assert.JSONEq(t, `{"value":42,"raw":"012a"}`, result.decoded)
+
+	result, _, err = runPostReceive(packet, []byte{0x01}, []byte{0x02, 0x00})
+	require.NoError(t, err)
+	assert.Equal(t, models.VerdictFail, result.verdict)
+	assert.Equal(t, "command 02", result.message)
+
+	packet.PostReceiveScript = "def post_receive(request, response):\n    return len(response) > 0\n"
+	result, _, err = runPostReceive(packet, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, models.VerdictFail, result.verdict)
+
+	packet.PostReceiveScript = "def post_receive(request, response):\n    return \"maybe\"\n"
+	_, _, err = runPostReceive(packet, nil, nil)
+	assert.ErrorContains(t, err, "알 수 없는 판정")
+}
+
+func TestScriptTimeoutAndSandbox(t *testing.T) {
+	packet := models.TCPPacket{ScriptTimeoutMs: 50, PreSendScript: `
+def pre_send(data):
+    while True:
+        data = data + []
+`}
+	_, _, err := runPreSend(packet, []byte{0x01})
+	assert.ErrorContains(t, err, "실행 시간(50ms)을 초과했습니다")
+
+	// load와 파일/네트워크 관련 내장 함수는 없음
+	packet.PreSendScript = "load(\"os.star\", \"open\")\ndef pre_send(data):\n    return data\n"
+	_, _, err = runPreSend(packet, []byte{0x01})
+	assert.ErrorContains(t, err, "load는 사용할 수 없습니다")
+	packet.PreSendScript = "def pre_send(data):\n    return open(\"/etc/passwd\")\n"
+	assert.ErrorContains(t, ValidateScripts(packet), "undefined: open")
+
+	// 실행 오류는 스크립트 위치와 함께 보고됨
+	packet.PreSendScript = "def pre_send(data):\n    return data[10]\n"
+	_, _, err = runPreSend(packet, []byte{0x01})
+	assert.ErrorContains(t, err, "pre_send.star:2")
+}
+
+func TestValidateScripts(t *testing.T) {
+	assert.NoError(t, ValidateScripts(models.TCPPacket{}))
+	assert.ErrorContains(t, ValidateScripts(models.TCPPacket{PreSendScript: "x = 1\n"}), "pre_send 함수가 정의되어 있지 않습니다")
+	assert.ErrorContains(t, ValidateScripts(models.TCPPacket{PostReceiveScript: "def post_receive(:\n"}), "수신 후 스크립트 오류")
+	assert.Error(t, ValidateScripts(models.TCPPacket{ScriptTimeoutMs: 60000}))
+}
+
+func TestPostReceiveScriptExcludedFromLatency(t *testing.T) {
+	host, port, _ := net.SplitHostPort(serveTCP(t, echo))
+	server := models.TCPServer{ID: 1, Host: host}
+	server.Port, _ = strconv.Atoi(port)
+	connManager := NewTCPConnectionManager()
+	defer connManager.Disconnect(server.ID)
+	sender := NewPacketSender(setupTestDB(), connManager, NewWebSocketHub())
+
+	// 시간 제한까지 도는 스크립트 실행 시간은 응답 시간에 포함하지 않음
+	packet := models.TCPPacket{TCPServerID: server.ID, ScriptTimeoutMs: 300,
+		Data: models.PacketData{{Offset: 0, Value: 1, Type: models.TypeUint8}},
+		PostReceiveScript: `
+def post_receive(request, response):
+    while True:
+        pass
+`}
+	history, latency, err := sender.sendOnce(server, packet, []byte{1})
+	require.NoError(t, err)
+	assert.Equal(t, models.VerdictError, history.Verdict)
+	assert.Less(t, latency, 300*time.Millisecond)
+}
@@ -0,0 +1,395 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"time"
+
+	"github.com/fake-edge-server/models"
+)
+
+// ErrJobNotRunning is returned when stopping a repeat job that is not running.
+var ErrJobNotRunning = errors.New("실행 중인 전송 작업이 없습니다")
+
+// sendJob is a running repeat job.
+type sendJob struct {
+	id   uint
+	stop chan struct{}
+}
+
+func jobKey(serverID, packetID uint) string {
+	return fmt.Sprintf("%d:%d", serverID, packetID)
+}
+
+// Start begins sending the packet repeatedly as described by spec and stores
+// the job. If the packet is already being sent to the server, the running job
+// is returned.
+func (p *PacketSender) Start(server models.TCPServer, packet models.TCPPacket, spec models.SendJobSpec) (*models.SendJob, error) {
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	if !spec.Repeat() {
+		return nil, errors.New("interval_ms는 0보다 커야 합니다")
+	}
+	data, err := packetPayload(packet)
+	if err != nil {
+		return nil, err
+	}
+
+	key := jobKey(server.ID, packet.ID)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if running, exists := p.jobs[key]; exists {
+		var job models.SendJob
+		if err := p.db.First(&job, running.id).Error; err != nil {
+			return nil, err
+		}
+		return &job, nil
+	}
+
+	job := models.SendJob{
+		TCPServerID: server.ID,
+		ServerName:  server.Name,
+		TCPPacketID: packet.ID,
+		PacketName:  packet.Name,
+		SendJobSpec: spec,
+		Status:      models.SendJobRunning,
+		StartedAt:   time.Now(),
+	}
+	if err := p.db.Create(&job).Error; err != nil {
+		return nil, err
+	}
+	p.run(key, job, server, packet, data)
+	return &job, nil
+}
+
+// run starts the loop of a stored job. The caller must hold p.mu.
+func (p *PacketSender) run(key string, job models.SendJob, server models.TCPServer, packet models.TCPPacket, data []byte) {
+	stop := make(chan struct{})
+	p.jobs[key] = &sendJob{id: job.ID, stop: stop}
+	go p.loop(key, job, server, packet, data, stop)
+}
+
+// loop sends bursts of the packet at the job's interval, varied by its
+// jitter, until the job is stopped or reaches its count or duration limit.
+// The duration is measured from the job's start time, so a resumed job only
+// runs for what is left of it. While the server is reconnecting the job is
+// paused and sends nothing.
+func (p *PacketSender) loop(key string, job models.SendJob, server models.TCPServer, packet models.TCPPacket, data []byte, stop chan struct{}) {
+	var end time.Time
+	var deadline <-chan time.Time
+	if job.DurationMs > 0 {
+		end = job.StartedAt.Add(time.Duration(job.DurationMs) * time.Millisecond)
+		timer := time.NewTimer(time.Until(end))
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	done := func() bool {
+		return job.Count > 0 && job.Sent >= int64(job.Count) || !end.IsZero() && !time.Now().Before(end)
+	}
+	burst := max(job.Burst, 1)
+
+	next := time.Now()
+	for !done() {
+		// Keep the average rate when a burst overruns the interval, but do
+		// not send extra bursts to catch up.
+		next = next.Add(jobDelay(job.SendJobSpec))
+		if now := time.Now(); next.Before(now) {
+			next = now
+		}
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-timer.C:
+		case <-deadline:
+			timer.Stop()
+		case <-stop:
+			timer.Stop()
+			return
+		}
+
+		if reconnected := p.connManager.Reconnecting(server.ID); reconnected != nil {
+			if !p.pause(key, &job, reconnected, stop, deadline) {
+				return
+			}
+			next = time.Now()
+			if done() {
+				break
+			}
+		}
+
+		for i := 0; i < burst && !done(); i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			p.tick(&job, server, packet, data)
+		}
+	}
+	p.complete(key, &job)
+}
+
+// pause holds the job until the server's reconnect ends, marking it paused
+// meanwhile. When the reconnect gives up and leaves the server Dead, the job
+// fails rather than redialing the server on every tick. It returns false when
+// the job is stopped while paused or fails.
+func (p *PacketSender) pause(key string, job *models.SendJob, reconnected <-chan struct{}, stop chan struct{}, deadline <-chan time.Time) bool {
+	if !p.setStatus(key, job, models.SendJobPaused, "job_paused") {
+		return false
+	}
+	select {
+	case <-reconnected:
+	case <-deadline:
+		// complete replaces the paused status
+		return true
+	case <-stop:
+		return false
+	}
+	if p.connManager.GetStatus(job.TCPServerID) == "Dead" {
+		job.Error = "서버 재연결에 실패했습니다"
+		p.finish(key, job, models.SendJobFailed)
+		return false
+	}
+	return p.setStatus(key, job, models.SendJobRunning, "job_resumed")
+}
+
+// setStatus stores and broadcasts the status of a job that is still
+// registered. It returns false when the job was stopped in the meantime.
+func (p *PacketSender) setStatus(key string, job *models.SendJob, status, event string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	running, ok := p.jobs[key]
+	if !ok || running.id != job.ID {
+		return false
+	}
+	job.Status = status
+	if err := p.db.Model(&models.SendJob{}).Where("id = ?", job.ID).Update("status", status).Error; err != nil {
+		log.Print(err)
+	}
+	p.hub.Broadcast(map[string]interface{}{
+		"type": event,
+		"job":  job,
+	})
+	return true
+}
+
+// jobDelay returns the job's interval varied by up to ±JitterPct percent.
+func jobDelay(spec models.SendJobSpec) time.Duration {
+	interval := time.Duration(spec.IntervalMs) * time.Millisecond
+	if spec.JitterPct == 0 {
+		return interval
+	}
+	spread := float64(interval) * float64(spec.JitterPct) / 100
+	return interval + time.Duration((rand.Float64()*2-1)*spread)
+}
+
+// complete marks a job that reached its limit as completed and broadcasts a
+// summary. A job that was stopped in the meantime stays stopped.
+func (p *PacketSender) complete(key string, job *models.SendJob) {
+	p.finish(key, job, models.SendJobCompleted)
+}
+
+// finish ends a job that is still registered with the given status and
+// broadcasts a summary.
+func (p *PacketSender) finish(key string, job *models.SendJob, status string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	running, ok := p.jobs[key]
+	if !ok || running.id != job.ID {
+		return
+	}
+	delete(p.jobs, key)
+
+	now := time.Now()
+	job.Status = status
+	job.StoppedAt = &now
+	err := p.db.Model(&models.SendJob{}).Where("id = ?", job.ID).Updates(map[string]interface{}{
+		"status":     job.Status,
+		"error":      job.Error,
+		"stopped_at": job.StoppedAt,
+	}).Error
+	if err != nil {
+		log.Print(err)
+	}
+	p.hub.Broadcast(map[string]interface{}{
+		"type": "job_done",
+		"job":  job,
+		"summary": map[string]interface{}{
+			"sent":       job.Sent,
+			"succeeded":  job.Succeeded,
+			"failed":     job.Failed,
+			"elapsed_ms": now.Sub(job.StartedAt).Milliseconds(),
+			"avg_rtt_ms": job.AvgRTTMs,
+			"last_error": job.LastError,
+		},
+	})
+}
+
+// tick sends the packet once for the job, then stores and broadcasts the
+// job's updated metrics.
+func (p *PacketSender) tick(job *models.SendJob, server models.TCPServer, packet models.TCPPacket, data []byte) {
+	history, rtt, err := p.sendOnce(server, packet, data)
+	now := time.Now()
+	metrics := &job.SendJobMetrics
+	metrics.Sent++
+	metrics.LastSentAt = &now
+	if err == nil && (history.Verdict == models.VerdictFail || history.Verdict == models.VerdictError) {
+		err = fmt.Errorf("스크립트 판정: %s", history.Verdict)
+	}
+	if history != nil {
+		metrics.LastRTTMs = float64(rtt.Microseconds()) / 1000
+		metrics.LastResponse = history.Response
+	}
+	if err != nil {
+		metrics.Failed++
+		metrics.LastError = err.Error()
+	} else {
+		metrics.Succeeded++
+		metrics.AvgRTTMs += (metrics.LastRTTMs - metrics.AvgRTTMs) / float64(metrics.Succeeded)
+	}
+
+	err = p.db.Model(&models.SendJob{}).Where("id = ?", job.ID).Updates(map[string]interface{}{
+		"sent":          metrics.Sent,
+		"succeeded":     metrics.Succeeded,
+		"failed":        metrics.Failed,
+		"last_error":    metrics.LastError,
+		"last_rtt_ms":   metrics.LastRTTMs,
+		"avg_rtt_ms":    metrics.AvgRTTMs,
+		"last_response": metrics.LastResponse,
+		"last_sent_at":  metrics.LastSentAt,
+	}).Error
+	if err != nil {
+		log.Print(err)
+	}
+	p.hub.Broadcast(map[string]interface{}{
+		"type": "job_metrics",
+		"job":  job,
+	})
+}
+
+// Stop terminates the background sending job of the packet on the server and
+// marks it stopped. It returns ErrJobNotRunning when no such job is running.
+func (p *PacketSender) Stop(serverID, packetID uint) (*models.SendJob, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.stop(jobKey(serverID, packetID))
+}
+
+// StopJob terminates the running job with the given ID.
+func (p *PacketSender) StopJob(id uint) (*models.SendJob, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for key, job := range p.jobs {
+		if job.id == id {
+			return p.stop(key)
+		}
+	}
+	return nil, ErrJobNotRunning
+}
+
+// stop closes the job's loop and stores its stopped state. The caller must
+// hold p.mu.
+func (p *PacketSender) stop(key string) (*models.SendJob, error) {
+	running, ok := p.jobs[key]
+	if !ok {
+		return nil, ErrJobNotRunning
+	}
+	close(running.stop)
+	delete(p.jobs, key)
+
+	now := time.Now()
+	var job models.SendJob
+	err := p.db.Model(&models.SendJob{}).Where("id = ?", running.id).Updates(map[string]interface{}{
+		"status":     models.SendJobStopped,
+		"stopped_at": &now,
+	}).Error
+	if err == nil {
+		err = p.db.First(&job, running.id).Error
+	}
+	if err != nil {
+		return nil, err
+	}
+	p.hub.Broadcast(map[string]interface{}{
+		"type": "job_stopped",
+		"job":  job,
+	})
+	return &job, nil
+}
+
+// ResumeJobs picks up the jobs that were running or paused when the backend
+// last stopped, according to policy (models.JobResumeContinue when empty). Jobs
+// whose server or packet no longer exists are marked failed.
+func (p *PacketSender) ResumeJobs(policy string) {
+	if !models.ValidResumePolicy(policy) {
+		log.Printf("unknown job_resume_policy %q, using %q", policy, models.JobResumeContinue)
+		policy = models.JobResumeContinue
+	}
+	var jobs []models.SendJob
+	if err := p.db.Where("status IN ?", []string{models.SendJobRunning, models.SendJobPaused}).Find(&jobs).Error; err != nil {
+		log.Print(err)
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, job := range jobs {
+		key := jobKey(job.TCPServerID, job.TCPPacketID)
+		if _, exists := p.jobs[key]; exists {
+			continue
+		}
+		if err := p.resume(key, job, policy); err != nil {
+			log.Printf("SendJob[%d]: %v", job.ID, err)
+		}
+	}
+}
+
+// resume restarts one stored job or records why it was not restarted. The
+// restart policy also clears the job's metrics.
+func (p *PacketSender) resume(key string, job models.SendJob, policy string) error {
+	now := time.Now()
+	if policy == models.JobResumeNone {
+		return p.db.Model(&job).Updates(map[string]interface{}{
+			"status":     models.SendJobInterrupted,
+			"stopped_at": &now,
+		}).Error
+	}
+
+	var server models.TCPServer
+	var packet models.TCPPacket
+	var data []byte
+	err := p.db.First(&server, job.TCPServerID).Error
+	if err != nil {
+		err = fmt.Errorf("서버[%d]를 찾을 수 없습니다", job.TCPServerID)
+	} else if err = p.db.First(&packet, job.TCPPacketID).Error; err != nil {
+		err = fmt.Errorf("패킷[%d]을 찾을 수 없습니다", job.TCPPacketID)
+	} else if !job.Repeat() {
+		err = fmt.Errorf("잘못된 전송 간격: %dms", job.IntervalMs)
+	} else {
+		data, err = packetPayload(packet)
+	}
+	if err != nil {
+		if dbErr := p.db.Model(&job).Updates(map[string]interface{}{
+			"status":     models.SendJobFailed,
+			"error":      err.Error(),
+			"stopped_at": &now,
+		}).Error; dbErr != nil {
+			return dbErr
+		}
+		return err
+	}
+
+	job.Status = models.SendJobRunning
+	job.Resumes++
+	job.ResumedAt = &now
+	if policy == models.JobResumeRestart {
+		job.StartedAt = now
+		job.SendJobMetrics = models.SendJobMetrics{}
+	}
+	if err := p.db.Save(&job).Error; err != nil {
+		return err
+	}
+	p.run(key, job, server, packet, data)
+	return nil
+}
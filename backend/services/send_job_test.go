@@ -0,0 +1,24 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fake-edge-server/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobDelayJitter(t *testing.T) {
+	assert.Equal(t, 500*time.Millisecond, jobDelay(models.SendJobSpec{IntervalMs: 500}))
+
+	spec := models.SendJobSpec{IntervalMs: 500, JitterPct: 20}
+	var lo, hi bool
+	for i := 0; i < 1000; i++ {
+		d := jobDelay(spec)
+		assert.GreaterOrEqual(t, d, 400*time.Millisecond)
+		assert.LessOrEqual(t, d, 600*time.Millisecond)
+		lo = lo || d < 450*time.Millisecond
+		hi = hi || d > 550*time.Millisecond
+	}
+	assert.True(t, lo && hi, "지터가 간격 양쪽으로 퍼져야 함")
+}
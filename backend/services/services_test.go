@@ -21,6 +21,21 @@ func setupTestDB() *gorm.DB {
 		&models.TCPConnection{},
 		&models.TCPServer{},
 		&models.TCPPacket{},
+		&models.TCPPacketHistory{},
+		&models.MockEndpoint{},
+		&models.MockRule{},
+		&models.MockPush{},
+		&models.Recording{},
+		&models.RecordingEntry{},
+		&models.Relay{},
+		&models.LoadTest{},
+		&models.FuzzJob{},
+		&models.FuzzCase{},
+		&models.Scenario{},
+		&models.ScenarioRun{},
+		&models.Schedule{},
+		&models.ScheduleRun{},
+		&models.SendJob{},
 	)
 	if err != nil {
 		panic("마이그레이션 실패: " + err.Error())
@@ -1,86 +1,314 @@
 package services
 
 import (
-	"fmt"
+	"errors"
+	"log"
 	"net"
 	"sync"
 	"time"
+
+	"github.com/fake-edge-server/models"
+	"gorm.io/gorm"
 )
 
+// datagramAliveWindow is how long a UDP target counts as Alive after its last response.
+const datagramAliveWindow = 30 * time.Second
+
+// errReconnectCanceled is returned when a reconnect attempt finishes after the
+// reconnect was canceled by Disconnect, MarkDead or a new ConnectServer call.
+var errReconnectCanceled = errors.New("재연결이 취소되었습니다")
+
 // TCPConnectionManager manages persistent TCP connections keyed by ID.
 type TCPConnectionManager struct {
-	mu     sync.Mutex
-	conns  map[uint]net.Conn
-	status map[uint]string
+	mu         sync.Mutex
+	conns      map[uint]net.Conn
+	readers    map[uint]*FrameReader
+	status     map[uint]string
+	info       map[uint]ConnectionInfo
+	reconnects map[uint]*reconnect
+	hub        *WebSocketHub
+	db         *gorm.DB
+}
+
+// reconnect is a reconnect in progress for a dropped connection.
+type reconnect struct {
+	attempt int
+	cancel  chan struct{}
+	done    chan struct{} // closed when the reconnect succeeds, gives up or is canceled
 }
 
 // NewTCPConnectionManager creates a new TCPConnectionManager instance.
 func NewTCPConnectionManager() *TCPConnectionManager {
 	return &TCPConnectionManager{
-		conns:  make(map[uint]net.Conn),
-		status: make(map[uint]string),
+		conns:      make(map[uint]net.Conn),
+		readers:    make(map[uint]*FrameReader),
+		status:     make(map[uint]string),
+		info:       make(map[uint]ConnectionInfo),
+		reconnects: make(map[uint]*reconnect),
 	}
 }
 
-// Connect establishes a TCP connection for the given id and stores it.
-// No timeouts or deadlines are set; the connection remains until Stop is called.
+// SetHub sets the hub used to broadcast reconnect progress.
+func (m *TCPConnectionManager) SetHub(hub *WebSocketHub) {
+	m.mu.Lock()
+	m.hub = hub
+	m.mu.Unlock()
+}
+
+// SetDB sets the database used to reload a server's settings when its
+// connection drops.
+func (m *TCPConnectionManager) SetDB(db *gorm.DB) {
+	m.mu.Lock()
+	m.db = db
+	m.mu.Unlock()
+}
+
+// Connect establishes a plain TCP connection for the given id and stores it.
 func (m *TCPConnectionManager) Connect(id uint, host string, port int) error {
-	addr := fmt.Sprintf("%s:%d", host, port)
-	conn, err := net.Dial("tcp", addr)
+	return m.ConnectServer(models.TCPServer{ID: id, Host: host, Port: port})
+}
+
+// ConnectServer establishes a connection to the server using its transport
+// settings and stores it under the server ID. A reconnect in progress for the
+// server is canceled.
+// No read deadlines are set; the connection remains until Stop is called.
+func (m *TCPConnectionManager) ConnectServer(server models.TCPServer) error {
+	m.mu.Lock()
+	m.cancelReconnect(server.ID)
+	m.mu.Unlock()
+	return m.connect(server, nil)
+}
+
+// connect dials the server and stores the connection. When rc is set, the
+// dial is an attempt of that reconnect: a failure leaves the status as
+// Reconnecting, and the connection is discarded if the reconnect was canceled
+// while dialing.
+func (m *TCPConnectionManager) connect(server models.TCPServer, rc *reconnect) error {
+	id := server.ID
+	conn, err := DialServer(server, dialTimeout)
 	if err != nil {
-		m.mu.Lock()
-		m.status[id] = "Dead"
-		m.mu.Unlock()
+		if rc == nil {
+			m.mu.Lock()
+			m.status[id] = "Dead"
+			delete(m.info, id)
+			m.mu.Unlock()
+		}
 		return err
 	}
 
+	var reader *FrameReader
+	if server.IsDatagram() {
+		reader = NewDatagramReader(conn)
+	} else {
+		reader = NewFrameReader(conn)
+	}
+	info := describeConn(conn)
+	info.Transport = server.Network()
+	if server.Proxy.Enabled() {
+		// 프록시를 거치면 연결의 상대 주소는 프록시이므로 대상 주소를 표시한다
+		info.Proxy = server.Proxy.Type + "://" + server.Proxy.Address
+		info.RemoteAddr = server.Address()
+	}
 	m.mu.Lock()
+	if rc != nil {
+		if m.reconnects[id] != rc {
+			m.mu.Unlock()
+			conn.Close()
+			return errReconnectCanceled
+		}
+		delete(m.reconnects, id)
+	}
 	if old, ok := m.conns[id]; ok {
 		old.Close()
 	}
 	m.conns[id] = conn
+	m.readers[id] = reader
+	m.info[id] = info
 	m.status[id] = "Alive"
 	m.mu.Unlock()
 
-	go m.monitor(id, conn)
+	go m.monitor(server, conn, reader)
 	return nil
 }
 
-// monitor waits for the connection to be closed and marks it as Dead.
-func (m *TCPConnectionManager) monitor(id uint, conn net.Conn) {
-	buf := make([]byte, 1)
-	for {
-		time.Sleep(10 * time.Millisecond)
-		_, err := conn.Read(buf)
-		if err != nil {
-			m.mu.Lock()
-			conn.Close()
-			delete(m.conns, id)
-			m.status[id] = "Dead"
-			m.mu.Unlock()
+// monitor waits for the connection to be closed and, depending on the
+// server's reconnect policy, marks it as Dead or starts reconnecting.
+// The server is reloaded first, so settings edited while connected apply to
+// the reconnect.
+// The FrameReader is the only reader of the connection, so incoming bytes are
+// never consumed here.
+func (m *TCPConnectionManager) monitor(server models.TCPServer, conn net.Conn, reader *FrameReader) {
+	<-reader.Done()
+	server = m.reload(server)
+	id := server.ID
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	conn.Close()
+	if m.conns[id] != conn {
+		return
+	}
+	delete(m.conns, id)
+	delete(m.readers, id)
+	delete(m.info, id)
+	if !server.Reconnect.Enabled() {
+		m.status[id] = "Dead"
+		return
+	}
+	rc := &reconnect{cancel: make(chan struct{}), done: make(chan struct{})}
+	m.reconnects[id] = rc
+	m.status[id] = "Reconnecting"
+	go m.reconnect(server, rc)
+}
+
+// reload returns the stored settings of the server, or server itself when no
+// database is set or the server cannot be loaded.
+func (m *TCPConnectionManager) reload(server models.TCPServer) models.TCPServer {
+	m.mu.Lock()
+	db := m.db
+	m.mu.Unlock()
+	if db == nil {
+		return server
+	}
+	var stored models.TCPServer
+	if err := db.First(&stored, server.ID).Error; err != nil {
+		log.Printf("TCPServer[%d]: using settings from connect time: %v", server.ID, err)
+		return server
+	}
+	return stored
+}
+
+// reconnect dials the server again, waiting before each attempt as the
+// server's policy describes, until it connects, runs out of attempts or is
+// canceled. Every attempt and the outcome are broadcast as status messages.
+func (m *TCPConnectionManager) reconnect(server models.TCPServer, rc *reconnect) {
+	defer close(rc.done)
+	id := server.ID
+	policy := server.Reconnect
+	var err error
+	attempt := 1
+	for ; policy.MaxAttempts == 0 || attempt <= policy.MaxAttempts; attempt++ {
+		delay := policy.Delay(attempt)
+		m.mu.Lock()
+		rc.attempt = attempt
+		m.mu.Unlock()
+		m.broadcast(map[string]interface{}{
+			"type":         "status",
+			"server_id":    id,
+			"status":       "Reconnecting",
+			"attempt":      attempt,
+			"max_attempts": policy.MaxAttempts,
+			"delay_ms":     delay.Milliseconds(),
+		})
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-rc.cancel:
+			timer.Stop()
+			return
+		}
+		err = m.connect(server, rc)
+		if err == nil {
+			log.Printf("TCPServer[%d]: reconnected after %d attempt(s)", id, attempt)
+			m.broadcast(map[string]interface{}{
+				"type":      "status",
+				"server_id": id,
+				"status":    "Alive",
+				"attempt":   attempt,
+			})
 			return
 		}
+		if errors.Is(err, errReconnectCanceled) {
+			return
+		}
+		log.Printf("TCPServer[%d]: reconnect attempt %d failed: %v", id, attempt, err)
 	}
+
+	m.mu.Lock()
+	if m.reconnects[id] != rc {
+		m.mu.Unlock()
+		return
+	}
+	delete(m.reconnects, id)
+	m.status[id] = "Dead"
+	m.mu.Unlock()
+	m.broadcast(map[string]interface{}{
+		"type":      "status",
+		"server_id": id,
+		"status":    "Dead",
+		"attempt":   attempt - 1,
+		"error":     err.Error(),
+	})
 }
 
-// Disconnect closes and removes the connection for the given id and sets status to Wait.
+// cancelReconnect stops the reconnect in progress for the given id, if any.
+// The caller must hold m.mu.
+func (m *TCPConnectionManager) cancelReconnect(id uint) {
+	if rc, ok := m.reconnects[id]; ok {
+		close(rc.cancel)
+		delete(m.reconnects, id)
+	}
+}
+
+// Reconnecting returns a channel that is closed when the reconnect in
+// progress for the given id ends, or nil when the connection is not
+// reconnecting.
+func (m *TCPConnectionManager) Reconnecting(id uint) <-chan struct{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if rc, ok := m.reconnects[id]; ok {
+		return rc.done
+	}
+	return nil
+}
+
+// ReconnectAttempt returns the current attempt of the reconnect in progress
+// for the given id. ok is false when the connection is not reconnecting.
+func (m *TCPConnectionManager) ReconnectAttempt(id uint) (attempt int, ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if rc, ok := m.reconnects[id]; ok {
+		return rc.attempt, true
+	}
+	return 0, false
+}
+
+// broadcast sends msg to the hub when one is set.
+func (m *TCPConnectionManager) broadcast(msg interface{}) {
+	m.mu.Lock()
+	hub := m.hub
+	m.mu.Unlock()
+	if hub != nil {
+		hub.Broadcast(msg)
+	}
+}
+
+// Disconnect closes and removes the connection for the given id, cancels a
+// reconnect in progress and sets status to Wait.
 func (m *TCPConnectionManager) Disconnect(id uint) {
 	m.mu.Lock()
 	if conn, ok := m.conns[id]; ok {
 		conn.Close()
 		delete(m.conns, id)
+		delete(m.readers, id)
+		delete(m.info, id)
 	}
+	m.cancelReconnect(id)
 	m.status[id] = "Wait"
 	m.mu.Unlock()
 }
 
-// MarkDead forcibly marks the connection as Dead and closes it if present.
+// MarkDead forcibly marks the connection as Dead, closes it if present and
+// cancels a reconnect in progress.
 func (m *TCPConnectionManager) MarkDead(id uint) {
 	m.mu.Lock()
 	if conn, ok := m.conns[id]; ok {
 		conn.Close()
 		delete(m.conns, id)
+		delete(m.readers, id)
+		delete(m.info, id)
 	}
+	m.cancelReconnect(id)
 	m.status[id] = "Dead"
 	m.mu.Unlock()
 }
@@ -90,12 +318,24 @@ func (m *TCPConnectionManager) MarkDead(id uint) {
 func (m *TCPConnectionManager) GetStatus(id uint) string {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	if info, ok := m.info[id]; ok && info.Transport == models.TransportUDP {
+		return datagramStatus(m.readers[id].LastRead())
+	}
 	if s, ok := m.status[id]; ok {
 		return s
 	}
 	return "Wait"
 }
 
+// datagramStatus reports a UDP target as Alive while responses keep arriving,
+// since there is no connection state to observe.
+func datagramStatus(lastRead time.Time) string {
+	if lastRead.IsZero() || time.Since(lastRead) > datagramAliveWindow {
+		return "Silent"
+	}
+	return "Alive"
+}
+
 func (m *TCPConnectionManager) GetConn(id uint) net.Conn {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -104,3 +344,31 @@ func (m *TCPConnectionManager) GetConn(id uint) net.Conn {
 	}
 	return nil
 }
+
+// GetReader returns the buffered frame reader of the connection for the given id.
+func (m *TCPConnectionManager) GetReader(id uint) *FrameReader {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.readers[id]
+}
+
+// GetInfo returns details of the current connection for the given id.
+// ok is false when no connection is established.
+func (m *TCPConnectionManager) GetInfo(id uint) (ConnectionInfo, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	info, ok := m.info[id]
+	if ok {
+		if last := m.readers[id].LastRead(); !last.IsZero() {
+			info.LastResponseAt = &last
+		}
+	}
+	return info, ok
+}
+
+// session returns the connection and its reader as one consistent pair.
+func (m *TCPConnectionManager) session(id uint) (net.Conn, *FrameReader) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.conns[id], m.readers[id]
+}
@@ -2,9 +2,11 @@ package services
 
 import (
 	"net"
+	"strconv"
 	"testing"
 	"time"
 
+	"github.com/fake-edge-server/models"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -43,3 +45,123 @@ func TestTCPConnectionManager(t *testing.T) {
 	time.Sleep(100 * time.Millisecond)
 	assert.Equal(t, "Dead", mgr.GetStatus(1))
 }
+
+func TestTCPConnectionManagerHostnameAndIPv6(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		for {
+			if _, err := ln.Accept(); err != nil {
+				return
+			}
+		}
+	}()
+
+	mgr := NewTCPConnectionManager()
+	port := ln.Addr().(*net.TCPAddr).Port
+	assert.NoError(t, mgr.Connect(1, "localhost", port))
+	defer mgr.Disconnect(1)
+	info, ok := mgr.GetInfo(1)
+	assert.True(t, ok)
+	assert.Equal(t, net.JoinHostPort("127.0.0.1", strconv.Itoa(port)), info.RemoteAddr)
+
+	ln6, err := net.Listen("tcp", "[::1]:0")
+	if err != nil {
+		t.Skip("IPv6 loopback을 사용할 수 없습니다")
+	}
+	defer ln6.Close()
+	go ln6.Accept()
+
+	port6 := ln6.Addr().(*net.TCPAddr).Port
+	assert.NoError(t, mgr.Connect(2, "::1", port6))
+	defer mgr.Disconnect(2)
+	info, _ = mgr.GetInfo(2)
+	assert.Equal(t, "[::1]:"+strconv.Itoa(port6), info.RemoteAddr)
+}
+
+func TestTCPConnectionManagerReconnect(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer ln.Close()
+	accepted := make(chan net.Conn, 10)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- conn
+		}
+	}()
+
+	mgr := NewTCPConnectionManager()
+	server := models.TCPServer{
+		ID:        1,
+		Host:      "127.0.0.1",
+		Port:      ln.Addr().(*net.TCPAddr).Port,
+		Reconnect: models.ReconnectPolicy{Mode: models.ReconnectBackoff, MaxAttempts: 3, InitialMs: 50, MaxMs: 100},
+	}
+	assert.NoError(t, mgr.ConnectServer(server))
+	assert.Nil(t, mgr.Reconnecting(1))
+
+	// 서버가 연결을 끊으면 재연결
+	(<-accepted).Close()
+	var done <-chan struct{}
+	assert.Eventually(t, func() bool {
+		done = mgr.Reconnecting(1)
+		return done != nil
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, "Reconnecting", mgr.GetStatus(1))
+	attempt, ok := mgr.ReconnectAttempt(1)
+	assert.True(t, ok)
+	assert.Equal(t, 1, attempt)
+	<-done
+	assert.Equal(t, "Alive", mgr.GetStatus(1))
+	_, ok = mgr.ReconnectAttempt(1)
+	assert.False(t, ok)
+
+	// 서버가 사라지면 max_attempts만큼 시도한 뒤 Dead
+	ln.Close()
+	started := time.Now()
+	(<-accepted).Close()
+	assert.Eventually(t, func() bool {
+		done = mgr.Reconnecting(1)
+		return done != nil
+	}, time.Second, 5*time.Millisecond)
+	<-done
+	assert.GreaterOrEqual(t, time.Since(started), 250*time.Millisecond) // 50 + 100 + 100
+	assert.Equal(t, "Dead", mgr.GetStatus(1))
+}
+
+func TestTCPConnectionManagerReconnectCanceled(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, _ := ln.Accept()
+		accepted <- conn
+	}()
+
+	mgr := NewTCPConnectionManager()
+	server := models.TCPServer{
+		ID:        1,
+		Host:      "127.0.0.1",
+		Port:      ln.Addr().(*net.TCPAddr).Port,
+		Reconnect: models.ReconnectPolicy{Mode: models.ReconnectImmediate, InitialMs: 20},
+	}
+	assert.NoError(t, mgr.ConnectServer(server))
+	ln.Close()
+	(<-accepted).Close()
+
+	// 무제한 재시도 중에도 Disconnect로 멈춤
+	assert.Eventually(t, func() bool {
+		attempt, _ := mgr.ReconnectAttempt(1)
+		return attempt >= 3
+	}, time.Second, 5*time.Millisecond)
+	done := mgr.Reconnecting(1)
+	mgr.Disconnect(1)
+	<-done
+	assert.Equal(t, "Wait", mgr.GetStatus(1))
+	assert.Nil(t, mgr.Reconnecting(1))
+}
@@ -3,8 +3,7 @@ package services
 import (
 	"errors"
 	"fmt"
-	"io"
-	"net"
+	"strconv"
 	"time"
 
 	"github.com/fake-edge-server/config"
@@ -38,29 +37,35 @@ func NewTCPService(db *gorm.DB) *TCPService {
 	}
 }
 
-// SendRequest는 지정된 TCP 서버로 데이터를 전송하고 응답을 받습니다.
-func (s *TCPService) SendRequest(serverName, data string, requestID uint) (string, error) {
-	// 설정에서 서버 찾기
-	var serverAddr string
-	var serverPort string
-	serverFound := false
+// findServer는 이름으로 등록된 TCP 서버를 찾고, 없으면 설정 파일의 서버를 사용합니다.
+func (s *TCPService) findServer(serverName string) (models.TCPServer, error) {
+	var server models.TCPServer
+	if err := s.DB.Where("name = ?", serverName).First(&server).Error; err == nil {
+		return server, nil
+	}
 
-	for _, server := range config.GetConfig().TCPServers {
-		if server.Name == serverName {
-			serverAddr = server.Address
-			serverPort = server.Port
-			serverFound = true
-			break
+	for _, cfgServer := range config.GetConfig().TCPServers {
+		if cfgServer.Name == serverName {
+			port, err := strconv.Atoi(cfgServer.Port)
+			if err != nil {
+				return server, fmt.Errorf("설정된 서버 포트가 올바르지 않습니다: %s", cfgServer.Port)
+			}
+			return models.TCPServer{Name: cfgServer.Name, Host: cfgServer.Address, Port: port}, nil
 		}
 	}
+	return server, errors.New("지정된 서버를 찾을 수 없습니다")
+}
 
-	if !serverFound {
-		return "", errors.New("지정된 서버를 찾을 수 없습니다")
+// SendRequest는 지정된 TCP 서버로 데이터를 전송하고 응답을 받습니다.
+func (s *TCPService) SendRequest(serverName, data string, requestID uint) (string, error) {
+	server, err := s.findServer(serverName)
+	if err != nil {
+		return "", err
 	}
 
-	// TCP 연결 생성
-	addr := fmt.Sprintf("%s:%s", serverAddr, serverPort)
-	conn, err := net.DialTimeout("tcp", addr, 5*time.Second)
+	// TCP 연결 생성 (서버의 TLS 설정 적용)
+	addr := server.Address()
+	conn, err := DialServer(server, 5*time.Second)
 	if err != nil {
 		// 연결 실패 기록
 		s.logConnection(requestID, serverName, addr, data, "", false, err.Error())
@@ -76,17 +81,15 @@ func (s *TCPService) SendRequest(serverName, data string, requestID uint) (strin
 		return "", fmt.Errorf("데이터 전송 실패: %v", err)
 	}
 
-	// 응답 읽기
-	response := make([]byte, 4096)
-	conn.SetReadDeadline(time.Now().Add(10 * time.Second))
-	n, err := conn.Read(response)
-	if err != nil && err != io.EOF {
+	// 응답 읽기 (여러 세그먼트로 나뉘어 도착해도 모두 모은다)
+	response, err := NewFrameReader(conn).ReadFrame(nil, 10*time.Second)
+	if err != nil {
 		// 읽기 실패 기록
 		s.logConnection(requestID, serverName, addr, data, "", false, err.Error())
 		return "", fmt.Errorf("응답 읽기 실패: %v", err)
 	}
 
-	responseStr := string(response[:n])
+	responseStr := string(response)
 
 	// 성공 기록
 	s.logConnection(requestID, serverName, addr, data, responseStr, true, "")
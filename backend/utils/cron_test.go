@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCronNext(t *testing.T) {
+	base := time.Date(2026, 3, 14, 10, 7, 30, 0, time.UTC)
+	cases := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 3, 14, 10, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 3, 14, 10, 15, 0, 0, time.UTC)},
+		{"0 9-17/4 * * *", time.Date(2026, 3, 14, 13, 0, 0, 0, time.UTC)},
+		{"30 2 1 * *", time.Date(2026, 4, 1, 2, 30, 0, 0, time.UTC)},
+		{"0 0 * * mon", time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"0 0 13 * fri", time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC)}, // 일 또는 요일
+		{"0 2 * * 1-5", time.Date(2026, 3, 16, 2, 0, 0, 0, time.UTC)},
+		{"0 0 1 * 1", time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC)},
+		{"0 0 */2 * 1", time.Date(2026, 3, 23, 0, 0, 0, 0, time.UTC)}, // */n이면 일과 요일이 모두 맞아야 함
+		{"0 0 29 feb *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, 3, 14, 11, 0, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		sched, err := ParseCron(c.spec, time.UTC)
+		require.NoError(t, err, c.spec)
+		assert.Equal(t, c.want, sched.Next(base), c.spec)
+	}
+}
+
+func TestCronDayOfMonthOrDayOfWeek(t *testing.T) {
+	// 평일 02:00: 토요일 다음은 월요일, 금요일 다음은 다음 월요일
+	sched, err := ParseCron("0 2 * * 1-5", time.UTC)
+	require.NoError(t, err)
+	at := time.Date(2026, 3, 19, 2, 0, 0, 0, time.UTC)
+	var runs []time.Time
+	for i := 0; i < 3; i++ {
+		at = sched.Next(at)
+		runs = append(runs, at)
+	}
+	assert.Equal(t, []time.Time{
+		time.Date(2026, 3, 20, 2, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 23, 2, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 24, 2, 0, 0, 0, time.UTC),
+	}, runs)
+
+	// 매월 1일 또는 월요일: 3/30(월) 다음은 4/1(수), 그다음은 4/6(월)
+	sched, err = ParseCron("0 0 1 * 1", time.UTC)
+	require.NoError(t, err)
+	at = time.Date(2026, 3, 29, 12, 0, 0, 0, time.UTC)
+	runs = nil
+	for i := 0; i < 3; i++ {
+		at = sched.Next(at)
+		runs = append(runs, at)
+	}
+	assert.Equal(t, []time.Time{
+		time.Date(2026, 3, 30, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 4, 6, 0, 0, 0, 0, time.UTC),
+	}, runs)
+}
+
+func TestCronNextInTimeZone(t *testing.T) {
+	seoul, err := time.LoadLocation("Asia/Seoul")
+	require.NoError(t, err)
+	sched, err := ParseCron("0 9 * * *", seoul)
+	require.NoError(t, err)
+	next := sched.Next(time.Date(2026, 3, 14, 1, 0, 0, 0, time.UTC))
+	assert.Equal(t, time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC), next.UTC())
+
+	// 서머타임 시작으로 없는 02:30은 건너뜀
+	ny, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	sched, _ = ParseCron("30 2 * * *", ny)
+	next = sched.Next(time.Date(2026, 3, 8, 0, 0, 0, 0, ny))
+	assert.Equal(t, time.Date(2026, 3, 9, 2, 30, 0, 0, ny), next)
+}
+
+func TestParseCronErrors(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "* * * foo *"} {
+		_, err := ParseCron(spec, time.UTC)
+		assert.Error(t, err, spec)
+	}
+}
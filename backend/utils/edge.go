@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// EdgeTypeSize는 프레임 페이로드 앞에 붙는 노드 타입(1) + 커맨드 타입(1) 길이입니다.
+const EdgeTypeSize = 2
+
+// BuildWithType은 노드 타입과 커맨드 타입을 페이로드 앞에 붙여 프레임을 생성합니다.
+func (f FrameFormat) BuildWithType(nodeType byte, commandType byte, payload []byte) ([]byte, error) {
+	typed := make([]byte, 0, EdgeTypeSize+len(payload))
+	typed = append(typed, nodeType, commandType)
+	typed = append(typed, payload...)
+	return f.Build(typed)
+}
+
+// UnpackWithType은 프레임을 검증하고 노드 타입, 커맨드 타입, 페이로드로 분리합니다.
+func (f FrameFormat) UnpackWithType(buf []byte) (byte, byte, []byte, error) {
+	payload, err := f.Unpack(buf)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	if len(payload) < EdgeTypeSize {
+		return 0, 0, nil, fmt.Errorf("노드/커맨드 타입 누락")
+	}
+	return payload[0], payload[1], payload[EdgeTypeSize:], nil
+}
+
+// BuildPacketWithType은 기본 프레임 형식으로 타입이 포함된 패킷을 생성합니다.
+// 기본 형식의 길이 필드는 4바이트이므로 4GiB 미만의 페이로드만 지원합니다.
+func BuildPacketWithType(nodeType byte, commandType byte, payload []byte) []byte {
+	frame, _ := DefaultFrameFormat.BuildWithType(nodeType, commandType, payload)
+	return frame
+}
+
+// UnpackPacketWithType validates and parses a packet into type and payload
+func UnpackPacketWithType(buf []byte) (byte, byte, []byte, error) {
+	return DefaultFrameFormat.UnpackWithType(buf)
+}
+
+// BuildEdgePacket은 타입 없이 Edge 페이로드를 기본 프레임으로 감쌉니다.
+func BuildEdgePacket(payload []byte) []byte {
+	return BuildPacket(payload)
+}
+
+// UnpackEdgePacket for Tests
+func UnpackEdgePacket(buf []byte) ([]byte, error) {
+	return UnpackPacket(buf)
+}
+
+// BuildEdgePayload는 [edgeId(1) + msgId(8) + data] 구조의 Edge 페이로드를 생성합니다.
+func BuildEdgePayload(edgeID byte, msgID uint64, data []byte) []byte {
+	buf := make([]byte, EdgePayloadOffset+len(data))
+	buf[EdgeIdOffset] = edgeID
+	binary.LittleEndian.PutUint64(buf[EdgeMsgStartOffset:EdgeMsgEndOffset], msgID)
+	copy(buf[EdgePayloadOffset:], data)
+	return buf
+}
+
+// ParseEdgePayload는 Edge 페이로드를 edgeId, msgId, data로 분리합니다.
+func ParseEdgePayload(payload []byte) (byte, uint64, []byte, error) {
+	if len(payload) < EdgePayloadOffset {
+		return 0, 0, nil, fmt.Errorf("Edge 페이로드 길이 부족")
+	}
+	msgID := binary.LittleEndian.Uint64(payload[EdgeMsgStartOffset:EdgeMsgEndOffset])
+	return payload[EdgeIdOffset], msgID, payload[EdgePayloadOffset:], nil
+}
+
+func GeneratePayloadForProxy(payload []byte, nodeId int8) []byte {
+	// 기존 패킷 구조: [edgeId(1) + msgId(8) + apiUrl]
+	// 변경된 패킷 구조: [edgeId(1) + msgId(8) + nodeId(1) + apiUrl]
+	// apiUrl := string(payload[EdgePayloadOffset:])
+
+	res := append([]byte{}, payload[:EdgePayloadOffset]...)
+	res = append(res, byte(nodeId))
+	res = append(res, payload[EdgePayloadOffset:]...)
+
+	return res
+}
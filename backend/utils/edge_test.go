@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPacketWithTypeRoundTrip(t *testing.T) {
+	frame := BuildPacketWithType(3, 7, []byte("body"))
+
+	nodeType, commandType, payload, err := UnpackPacketWithType(frame)
+	assert.NoError(t, err)
+	assert.Equal(t, byte(3), nodeType)
+	assert.Equal(t, byte(7), commandType)
+	assert.Equal(t, "body", string(payload))
+}
+
+func TestUnpackPacketWithTypeTooShort(t *testing.T) {
+	_, _, _, err := UnpackPacketWithType(BuildPacket([]byte{1}))
+	assert.Error(t, err)
+}
+
+func TestEdgePayloadRoundTrip(t *testing.T) {
+	payload := BuildEdgePayload(9, 42, []byte("/api/status"))
+	assert.Len(t, payload, EdgePayloadOffset+len("/api/status"))
+
+	edgeID, msgID, data, err := ParseEdgePayload(payload)
+	assert.NoError(t, err)
+	assert.Equal(t, byte(9), edgeID)
+	assert.Equal(t, uint64(42), msgID)
+	assert.Equal(t, "/api/status", string(data))
+
+	proxied := GeneratePayloadForProxy(payload, 5)
+	assert.Equal(t, byte(5), proxied[EdgePayloadOffset])
+	assert.Equal(t, "/api/status", string(proxied[EdgePayloadOffset+1:]))
+}
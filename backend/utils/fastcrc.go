@@ -13,13 +13,29 @@ var crc32Pool = sync.Pool{
 	},
 }
 
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+var crc32cPool = sync.Pool{
+	New: func() interface{} {
+		return crc32.New(crc32cTable)
+	},
+}
+
 // FastCRC32 는 풀링된 해시 객체를 사용하여 CRC32 계산 속도 향상
 func FastCRC32(data []byte) uint32 {
+	return pooledSum32(&crc32Pool, data)
+}
+
+// FastCRC32C 는 Castagnoli 다항식을 사용하는 CRC32C 값을 계산
+func FastCRC32C(data []byte) uint32 {
+	return pooledSum32(&crc32cPool, data)
+}
+
+func pooledSum32(pool *sync.Pool, data []byte) uint32 {
 	// hash.Hash32 인터페이스로 변환
-	h := crc32Pool.Get().(hash.Hash32)
+	h := pool.Get().(hash.Hash32)
 	h.Reset()
 	h.Write(data)
 	crc := h.Sum32()
-	crc32Pool.Put(h)
+	pool.Put(h)
 	return crc
 }
@@ -0,0 +1,265 @@
+package utils
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// 프레임 헤더 필드 이름
+const (
+	FieldMagic    = "magic"
+	FieldLength   = "length"
+	FieldChecksum = "checksum"
+)
+
+// 체크섬 알고리즘
+const (
+	ChecksumNone   = "none"
+	ChecksumCRC32  = "crc32"
+	ChecksumCRC32C = "crc32c"
+)
+
+// 체크섬 계산 범위
+const (
+	ChecksumScopePayload = "payload" // 페이로드만
+	ChecksumScopeFrame   = "frame"   // 체크섬 필드를 0으로 둔 헤더 + 페이로드
+)
+
+// checksums는 지원하는 체크섬 알고리즘과 계산 함수입니다. 모두 4바이트 값을 만듭니다.
+var checksums = map[string]func([]byte) uint32{
+	ChecksumCRC32:  FastCRC32,
+	ChecksumCRC32C: FastCRC32C,
+}
+
+// FrameFormat은 프레임 헤더의 매직 바이트, 필드 순서, 엔디안, 체크섬 알고리즘을 정의합니다.
+// 길이 필드는 페이로드 길이만 나타냅니다.
+type FrameFormat struct {
+	Magic         []byte
+	Fields        []string
+	Order         binary.ByteOrder
+	LengthSize    int
+	Checksum      string
+	ChecksumScope string
+}
+
+// DefaultFrameFormat은 기존 고정 헤더(0xABCD1234 + 길이 + IEEE CRC32, 리틀 엔디안)입니다.
+var DefaultFrameFormat = FrameFormat{
+	Magic:         []byte{0x34, 0x12, 0xcd, 0xab},
+	Fields:        []string{FieldMagic, FieldLength, FieldChecksum},
+	Order:         binary.LittleEndian,
+	LengthSize:    4,
+	Checksum:      ChecksumCRC32,
+	ChecksumScope: ChecksumScopePayload,
+}
+
+// Validate는 헤더 구성이 프레임을 만들고 해석할 수 있는지 확인합니다.
+func (f FrameFormat) Validate() error {
+	if f.Order == nil {
+		return fmt.Errorf("엔디안이 지정되지 않았습니다")
+	}
+	if f.LengthSize != 2 && f.LengthSize != 4 {
+		return fmt.Errorf("길이 필드 크기는 2 또는 4바이트여야 합니다: %d", f.LengthSize)
+	}
+	if f.Checksum != ChecksumNone {
+		if _, ok := checksums[f.Checksum]; !ok {
+			return fmt.Errorf("지원되지 않는 체크섬 알고리즘: %s", f.Checksum)
+		}
+		if f.ChecksumScope != ChecksumScopePayload && f.ChecksumScope != ChecksumScopeFrame {
+			return fmt.Errorf("지원되지 않는 체크섬 범위: %s", f.ChecksumScope)
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, field := range f.Fields {
+		switch field {
+		case FieldMagic, FieldLength, FieldChecksum:
+		default:
+			return fmt.Errorf("알 수 없는 헤더 필드: %s", field)
+		}
+		if seen[field] {
+			return fmt.Errorf("헤더 필드가 중복되었습니다: %s", field)
+		}
+		seen[field] = true
+	}
+	if !seen[FieldLength] {
+		return fmt.Errorf("헤더에 length 필드가 필요합니다")
+	}
+	if seen[FieldMagic] && len(f.Magic) == 0 {
+		return fmt.Errorf("magic 필드에 사용할 값이 없습니다")
+	}
+	if seen[FieldChecksum] != (f.Checksum != ChecksumNone) {
+		return fmt.Errorf("checksum 필드와 체크섬 알고리즘 설정이 일치하지 않습니다")
+	}
+	return nil
+}
+
+// fieldSize는 헤더 필드 하나가 차지하는 바이트 수를 반환합니다.
+func (f FrameFormat) fieldSize(field string) int {
+	switch field {
+	case FieldMagic:
+		return len(f.Magic)
+	case FieldLength:
+		return f.LengthSize
+	case FieldChecksum:
+		return 4
+	}
+	return 0
+}
+
+// FieldOffset은 헤더 안에서 필드의 시작 위치를 반환합니다. 필드가 없으면 -1입니다.
+func (f FrameFormat) FieldOffset(field string) int {
+	offset := 0
+	for _, name := range f.Fields {
+		if name == field {
+			return offset
+		}
+		offset += f.fieldSize(name)
+	}
+	return -1
+}
+
+// HeaderSize는 헤더 전체 길이를 반환합니다.
+func (f FrameFormat) HeaderSize() int {
+	size := 0
+	for _, name := range f.Fields {
+		size += f.fieldSize(name)
+	}
+	return size
+}
+
+func (f FrameFormat) putUint(buf []byte, size int, v uint32) {
+	if size == 2 {
+		f.Order.PutUint16(buf, uint16(v))
+		return
+	}
+	f.Order.PutUint32(buf, v)
+}
+
+func (f FrameFormat) uint(buf []byte, size int) uint32 {
+	if size == 2 {
+		return uint32(f.Order.Uint16(buf))
+	}
+	return f.Order.Uint32(buf)
+}
+
+// sum은 설정된 범위에 대해 체크섬을 계산합니다. frame은 헤더를 포함한 전체 프레임입니다.
+func (f FrameFormat) sum(frame []byte) uint32 {
+	header := f.HeaderSize()
+	if f.ChecksumScope == ChecksumScopeFrame {
+		data := append([]byte(nil), frame...)
+		offset := f.FieldOffset(FieldChecksum)
+		copy(data[offset:offset+4], make([]byte, 4))
+		return checksums[f.Checksum](data)
+	}
+	return checksums[f.Checksum](frame[header:])
+}
+
+// MaxPayload는 길이 필드로 나타낼 수 있는 최대 페이로드 길이를 반환합니다.
+func (f FrameFormat) MaxPayload() uint64 {
+	if f.LengthSize == 2 {
+		return math.MaxUint16
+	}
+	return math.MaxUint32
+}
+
+// Build는 페이로드 앞에 헤더를 붙인 프레임을 생성합니다.
+// 페이로드가 길이 필드로 나타낼 수 없을 만큼 길면 오류를 반환합니다.
+func (f FrameFormat) Build(payload []byte) ([]byte, error) {
+	if uint64(len(payload)) > f.MaxPayload() {
+		return nil, fmt.Errorf("페이로드가 길이 필드 범위를 넘습니다: %d바이트 (최대 %d바이트)", len(payload), f.MaxPayload())
+	}
+	header := f.HeaderSize()
+	buf := make([]byte, header+len(payload))
+	copy(buf[header:], payload)
+	for _, name := range f.Fields {
+		offset := f.FieldOffset(name)
+		switch name {
+		case FieldMagic:
+			copy(buf[offset:], f.Magic)
+		case FieldLength:
+			f.putUint(buf[offset:], f.LengthSize, uint32(len(payload)))
+		}
+	}
+	if offset := f.FieldOffset(FieldChecksum); offset >= 0 {
+		f.Order.PutUint32(buf[offset:], f.sum(buf))
+	}
+	return buf, nil
+}
+
+// Split은 bufio.SplitFunc 형식으로 스트림에서 헤더의 길이만큼 한 프레임을 잘라냅니다.
+// 프레임이 아직 다 도착하지 않았으면 더 읽도록 (0, nil, nil)을 반환합니다.
+func (f FrameFormat) Split(data []byte, atEOF bool) (int, []byte, error) {
+	header := f.HeaderSize()
+	if len(data) < header {
+		if atEOF && len(data) > 0 {
+			return 0, nil, fmt.Errorf("패킷 길이 부족")
+		}
+		return 0, nil, nil
+	}
+	if offset := f.FieldOffset(FieldMagic); offset >= 0 {
+		for i, b := range f.Magic {
+			if data[offset+i] != b {
+				return 0, nil, fmt.Errorf("magic 불일치")
+			}
+		}
+	}
+	offset := f.FieldOffset(FieldLength)
+	total := header + int(f.uint(data[offset:], f.LengthSize))
+	if len(data) < total {
+		if atEOF {
+			return 0, nil, fmt.Errorf("전체 패킷 미도착")
+		}
+		return 0, nil, nil
+	}
+	return total, data[:total], nil
+}
+
+// Unpack은 프레임의 매직, 길이, 체크섬을 검증하고 페이로드를 반환합니다.
+func (f FrameFormat) Unpack(buf []byte) ([]byte, error) {
+	_, frame, err := f.Split(buf, true)
+	if err != nil {
+		return nil, err
+	}
+	if frame == nil {
+		return nil, fmt.Errorf("패킷 길이 부족")
+	}
+
+	payload := frame[f.HeaderSize():]
+	if offset := f.FieldOffset(FieldChecksum); offset >= 0 {
+		expected := f.Order.Uint32(frame[offset:])
+		actual := f.sum(frame)
+		if actual != expected {
+			return nil, fmt.Errorf("CRC 불일치: 기대 %x, 실제 %x", expected, actual)
+		}
+	}
+	return payload, nil
+}
+
+// BreakChecksum은 체크섬 필드의 모든 비트를 뒤집어 검증에 실패하는 프레임 사본을 반환합니다.
+// 체크섬 필드가 없거나 프레임이 헤더보다 짧으면 false를 반환합니다.
+func (f FrameFormat) BreakChecksum(frame []byte) ([]byte, bool) {
+	offset := f.FieldOffset(FieldChecksum)
+	if offset < 0 || len(frame) < f.HeaderSize() {
+		return frame, false
+	}
+	out := append([]byte(nil), frame...)
+	f.Order.PutUint32(out[offset:], ^f.sum(out))
+	return out, true
+}
+
+// WithLength는 길이 필드를 length로 바꾸고 체크섬을 다시 계산한 프레임 사본을 반환합니다.
+// 길이 필드만 실제 페이로드 길이와 어긋난 프레임을 만들 때 사용합니다.
+// 프레임이 헤더보다 짧으면 false를 반환합니다.
+func (f FrameFormat) WithLength(frame []byte, length uint32) ([]byte, bool) {
+	offset := f.FieldOffset(FieldLength)
+	if offset < 0 || len(frame) < f.HeaderSize() {
+		return frame, false
+	}
+	out := append([]byte(nil), frame...)
+	f.putUint(out[offset:], f.LengthSize, length)
+	if offset := f.FieldOffset(FieldChecksum); offset >= 0 {
+		f.Order.PutUint32(out[offset:], f.sum(out))
+	}
+	return out, true
+}
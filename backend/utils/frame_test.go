@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultFrameFormatMatchesLegacyHeader(t *testing.T) {
+	frame := BuildPacket([]byte{1, 2, 3})
+	assert.Equal(t, MagicHeader, binary.LittleEndian.Uint32(frame[0:4]))
+	assert.Equal(t, uint32(3), binary.LittleEndian.Uint32(frame[4:8]))
+	assert.Equal(t, FastCRC32([]byte{1, 2, 3}), binary.LittleEndian.Uint32(frame[8:12]))
+
+	payload, err := UnpackPacket(frame)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{1, 2, 3}, payload)
+}
+
+func TestCustomFrameFormatRoundTrip(t *testing.T) {
+	format := FrameFormat{
+		Magic:         []byte{0xEB, 0x90},
+		Fields:        []string{FieldLength, FieldMagic, FieldChecksum},
+		Order:         binary.BigEndian,
+		LengthSize:    2,
+		Checksum:      ChecksumCRC32C,
+		ChecksumScope: ChecksumScopeFrame,
+	}
+	assert.NoError(t, format.Validate())
+	assert.Equal(t, 8, format.HeaderSize())
+
+	frame, err := format.Build([]byte("hello"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x00, 0x05, 0xEB, 0x90}, frame[:4])
+
+	payload, err := format.Unpack(frame)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", string(payload))
+
+	frame[2] = 0xEC
+	_, err = format.Unpack(frame)
+	assert.Error(t, err)
+
+	// 2바이트 길이 필드로 나타낼 수 없는 페이로드는 잘라내지 않고 거부
+	_, err = format.Build(make([]byte, 65536))
+	assert.ErrorContains(t, err, "길이 필드 범위")
+	frame, err = format.Build(make([]byte, 65535))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0xFF, 0xFF}, frame[:2])
+}
+
+func TestFrameFormatDetectsChecksumMismatch(t *testing.T) {
+	frame := BuildPacket([]byte{1, 2, 3})
+	frame[len(frame)-1] ^= 0xFF
+	_, err := UnpackPacket(frame)
+	assert.ErrorContains(t, err, "CRC 불일치")
+
+	broken, ok := DefaultFrameFormat.BreakChecksum(BuildPacket([]byte{1, 2, 3}))
+	assert.True(t, ok)
+	_, err = UnpackPacket(broken)
+	assert.ErrorContains(t, err, "CRC 불일치")
+	noChecksum := FrameFormat{Fields: []string{FieldLength}, Order: DefaultFrameFormat.Order, LengthSize: 2, Checksum: ChecksumNone}
+	frame, _ = noChecksum.Build([]byte{1})
+	_, ok = noChecksum.BreakChecksum(frame)
+	assert.False(t, ok)
+}
+
+func TestFrameFormatWithLength(t *testing.T) {
+	frame := BuildPacket([]byte{1, 2, 3})
+	longer, ok := DefaultFrameFormat.WithLength(frame, 4)
+	assert.True(t, ok)
+	assert.Len(t, longer, len(frame))
+	_, err := UnpackPacket(longer)
+	assert.ErrorContains(t, err, "전체 패킷 미도착")
+
+	assert.Equal(t, uint32(4), DefaultFrameFormat.Order.Uint32(longer[DefaultFrameFormat.FieldOffset(FieldLength):]))
+	assert.Equal(t, frame[DefaultFrameFormat.HeaderSize():], longer[DefaultFrameFormat.HeaderSize():])
+
+	_, ok = DefaultFrameFormat.WithLength([]byte{1}, 0)
+	assert.False(t, ok)
+}
+
+func TestFrameFormatValidate(t *testing.T) {
+	format := DefaultFrameFormat
+	format.Fields = []string{FieldMagic, FieldChecksum}
+	assert.Error(t, format.Validate())
+
+	format = DefaultFrameFormat
+	format.Checksum = "md5"
+	assert.Error(t, format.Validate())
+
+	format = DefaultFrameFormat
+	format.Checksum = ChecksumNone
+	format.Fields = []string{FieldMagic, FieldLength}
+	assert.NoError(t, format.Validate())
+}
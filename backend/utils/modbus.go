@@ -0,0 +1,236 @@
+package utils
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Modbus 함수 코드
+const (
+	ModbusReadCoils              byte = 0x01
+	ModbusReadDiscreteInputs     byte = 0x02
+	ModbusReadHoldingRegisters   byte = 0x03
+	ModbusReadInputRegisters     byte = 0x04
+	ModbusWriteSingleCoil        byte = 0x05
+	ModbusWriteSingleRegister    byte = 0x06
+	ModbusWriteMultipleCoils     byte = 0x0F
+	ModbusWriteMultipleRegisters byte = 0x10
+)
+
+// MBAPHeaderSize는 트랜잭션 ID(2) + 프로토콜 ID(2) + 길이(2) + 유닛 ID(1) 길이입니다.
+const MBAPHeaderSize = 7
+
+// MaxModbusPDUSize는 Modbus PDU(함수 코드 + 데이터)의 최대 길이입니다.
+const MaxModbusPDUSize = 253
+
+// modbusExceptions는 Modbus 예외 코드의 이름입니다.
+var modbusExceptions = map[byte]string{
+	0x01: "ILLEGAL FUNCTION",
+	0x02: "ILLEGAL DATA ADDRESS",
+	0x03: "ILLEGAL DATA VALUE",
+	0x04: "SERVER DEVICE FAILURE",
+	0x05: "ACKNOWLEDGE",
+	0x06: "SERVER DEVICE BUSY",
+	0x08: "MEMORY PARITY ERROR",
+	0x0A: "GATEWAY PATH UNAVAILABLE",
+	0x0B: "GATEWAY TARGET DEVICE FAILED TO RESPOND",
+}
+
+// ModbusResponse는 해석된 Modbus 응답입니다.
+// 코일/입력 읽기 결과는 Values에 0 또는 1로, 레지스터 읽기 결과는 레지스터 값으로 담깁니다.
+type ModbusResponse struct {
+	TransactionID uint16   `json:"transaction_id"`
+	UnitID        byte     `json:"unit_id"`
+	Function      byte     `json:"function"`
+	Exception     byte     `json:"exception,omitempty"`
+	ExceptionName string   `json:"exception_name,omitempty"`
+	Address       uint16   `json:"address,omitempty"`
+	Quantity      uint16   `json:"quantity,omitempty"`
+	Values        []uint16 `json:"values,omitempty"`
+}
+
+// BuildModbusPDU는 함수 코드와 파라미터로 PDU(함수 코드 + 데이터)를 생성합니다.
+// 단일 쓰기는 values[0]을, 다중 쓰기는 values 전체를 사용합니다.
+func BuildModbusPDU(function byte, address, quantity uint16, values []uint16) ([]byte, error) {
+	pdu := []byte{function, 0, 0}
+	binary.BigEndian.PutUint16(pdu[1:3], address)
+
+	switch function {
+	case ModbusReadCoils, ModbusReadDiscreteInputs:
+		if quantity < 1 || quantity > 2000 {
+			return nil, fmt.Errorf("코일 수량은 1-2000이어야 합니다: %d", quantity)
+		}
+		pdu = binary.BigEndian.AppendUint16(pdu, quantity)
+
+	case ModbusReadHoldingRegisters, ModbusReadInputRegisters:
+		if quantity < 1 || quantity > 125 {
+			return nil, fmt.Errorf("레지스터 수량은 1-125이어야 합니다: %d", quantity)
+		}
+		pdu = binary.BigEndian.AppendUint16(pdu, quantity)
+
+	case ModbusWriteSingleCoil:
+		if len(values) != 1 {
+			return nil, fmt.Errorf("단일 코일 쓰기에는 값이 하나 필요합니다")
+		}
+		var v uint16
+		if values[0] != 0 {
+			v = 0xFF00
+		}
+		pdu = binary.BigEndian.AppendUint16(pdu, v)
+
+	case ModbusWriteSingleRegister:
+		if len(values) != 1 {
+			return nil, fmt.Errorf("단일 레지스터 쓰기에는 값이 하나 필요합니다")
+		}
+		pdu = binary.BigEndian.AppendUint16(pdu, values[0])
+
+	case ModbusWriteMultipleCoils:
+		if len(values) < 1 || len(values) > 1968 {
+			return nil, fmt.Errorf("코일 쓰기 개수는 1-1968이어야 합니다: %d", len(values))
+		}
+		bits := make([]byte, (len(values)+7)/8)
+		for i, v := range values {
+			if v != 0 {
+				bits[i/8] |= 1 << (i % 8)
+			}
+		}
+		pdu = binary.BigEndian.AppendUint16(pdu, uint16(len(values)))
+		pdu = append(pdu, byte(len(bits)))
+		pdu = append(pdu, bits...)
+
+	case ModbusWriteMultipleRegisters:
+		if len(values) < 1 || len(values) > 123 {
+			return nil, fmt.Errorf("레지스터 쓰기 개수는 1-123이어야 합니다: %d", len(values))
+		}
+		pdu = binary.BigEndian.AppendUint16(pdu, uint16(len(values)))
+		pdu = append(pdu, byte(len(values)*2))
+		for _, v := range values {
+			pdu = binary.BigEndian.AppendUint16(pdu, v)
+		}
+
+	default:
+		return nil, fmt.Errorf("지원되지 않는 Modbus 함수 코드: 0x%02X", function)
+	}
+	return pdu, nil
+}
+
+// CheckModbusPDU는 PDU에 함수 코드가 있고 최대 길이를 넘지 않는지 검사합니다.
+func CheckModbusPDU(pdu []byte) error {
+	if len(pdu) == 0 {
+		return fmt.Errorf("Modbus PDU가 비어 있습니다")
+	}
+	if len(pdu) > MaxModbusPDUSize {
+		return fmt.Errorf("Modbus PDU는 %d바이트 이하여야 합니다: %d", MaxModbusPDUSize, len(pdu))
+	}
+	return nil
+}
+
+// BuildMBAP은 PDU 앞에 MBAP 헤더를 붙여 Modbus TCP ADU를 생성합니다.
+func BuildMBAP(transactionID uint16, unitID byte, pdu []byte) []byte {
+	buf := make([]byte, MBAPHeaderSize+len(pdu))
+	binary.BigEndian.PutUint16(buf[0:2], transactionID)
+	binary.BigEndian.PutUint16(buf[2:4], 0)
+	binary.BigEndian.PutUint16(buf[4:6], uint16(len(pdu)+1))
+	buf[6] = unitID
+	copy(buf[MBAPHeaderSize:], pdu)
+	return buf
+}
+
+// SplitMBAP은 bufio.SplitFunc 형식으로 MBAP 길이 필드를 기준으로 ADU 하나를 잘라냅니다.
+func SplitMBAP(data []byte, atEOF bool) (int, []byte, error) {
+	if len(data) < MBAPHeaderSize {
+		if atEOF && len(data) > 0 {
+			return 0, nil, fmt.Errorf("MBAP 헤더 길이 부족")
+		}
+		return 0, nil, nil
+	}
+	if binary.BigEndian.Uint16(data[2:4]) != 0 {
+		return 0, nil, fmt.Errorf("Modbus 프로토콜 ID 불일치")
+	}
+	length := int(binary.BigEndian.Uint16(data[4:6]))
+	if length < 2 {
+		return 0, nil, fmt.Errorf("MBAP 길이 필드 오류: %d", length)
+	}
+	total := 6 + length
+	if len(data) < total {
+		if atEOF {
+			return 0, nil, fmt.Errorf("전체 패킷 미도착")
+		}
+		return 0, nil, nil
+	}
+	return total, data[:total], nil
+}
+
+// ParseModbusResponse는 ADU를 해석합니다. quantity는 요청한 코일/입력 수로,
+// 바이트 단위로 채워진 비트 응답에서 남는 비트를 잘라내는 데 사용합니다.
+func ParseModbusResponse(adu []byte, quantity uint16) (ModbusResponse, error) {
+	var resp ModbusResponse
+	if len(adu) < MBAPHeaderSize+1 {
+		return resp, fmt.Errorf("Modbus 응답 길이 부족")
+	}
+	resp.TransactionID = binary.BigEndian.Uint16(adu[0:2])
+	resp.UnitID = adu[6]
+	resp.Function = adu[7]
+	data := adu[MBAPHeaderSize+1:]
+
+	if resp.Function&0x80 != 0 {
+		if len(data) < 1 {
+			return resp, fmt.Errorf("Modbus 예외 코드 누락")
+		}
+		resp.Function &^= 0x80
+		resp.Exception = data[0]
+		resp.ExceptionName = modbusExceptions[data[0]]
+		if resp.ExceptionName == "" {
+			resp.ExceptionName = "UNKNOWN EXCEPTION"
+		}
+		return resp, nil
+	}
+
+	switch resp.Function {
+	case ModbusReadCoils, ModbusReadDiscreteInputs:
+		if len(data) < 1 || len(data) < 1+int(data[0]) {
+			return resp, fmt.Errorf("Modbus 비트 응답 길이 오류")
+		}
+		bits := data[1 : 1+int(data[0])]
+		count := int(quantity)
+		if count == 0 || count > len(bits)*8 {
+			count = len(bits) * 8
+		}
+		resp.Values = make([]uint16, count)
+		for i := 0; i < count; i++ {
+			resp.Values[i] = uint16(bits[i/8]>>(i%8)) & 1
+		}
+
+	case ModbusReadHoldingRegisters, ModbusReadInputRegisters:
+		if len(data) < 1 || len(data) < 1+int(data[0]) || data[0]%2 != 0 {
+			return resp, fmt.Errorf("Modbus 레지스터 응답 길이 오류")
+		}
+		regs := data[1 : 1+int(data[0])]
+		resp.Values = make([]uint16, len(regs)/2)
+		for i := range resp.Values {
+			resp.Values[i] = binary.BigEndian.Uint16(regs[i*2:])
+		}
+
+	case ModbusWriteSingleCoil, ModbusWriteSingleRegister:
+		if len(data) < 4 {
+			return resp, fmt.Errorf("Modbus 쓰기 응답 길이 오류")
+		}
+		resp.Address = binary.BigEndian.Uint16(data[0:2])
+		value := binary.BigEndian.Uint16(data[2:4])
+		if resp.Function == ModbusWriteSingleCoil && value == 0xFF00 {
+			value = 1
+		}
+		resp.Values = []uint16{value}
+
+	case ModbusWriteMultipleCoils, ModbusWriteMultipleRegisters:
+		if len(data) < 4 {
+			return resp, fmt.Errorf("Modbus 쓰기 응답 길이 오류")
+		}
+		resp.Address = binary.BigEndian.Uint16(data[0:2])
+		resp.Quantity = binary.BigEndian.Uint16(data[2:4])
+
+	default:
+		return resp, fmt.Errorf("지원되지 않는 Modbus 함수 코드: 0x%02X", resp.Function)
+	}
+	return resp, nil
+}
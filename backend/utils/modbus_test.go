@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildModbusReadHoldingRegisters(t *testing.T) {
+	pdu, err := BuildModbusPDU(ModbusReadHoldingRegisters, 0x006B, 3, nil)
+	assert.NoError(t, err)
+	adu := BuildMBAP(1, 0x11, pdu)
+	assert.Equal(t, []byte{0x00, 0x01, 0x00, 0x00, 0x00, 0x06, 0x11, 0x03, 0x00, 0x6B, 0x00, 0x03}, adu)
+}
+
+func TestBuildModbusWriteMultipleCoils(t *testing.T) {
+	pdu, err := BuildModbusPDU(ModbusWriteMultipleCoils, 0x0013, 0, []uint16{1, 0, 1, 1, 0, 0, 1, 1, 1, 0})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x0F, 0x00, 0x13, 0x00, 0x0A, 0x02, 0xCD, 0x01}, pdu)
+}
+
+func TestBuildModbusPDUValidation(t *testing.T) {
+	_, err := BuildModbusPDU(ModbusReadHoldingRegisters, 0, 126, nil)
+	assert.Error(t, err)
+	_, err = BuildModbusPDU(ModbusWriteSingleRegister, 0, 0, nil)
+	assert.Error(t, err)
+	_, err = BuildModbusPDU(0x2B, 0, 1, nil)
+	assert.Error(t, err)
+}
+
+func TestParseModbusResponse(t *testing.T) {
+	regs := []byte{0x00, 0x07, 0x00, 0x00, 0x00, 0x09, 0x01, 0x03, 0x06, 0x02, 0x2B, 0x00, 0x00, 0x00, 0x64}
+	resp, err := ParseModbusResponse(regs, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(7), resp.TransactionID)
+	assert.Equal(t, []uint16{555, 0, 100}, resp.Values)
+
+	coils := BuildMBAP(2, 1, []byte{0x01, 0x01, 0x05})
+	resp, err = ParseModbusResponse(coils, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint16{1, 0, 1}, resp.Values)
+
+	exception := BuildMBAP(3, 1, []byte{0x83, 0x02})
+	resp, err = ParseModbusResponse(exception, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, ModbusReadHoldingRegisters, resp.Function)
+	assert.Equal(t, byte(0x02), resp.Exception)
+	assert.Equal(t, "ILLEGAL DATA ADDRESS", resp.ExceptionName)
+}
+
+func TestSplitMBAP(t *testing.T) {
+	adu := BuildMBAP(1, 1, []byte{0x06, 0x00, 0x01, 0x00, 0x03})
+	stream := append(append([]byte{}, adu...), adu[:3]...)
+
+	advance, frame, err := SplitMBAP(stream, false)
+	assert.NoError(t, err)
+	assert.Equal(t, len(adu), advance)
+	assert.Equal(t, adu, frame)
+
+	advance, frame, err = SplitMBAP(stream[advance:], false)
+	assert.NoError(t, err)
+	assert.Zero(t, advance)
+	assert.Nil(t, frame)
+}
@@ -1,9 +1,9 @@
 package utils
 
 import (
-	"encoding/binary"
 	"fmt"
 	"net"
+	"net/netip"
 	"strconv"
 	"strings"
 )
@@ -32,67 +32,93 @@ func GetLocalIP() (string, error) {
 	return "", fmt.Errorf("로컬 IP 찾기 실패")
 }
 
-func CompareIP(ip1, ip2 string) int {
-	parts1 := strings.Split(ip1, ".")
-	parts2 := strings.Split(ip2, ".")
-	for i := 0; i < 4; i++ {
-		if parts1[i] > parts2[i] {
-			return 1
-		} else if parts1[i] < parts2[i] {
-			return -1
-		}
+// CompareIP는 "host:port" 형식의 두 주소를 비교하여 -1, 0, 1을 반환합니다.
+// IPv4와 IPv6 주소를 모두 지원하며 IPv4 주소가 IPv6 주소보다 앞에 정렬됩니다.
+// IP가 아닌 호스트 이름은 IP 주소 뒤에 문자열 순서로 정렬됩니다.
+func CompareIP(addr1, addr2 string) int {
+	host1, port1 := splitAddr(addr1)
+	host2, port2 := splitAddr(addr2)
+
+	ip1, err1 := netip.ParseAddr(host1)
+	ip2, err2 := netip.ParseAddr(host2)
+	var c int
+	switch {
+	case err1 == nil && err2 == nil:
+		c = ip1.Unmap().Compare(ip2.Unmap())
+	case err1 == nil:
+		c = -1
+	case err2 == nil:
+		c = 1
+	default:
+		c = strings.Compare(host1, host2)
+	}
+	if c != 0 {
+		return c
 	}
-	port1, _ := strconv.Atoi(strings.Split(ip1, ":")[1])
-	port2, _ := strconv.Atoi(strings.Split(ip2, ":")[1])
 	return comparePort(port1, port2)
 }
 
+// splitAddr는 주소를 호스트와 포트로 분리합니다. 포트가 없으면 0을 반환합니다.
+func splitAddr(addr string) (string, int) {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return strings.Trim(addr, "[]"), 0
+	}
+	port, _ := strconv.Atoi(portStr)
+	return host, port
+}
+
 func comparePort(port1, port2 int) int {
 	if port1 > port2 {
 		return 1
+	} else if port1 < port2 {
+		return -1
 	}
-	return -1
+	return 0
 }
 
+// GenerateAddr는 호스트와 포트로 접속 주소를 생성합니다. IPv6 주소는 대괄호로 감쌉니다.
 func GenerateAddr(host string, port int) string {
-	return host + ":" + strconv.Itoa(port)
-}
-
-func BuildPacket(payload []byte) []byte {
-	length := uint32(len(payload))
-	crc := FastCRC32(payload)
-	buf := make([]byte, HeaderSize+len(payload))
-	binary.LittleEndian.PutUint32(buf[0:4], MagicHeader)
-	binary.LittleEndian.PutUint32(buf[4:8], length)
-	binary.LittleEndian.PutUint32(buf[8:12], crc)
-	copy(buf[12:], payload)
-	return buf
+	return net.JoinHostPort(host, strconv.Itoa(port))
 }
 
-// UnpackPacket for Tests
-func UnpackPacket(buf []byte) ([]byte, error) {
-	if len(buf) < HeaderSize {
-		return nil, fmt.Errorf("패킷 길이 부족")
+// IsValidHost는 host가 IP 주소(IPv4/IPv6) 또는 RFC 1123 호스트 이름인지 확인합니다.
+func IsValidHost(host string) bool {
+	if net.ParseIP(host) != nil {
+		return true
 	}
-
-	// 1. magic 확인
-	if binary.LittleEndian.Uint32(buf[0:4]) != MagicHeader {
-		return nil, fmt.Errorf("magic 불일치")
+	if len(host) == 0 || len(host) > 253 {
+		return false
 	}
-
-	// 2. 길이 읽기
-	length := binary.LittleEndian.Uint32(buf[4:8])
-	if len(buf) < int(HeaderSize+length) {
-		return nil, fmt.Errorf("전체 패킷 미도착")
+	for _, label := range strings.Split(strings.TrimSuffix(host, "."), ".") {
+		if len(label) == 0 || len(label) > 63 {
+			return false
+		}
+		if label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-') {
+				return false
+			}
+		}
 	}
+	return true
+}
 
-	// 3. CRC 확인
-	expectedCRC := binary.LittleEndian.Uint32(buf[8:12])
-	payload := buf[12 : 12+length]
-	actualCRC := FastCRC32(payload)
-	if actualCRC != expectedCRC {
-		return nil, fmt.Errorf("CRC 불일치: 기대 %x, 실제 %x", expectedCRC, actualCRC)
-	}
+// BuildPacket은 기본 프레임 형식으로 페이로드를 감쌉니다.
+// 기본 형식의 길이 필드는 4바이트이므로 4GiB 미만의 페이로드만 지원합니다.
+func BuildPacket(payload []byte) []byte {
+	frame, _ := DefaultFrameFormat.Build(payload)
+	return frame
+}
 
-	return payload, nil
+// SplitPacket은 기본 프레임 형식으로 스트림에서 한 프레임을 잘라냅니다.
+func SplitPacket(data []byte, atEOF bool) (int, []byte, error) {
+	return DefaultFrameFormat.Split(data, atEOF)
+}
+
+// UnpackPacket은 기본 프레임 형식의 패킷을 검증하고 페이로드를 반환합니다.
+func UnpackPacket(buf []byte) ([]byte, error) {
+	return DefaultFrameFormat.Unpack(buf)
 }
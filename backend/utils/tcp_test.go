@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareIP(t *testing.T) {
+	assert.Equal(t, -1, CompareIP("10.0.0.2:80", "10.0.0.10:80"))
+	assert.Equal(t, 1, CompareIP("10.0.0.1:81", "10.0.0.1:80"))
+	assert.Equal(t, 0, CompareIP("10.0.0.1:80", "10.0.0.1:80"))
+	assert.Equal(t, -1, CompareIP("[::1]:80", "[::2]:80"))
+	assert.Equal(t, -1, CompareIP("192.168.0.1:80", "[::1]:80"))
+	assert.Equal(t, 0, CompareIP("[::ffff:10.0.0.1]:80", "10.0.0.1:80"))
+	assert.Equal(t, -1, CompareIP("10.0.0.1:80", "edge-01.lab:80"))
+	assert.Equal(t, 1, CompareIP("edge-02.lab", "edge-01.lab"))
+
+	// 잘못된 입력에도 패닉이 발생하지 않음
+	assert.NotPanics(t, func() { CompareIP("", "1.2") })
+}
+
+func TestGenerateAddr(t *testing.T) {
+	assert.Equal(t, "127.0.0.1:80", GenerateAddr("127.0.0.1", 80))
+	assert.Equal(t, "[::1]:80", GenerateAddr("::1", 80))
+	assert.Equal(t, "edge-01.lab:80", GenerateAddr("edge-01.lab", 80))
+}
+
+func TestIsValidHost(t *testing.T) {
+	for _, host := range []string{"127.0.0.1", "::1", "localhost", "edge-01.lab", "edge-01.lab."} {
+		assert.True(t, IsValidHost(host), host)
+	}
+	for _, host := range []string{"", "bad host", "-edge.lab", "edge..lab", "[::1]", "edge_01.lab"} {
+		assert.False(t, IsValidHost(host), host)
+	}
+}